@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type CycleStatus struct {
+	Trigger    string     `json:"trigger" yaml:"trigger"`
+	StartedAt  time.Time  `json:"started_at" yaml:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" yaml:"finished_at,omitempty"`
+	Total      int        `json:"total" yaml:"total"`
+	Succeeded  int        `json:"succeeded" yaml:"succeeded"`
+	Failed     int        `json:"failed" yaml:"failed"`
+}
+
+// UpdaterStatus — состояние фонового обновления на реплике, принявшей запрос
+type UpdaterStatus struct {
+	Active       bool         `json:"active" yaml:"active"`
+	Interval     string       `json:"interval" yaml:"interval"`
+	Paused       bool         `json:"paused" yaml:"paused"`
+	NextRun      *time.Time   `json:"next_run,omitempty" yaml:"next_run,omitempty"`
+	CurrentCycle *CycleStatus `json:"current_cycle,omitempty" yaml:"current_cycle,omitempty"`
+	LastCycle    *CycleStatus `json:"last_cycle,omitempty" yaml:"last_cycle,omitempty"`
+}
+
+// UpdaterStatus возвращает состояние фонового обновления (служебный ключ)
+func (c *Client) UpdaterStatus(ctx context.Context) (*UpdaterStatus, error) {
+	var res UpdaterStatus
+	if err := c.do(ctx, http.MethodGet, "/api/admin/updater", nil, nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// RefreshAll запрашивает внеочередной проход по всем арендаторам и не ждет
+// его окончания (служебный ключ). В режиме очереди все задания назначаются
+// на сейчас; на паузе они ждут ее снятия
+func (c *Client) RefreshAll(ctx context.Context) (*UpdaterStatus, error) {
+	var res UpdaterStatus
+	if err := c.do(ctx, http.MethodPost, "/api/admin/updater/refresh", nil, nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
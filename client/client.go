@@ -0,0 +1,228 @@
+// Пакет client — Go-клиент REST API сервиса. На нем построен dnsctl,
+// его же можно использовать из других программ:
+//
+//	c, err := client.New("http://localhost:8080", client.WithAPIKey(key))
+//	domain, err := c.AddDomain(ctx, "github.com")
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	APIKeyHeader    = "X-API-Key"
+	RequestIDHeader = "X-Request-ID"
+
+	DefaultTimeout = 30 * time.Second
+)
+
+// Client выполняет запросы к API от имени одного ключа или JWT
+type Client struct {
+	baseURL   *url.URL
+	http      *http.Client
+	apiKey    string
+	token     string
+	userAgent string
+}
+
+type Option func(*Client)
+
+// WithAPIKey передает ключ в заголовке X-API-Key
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithBearerToken передает JWT в заголовке Authorization
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithHTTPClient заменяет HTTP-клиент, например для своих таймаутов или TLS.
+// Поток событий долгий, поэтому у клиента для Events не должно быть Timeout
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.http = hc
+	}
+}
+
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// New создает клиент для сервиса по адресу baseURL, например http://localhost:8080
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme and host are required", baseURL)
+	}
+
+	c := &Client{baseURL: u, http: &http.Client{}, userAgent: "dns-resolver-client"}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Error — ошибка API в формате problem+json
+type Error struct {
+	Status    int    `json:"status"`
+	Title     string `json:"title"`
+	Detail    string `json:"detail,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+func (e *Error) Error() string {
+	msg := e.Detail
+	if msg == "" {
+		msg = e.Title
+	}
+	if e.Code != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, e.Code)
+	}
+	if e.RequestID != "" {
+		msg += ", request id " + e.RequestID
+	}
+	return fmt.Sprintf("api: %d %s", e.Status, msg)
+}
+
+// IsNotFound сообщает, что API ответил 404
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound
+}
+
+// ListOptions — параметры постраничной выдачи. Нулевые значения — по умолчанию сервера
+type ListOptions struct {
+	Limit int
+	// Sort — поле сортировки, с "-" для обратного порядка
+	Sort   string
+	Cursor string
+}
+
+func (o ListOptions) values() url.Values {
+	q := url.Values{}
+	if o.Limit > 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Sort != "" {
+		q.Set("sort", o.Sort)
+	}
+	if o.Cursor != "" {
+		q.Set("cursor", o.Cursor)
+	}
+	return q
+}
+
+type pagination struct {
+	Limit      int    `json:"limit"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor"`
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Request, error) {
+	u := *c.baseURL
+	// Сегменты path уже экранированы (url.PathEscape), поэтому задаем и RawPath
+	u.RawPath = u.EscapedPath() + path
+	var err error
+	if u.Path, err = url.PathUnescape(u.RawPath); err != nil {
+		return nil, err
+	}
+	u.RawQuery = query.Encode()
+
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), r)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set(APIKeyHeader, c.apiKey)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	return req, nil
+}
+
+// send выполняет запрос и возвращает ответ с успешным статусом;
+// остальные статусы превращаются в *Error
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	return nil, decodeError(resp)
+}
+
+func decodeError(resp *http.Response) error {
+	apiErr := &Error{}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err := json.Unmarshal(data, apiErr); err != nil {
+		// Ответ не от сервиса, например от прокси
+		apiErr.Detail = strings.TrimSpace(string(data))
+	}
+	apiErr.Status = resp.StatusCode
+	if apiErr.Title == "" {
+		apiErr.Title = http.StatusText(resp.StatusCode)
+	}
+	if apiErr.RequestID == "" {
+		apiErr.RequestID = resp.Header.Get(RequestIDHeader)
+	}
+	return apiErr
+}
+
+// do выполняет запрос и декодирует JSON-ответ в out, если он не nil
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	req, err := c.newRequest(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
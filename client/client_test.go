@@ -0,0 +1,253 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKey = "test-key"
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeProblem(w http.ResponseWriter, status int, code, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set(RequestIDHeader, "req-1")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"type":       "urn:dns-resolver:problem:" + code,
+		"title":      http.StatusText(status),
+		"status":     status,
+		"detail":     detail,
+		"code":       code,
+		"request_id": "req-1",
+	})
+}
+
+// newTestServer отвечает так же, как сервис, на запросы с ключом testKey
+func newTestServer(t *testing.T) *Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v2/domains", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			FQDN string `json:"fqdn"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		if req.FQDN == "nonexistent.invalid" {
+			writeProblem(w, http.StatusNotFound, "dns.nxdomain", "domain does not exist")
+			return
+		}
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"data": map[string]interface{}{
+			"fqdn": req.FQDN, "fqdn_unicode": req.FQDN,
+			"records": []map[string]string{{"ip": "140.82.121.4", "family": "ipv4"}},
+		}})
+	})
+	mux.HandleFunc("GET /api/v2/domains", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2", r.URL.Query().Get("limit"))
+		assert.Equal(t, "-name", r.URL.Query().Get("sort"))
+		page := map[string]interface{}{
+			"data":       []map[string]string{{"fqdn": "xn--e1afmkfd.xn--p1ai", "fqdn_unicode": "пример.рф"}, {"fqdn": "github.com", "fqdn_unicode": "github.com"}},
+			"pagination": map[string]interface{}{"limit": 2, "total": 3, "next_cursor": "c1"},
+		}
+		if r.URL.Query().Get("cursor") == "c1" {
+			page = map[string]interface{}{
+				"data":       []map[string]string{{"fqdn": "example.com", "fqdn_unicode": "example.com"}},
+				"pagination": map[string]interface{}{"limit": 2, "total": 3},
+			}
+		}
+		writeJSON(w, http.StatusOK, page)
+	})
+	mux.HandleFunc("DELETE /api/v2/domains/{fqdn}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("fqdn") != "github.com" {
+			writeProblem(w, http.StatusNotFound, "resource.not_found", "fqdn not found")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /api/v2/domains/{fqdn}/records", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"data":       []map[string]string{{"ip": "2001:db8::1", "family": "ipv6"}},
+			"pagination": map[string]interface{}{"limit": 100, "total": 1},
+		})
+	})
+	mux.HandleFunc("GET /api/v2/addresses/{ip}/domains", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2001:db8::1", r.PathValue("ip"))
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"data":       []map[string]string{{"fqdn": "github.com", "fqdn_unicode": "github.com"}},
+			"pagination": map[string]interface{}{"limit": 100, "total": 1},
+		})
+	})
+	mux.HandleFunc("POST /api/fqdns/{fqdn}/refresh", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"fqdn": r.PathValue("fqdn"), "fqdn_unicode": r.PathValue("fqdn"), "ips": []string{"140.82.121.4", "2001:db8::1"},
+		})
+	})
+	mux.HandleFunc("POST /api/admin/updater/refresh", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusAccepted, map[string]interface{}{
+			"active": true, "interval": "5m0s", "interval_seconds": 300, "paused": false,
+		})
+	})
+	mux.HandleFunc("GET /api/export/firewall/{format}", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		fmt.Fprintf(w, "%s fqdn=%s group=%s family=%s name=%s\n",
+			r.PathValue("format"), q.Get("fqdn"), q.Get("group"), q.Get("family"), q.Get("name"))
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(APIKeyHeader) != testKey {
+			writeProblem(w, http.StatusUnauthorized, "auth.unauthenticated", "missing credentials")
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL+"/", WithAPIKey(testKey))
+	require.NoError(t, err)
+	return c
+}
+
+func TestClient(t *testing.T) {
+	c := newTestServer(t)
+	ctx := context.Background()
+
+	t.Run("Add domain", func(t *testing.T) {
+		domain, err := c.AddDomain(ctx, "github.com")
+		require.NoError(t, err)
+		assert.Equal(t, &Domain{FQDN: "github.com", FQDNUnicode: "github.com", Records: []Record{{IP: "140.82.121.4", Family: "ipv4"}}}, domain)
+	})
+
+	t.Run("Problem is returned as Error", func(t *testing.T) {
+		_, err := c.AddDomain(ctx, "nonexistent.invalid")
+		var apiErr *Error
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusNotFound, apiErr.Status)
+		assert.Equal(t, "dns.nxdomain", apiErr.Code)
+		assert.Equal(t, "req-1", apiErr.RequestID)
+		assert.True(t, IsNotFound(err))
+		assert.EqualError(t, err, "api: 404 domain does not exist (dns.nxdomain), request id req-1")
+	})
+
+	t.Run("List domains page by page", func(t *testing.T) {
+		page, err := c.ListDomains(ctx, ListOptions{Limit: 2, Sort: "-name"})
+		require.NoError(t, err)
+		assert.Len(t, page.Domains, 2)
+		assert.Equal(t, "пример.рф", page.Domains[0].FQDNUnicode)
+		assert.Equal(t, int64(3), page.Total)
+		assert.Equal(t, "c1", page.NextCursor)
+
+		page, err = c.ListDomains(ctx, ListOptions{Limit: 2, Sort: "-name", Cursor: page.NextCursor})
+		require.NoError(t, err)
+		assert.Equal(t, []Domain{{FQDN: "example.com", FQDNUnicode: "example.com"}}, page.Domains)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("Remove domain", func(t *testing.T) {
+		require.NoError(t, c.RemoveDomain(ctx, "github.com"))
+		assert.True(t, IsNotFound(c.RemoveDomain(ctx, "gitlab.com")))
+	})
+
+	t.Run("Lookup IPs and FQDNs", func(t *testing.T) {
+		ips, err := c.LookupIPs(ctx, "github.com", ListOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []Record{{IP: "2001:db8::1", Family: "ipv6"}}, ips.Records)
+
+		fqdns, err := c.LookupFQDNs(ctx, "2001:db8::1", ListOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "github.com", fqdns.Domains[0].FQDN)
+	})
+
+	t.Run("Refresh", func(t *testing.T) {
+		domain, err := c.RefreshDomain(ctx, "github.com")
+		require.NoError(t, err)
+		assert.Equal(t, []Record{{IP: "140.82.121.4", Family: "ipv4"}, {IP: "2001:db8::1", Family: "ipv6"}}, domain.Records)
+
+		status, err := c.RefreshAll(ctx)
+		require.NoError(t, err)
+		assert.True(t, status.Active)
+		assert.Equal(t, "5m0s", status.Interval)
+	})
+
+	t.Run("Export firewall", func(t *testing.T) {
+		body, err := c.ExportFirewall(ctx, "ipset", FirewallOptions{FQDNs: []string{"github.com", "api.github.com"}, Family: "ipv4"})
+		require.NoError(t, err)
+		assert.Equal(t, "ipset fqdn=github.com,api.github.com group= family=ipv4 name=\n", string(body))
+	})
+
+	t.Run("Missing credentials", func(t *testing.T) {
+		anon, err := New(c.baseURL.String())
+		require.NoError(t, err)
+		_, err = anon.ListDomains(ctx, ListOptions{})
+		var apiErr *Error
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusUnauthorized, apiErr.Status)
+		assert.Equal(t, "auth.unauthenticated", apiErr.Code)
+	})
+}
+
+func TestNew(t *testing.T) {
+	for _, raw := range []string{"localhost:8080", "ftp://localhost", "http://", "://bad"} {
+		_, err := New(raw)
+		assert.Error(t, err, raw)
+	}
+
+	c, err := New("https://example.com/dns/")
+	require.NoError(t, err)
+	req, err := c.newRequest(context.Background(), http.MethodGet, "/api/v2/addresses/"+"2001:db8::1"+"/domains", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/dns/api/v2/addresses/2001:db8::1/domains", req.URL.String())
+}
+
+func TestEvents(t *testing.T) {
+	var lastEventID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/events", r.URL.Path)
+		assert.Equal(t, "*.github.com", r.URL.Query().Get("fqdn"))
+		assert.Equal(t, "ip_added,ip_removed", r.URL.Query().Get("type"))
+		lastEventID = r.Header.Get("Last-Event-ID")
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": ping\n\n")
+		fmt.Fprint(w, "id: 7\nevent: ip_added\ndata: {\"id\":7,\"type\":\"ip_added\",\"fqdn\":\"api.github.com\",\"ip\":\"140.82.121.6\",\"occurred_at\":\"2025-01-01T00:00:00Z\"}\n\n")
+		fmt.Fprint(w, "id: 8\nevent: ip_removed\ndata: {\"id\":8,\"type\":\"ip_removed\",\"fqdn\":\"api.github.com\",\"ip\":\"140.82.121.5\",\"occurred_at\":\"2025-01-01T00:00:01Z\"}\n\n")
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL)
+	require.NoError(t, err)
+	opts := EventOptions{FQDNs: []string{"*.github.com"}, Types: []string{"ip_added", "ip_removed"}, LastEventID: "6"}
+
+	var events []Event
+	err = c.Events(context.Background(), opts, func(ev Event) error {
+		events = append(events, ev)
+		return nil
+	})
+	require.NoError(t, err, "stream closed by server")
+	assert.Equal(t, "6", lastEventID)
+	require.Len(t, events, 2)
+	assert.Equal(t, uint(7), events[0].ID)
+	assert.Equal(t, "ip_added", events[0].Type)
+	assert.Equal(t, "140.82.121.5", events[1].IP)
+
+	t.Run("Callback error stops the stream", func(t *testing.T) {
+		stop := errors.New("stop")
+		calls := 0
+		err := c.Events(context.Background(), opts, func(ev Event) error {
+			calls++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+}
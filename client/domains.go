@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/netip"
+	"net/url"
+)
+
+type Domain struct {
+	FQDN        string   `json:"fqdn" yaml:"fqdn"`
+	FQDNUnicode string   `json:"fqdn_unicode" yaml:"fqdn_unicode"`
+	Records     []Record `json:"records,omitempty" yaml:"records,omitempty"`
+}
+
+type Record struct {
+	IP string `json:"ip" yaml:"ip"`
+	// Family — ipv4 или ipv6
+	Family string `json:"family" yaml:"family"`
+}
+
+// DomainPage — страница доменов; NextCursor пуст на последней странице
+type DomainPage struct {
+	Domains    []Domain `json:"domains" yaml:"domains"`
+	Total      int64    `json:"total" yaml:"total"`
+	NextCursor string   `json:"next_cursor,omitempty" yaml:"next_cursor,omitempty"`
+}
+
+type RecordPage struct {
+	FQDN       string   `json:"fqdn" yaml:"fqdn"`
+	Records    []Record `json:"records" yaml:"records"`
+	Total      int64    `json:"total" yaml:"total"`
+	NextCursor string   `json:"next_cursor,omitempty" yaml:"next_cursor,omitempty"`
+}
+
+type domainList struct {
+	Data       []Domain   `json:"data"`
+	Pagination pagination `json:"pagination"`
+}
+
+func (l domainList) page() *DomainPage {
+	return &DomainPage{Domains: l.Data, Total: l.Pagination.Total, NextCursor: l.Pagination.NextCursor}
+}
+
+// ListDomains возвращает страницу отслеживаемых доменов
+func (c *Client) ListDomains(ctx context.Context, opts ListOptions) (*DomainPage, error) {
+	var res domainList
+	if err := c.do(ctx, http.MethodGet, "/api/v2/domains", opts.values(), nil, &res); err != nil {
+		return nil, err
+	}
+	return res.page(), nil
+}
+
+// AddDomain резолвит имя и начинает его отслеживать
+func (c *Client) AddDomain(ctx context.Context, fqdn string) (*Domain, error) {
+	var res struct {
+		Data Domain `json:"data"`
+	}
+	body := map[string]string{"fqdn": fqdn}
+	if err := c.do(ctx, http.MethodPost, "/api/v2/domains", nil, body, &res); err != nil {
+		return nil, err
+	}
+	return &res.Data, nil
+}
+
+// GetDomain возвращает домен с записями; для неотслеживаемого имени — 404
+func (c *Client) GetDomain(ctx context.Context, fqdn string) (*Domain, error) {
+	var res struct {
+		Data Domain `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v2/domains/"+url.PathEscape(fqdn), nil, nil, &res); err != nil {
+		return nil, err
+	}
+	return &res.Data, nil
+}
+
+// RemoveDomain прекращает отслеживание и удаляет записи домена
+func (c *Client) RemoveDomain(ctx context.Context, fqdn string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v2/domains/"+url.PathEscape(fqdn), nil, nil, nil)
+}
+
+// LookupIPs возвращает страницу текущих адресов домена
+func (c *Client) LookupIPs(ctx context.Context, fqdn string, opts ListOptions) (*RecordPage, error) {
+	var res struct {
+		Data       []Record   `json:"data"`
+		Pagination pagination `json:"pagination"`
+	}
+	path := "/api/v2/domains/" + url.PathEscape(fqdn) + "/records"
+	if err := c.do(ctx, http.MethodGet, path, opts.values(), nil, &res); err != nil {
+		return nil, err
+	}
+	return &RecordPage{FQDN: fqdn, Records: res.Data, Total: res.Pagination.Total, NextCursor: res.Pagination.NextCursor}, nil
+}
+
+// LookupFQDNs — обратный поиск: домены, которые сейчас резолвятся в ip
+func (c *Client) LookupFQDNs(ctx context.Context, ip string, opts ListOptions) (*DomainPage, error) {
+	var res domainList
+	path := "/api/v2/addresses/" + url.PathEscape(ip) + "/domains"
+	if err := c.do(ctx, http.MethodGet, path, opts.values(), nil, &res); err != nil {
+		return nil, err
+	}
+	return res.page(), nil
+}
+
+// RefreshDomain немедленно перепроверяет отслеживаемый домен (роль admin)
+func (c *Client) RefreshDomain(ctx context.Context, fqdn string) (*Domain, error) {
+	var res struct {
+		FQDN        string   `json:"fqdn"`
+		FQDNUnicode string   `json:"fqdn_unicode"`
+		IPs         []string `json:"ips"`
+	}
+	path := "/api/fqdns/" + url.PathEscape(fqdn) + "/refresh"
+	if err := c.do(ctx, http.MethodPost, path, nil, nil, &res); err != nil {
+		return nil, err
+	}
+	return &Domain{FQDN: res.FQDN, FQDNUnicode: res.FQDNUnicode, Records: newRecords(res.IPs)}, nil
+}
+
+// newRecords приводит список адресов v1 к записям v2
+func newRecords(ips []string) []Record {
+	res := make([]Record, len(ips))
+	for i, ip := range ips {
+		res[i] = Record{IP: ip, Family: "ipv4"}
+		if addr, err := netip.ParseAddr(ip); err == nil && addr.Is6() {
+			res[i].Family = "ipv6"
+		}
+	}
+	return res
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type Event struct {
+	ID         uint      `json:"id" yaml:"id"`
+	Type       string    `json:"type" yaml:"type"`
+	FQDN       string    `json:"fqdn" yaml:"fqdn"`
+	IP         string    `json:"ip,omitempty" yaml:"ip,omitempty"`
+	Error      string    `json:"error,omitempty" yaml:"error,omitempty"`
+	OccurredAt time.Time `json:"occurred_at" yaml:"occurred_at"`
+}
+
+type EventOptions struct {
+	// FQDNs — glob-шаблоны имен, например *.example.com
+	FQDNs []string
+	Types []string
+	// LastEventID — как заголовок Last-Event-ID: поток продолжится после
+	// этого события. Пусто — только новые события
+	LastEventID string
+}
+
+// Events читает поток событий (Server-Sent Events) и передает их в fn.
+// Возвращает ошибку fn, ошибку соединения или nil, если сервер закрыл поток;
+// для продолжения передайте ID последнего события в LastEventID
+func (c *Client) Events(ctx context.Context, opts EventOptions, fn func(Event) error) error {
+	q := url.Values{}
+	if len(opts.FQDNs) > 0 {
+		q.Set("fqdn", strings.Join(opts.FQDNs, ","))
+	}
+	if len(opts.Types) > 0 {
+		q.Set("type", strings.Join(opts.Types, ","))
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, "/api/events", q, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if opts.LastEventID != "" {
+		req.Header.Set("Last-Event-ID", opts.LastEventID)
+	}
+
+	resp, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" {
+			// Комментарии (": ping") и поля id/event не нужны: все есть в data
+			if value, ok := strings.CutPrefix(line, "data:"); ok {
+				data = append(data, strings.TrimPrefix(value, " "))
+			}
+			continue
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		var ev Event
+		if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &ev); err != nil {
+			return fmt.Errorf("failed to decode event: %w", err)
+		}
+		data = data[:0]
+		if err := fn(ev); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return scanner.Err()
+}
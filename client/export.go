@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// FirewallOptions выбирает адреса и оформление набора правил.
+// Нужен хотя бы один FQDN или группа
+type FirewallOptions struct {
+	FQDNs  []string
+	Groups []string
+	// Name — имя набора или цепочки, Table — таблица nftables
+	Name  string
+	Table string
+	// Family — ipv4, ipv6 или пусто для обоих
+	Family string
+}
+
+// ExportFirewall возвращает набор правил в формате nftables, ipset или iptables
+func (c *Client) ExportFirewall(ctx context.Context, format string, opts FirewallOptions) ([]byte, error) {
+	q := url.Values{}
+	if len(opts.FQDNs) > 0 {
+		q.Set("fqdn", strings.Join(opts.FQDNs, ","))
+	}
+	if len(opts.Groups) > 0 {
+		q.Set("group", strings.Join(opts.Groups, ","))
+	}
+	for key, value := range map[string]string{"name": opts.Name, "table": opts.Table, "family": opts.Family} {
+		if value != "" {
+			q.Set(key, value)
+		}
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, "/api/export/firewall/"+url.PathEscape(format), q, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
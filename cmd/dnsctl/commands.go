@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"dns-resolver/client"
+	"errors"
+	"flag"
+	"fmt"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// pageSize — наибольшая страница, которую отдает API
+	pageSize = 1000
+
+	reconnectDelay = 2 * time.Second
+)
+
+type handler = func(ctx context.Context, env *env, args []string) error
+
+func splitList(list string) []string {
+	var res []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// collect проходит страницы по курсору, пока не наберет limit элементов
+// (0 — все)
+func collect[T any](limit int, fetch func(opts client.ListOptions) ([]T, string, error)) ([]T, error) {
+	res := []T{}
+	opts := client.ListOptions{Limit: pageSize}
+	for {
+		if limit > 0 {
+			opts.Limit = min(pageSize, limit-len(res))
+		}
+		items, next, err := fetch(opts)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, items...)
+		if next == "" || limit > 0 && len(res) >= limit {
+			return res, nil
+		}
+		opts.Cursor = next
+	}
+}
+
+// each выполняет fn для каждого аргумента. Ошибки не прерывают обработку
+// остальных: они печатаются, а в конце возвращается их число
+func each(args []string, fn func(arg string) error) error {
+	if len(args) == 0 {
+		return usageError("at least one argument is required")
+	}
+
+	failed := 0
+	for _, arg := range args {
+		if err := fn(arg); err != nil {
+			fmt.Fprintf(os.Stderr, "dnsctl: %s: %v\n", arg, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d failed", failed, len(args))
+	}
+	return nil
+}
+
+// domainName показывает U-label рядом с именем, если они различаются
+func domainName(d client.Domain) string {
+	if d.FQDNUnicode != "" && d.FQDNUnicode != d.FQDN {
+		return fmt.Sprintf("%s (%s)", d.FQDN, d.FQDNUnicode)
+	}
+	return d.FQDN
+}
+
+func recordIPs(records []client.Record) string {
+	ips := make([]string, len(records))
+	for i, r := range records {
+		ips[i] = r.IP
+	}
+	return strings.Join(ips, ",")
+}
+
+func printDomains(env *env, domains []client.Domain) error {
+	rows := make([][]string, len(domains))
+	for i, d := range domains {
+		rows[i] = []string{domainName(d), recordIPs(d.Records)}
+	}
+	return env.out.print(domains, []string{"FQDN", "IPS"}, rows)
+}
+
+func listFlags(fs *flag.FlagSet) handler {
+	limit := fs.Int("limit", 0, "maximum number of domains, 0 for all")
+	sort := fs.String("sort", "name", "sort order: name or -name")
+
+	return func(ctx context.Context, env *env, args []string) error {
+		if len(args) > 0 {
+			return usageError("list takes no arguments")
+		}
+
+		domains, err := collect(*limit, func(opts client.ListOptions) ([]client.Domain, string, error) {
+			opts.Sort = *sort
+			page, err := env.client.ListDomains(ctx, opts)
+			if err != nil {
+				return nil, "", err
+			}
+			return page.Domains, page.NextCursor, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		rows := make([][]string, len(domains))
+		for i, d := range domains {
+			rows[i] = []string{domainName(d)}
+		}
+		return env.out.print(domains, []string{"FQDN"}, rows)
+	}
+}
+
+func addFlags(fs *flag.FlagSet) handler {
+	return func(ctx context.Context, env *env, args []string) error {
+		domains := []client.Domain{}
+		err := each(args, func(fqdn string) error {
+			domain, err := env.client.AddDomain(ctx, fqdn)
+			if err != nil {
+				return err
+			}
+			domains = append(domains, *domain)
+			return nil
+		})
+		return errors.Join(printDomains(env, domains), err)
+	}
+}
+
+func removeFlags(fs *flag.FlagSet) handler {
+	return func(ctx context.Context, env *env, args []string) error {
+		removed := []string{}
+		err := each(args, func(fqdn string) error {
+			if err := env.client.RemoveDomain(ctx, fqdn); err != nil {
+				return err
+			}
+			removed = append(removed, fqdn)
+			return nil
+		})
+
+		rows := make([][]string, len(removed))
+		for i, fqdn := range removed {
+			rows[i] = []string{fqdn}
+		}
+		return errors.Join(env.out.print(removed, []string{"REMOVED"}, rows), err)
+	}
+}
+
+// lookupResult — ответ на один запрос lookup: адреса домена или домены адреса
+type lookupResult struct {
+	Query string          `json:"query" yaml:"query"`
+	IPs   []client.Record `json:"ips,omitempty" yaml:"ips,omitempty"`
+	FQDNs []client.Domain `json:"fqdns,omitempty" yaml:"fqdns,omitempty"`
+}
+
+func lookupFlags(fs *flag.FlagSet) handler {
+	return func(ctx context.Context, env *env, args []string) error {
+		results := []lookupResult{}
+		var rows [][]string
+		err := each(args, func(query string) error {
+			res := lookupResult{Query: query}
+			var err error
+			if _, parseErr := netip.ParseAddr(query); parseErr == nil {
+				res.FQDNs, err = collect(0, func(opts client.ListOptions) ([]client.Domain, string, error) {
+					page, err := env.client.LookupFQDNs(ctx, query, opts)
+					if err != nil {
+						return nil, "", err
+					}
+					return page.Domains, page.NextCursor, nil
+				})
+			} else {
+				res.IPs, err = collect(0, func(opts client.ListOptions) ([]client.Record, string, error) {
+					page, err := env.client.LookupIPs(ctx, query, opts)
+					if err != nil {
+						return nil, "", err
+					}
+					return page.Records, page.NextCursor, nil
+				})
+			}
+			if err != nil {
+				return err
+			}
+
+			results = append(results, res)
+			for _, r := range res.IPs {
+				rows = append(rows, []string{query, r.IP})
+			}
+			for _, d := range res.FQDNs {
+				rows = append(rows, []string{query, domainName(d)})
+			}
+			if len(res.IPs) == 0 && len(res.FQDNs) == 0 {
+				rows = append(rows, []string{query, "-"})
+			}
+			return nil
+		})
+		return errors.Join(env.out.print(results, []string{"QUERY", "ANSWER"}, rows), err)
+	}
+}
+
+func refreshFlags(fs *flag.FlagSet) handler {
+	all := fs.Bool("all", false, "start an unscheduled pass over all tenants instead (service admin key)")
+
+	return func(ctx context.Context, env *env, args []string) error {
+		if !*all {
+			domains := []client.Domain{}
+			err := each(args, func(fqdn string) error {
+				domain, err := env.client.RefreshDomain(ctx, fqdn)
+				if err != nil {
+					return err
+				}
+				domains = append(domains, *domain)
+				return nil
+			})
+			return errors.Join(printDomains(env, domains), err)
+		}
+
+		if len(args) > 0 {
+			return usageError("-all takes no arguments")
+		}
+		status, err := env.client.RefreshAll(ctx)
+		if err != nil {
+			return err
+		}
+
+		nextRun := "-"
+		if status.NextRun != nil {
+			nextRun = status.NextRun.Format(time.RFC3339)
+		}
+		row := []string{strconv.FormatBool(status.Active), strconv.FormatBool(status.Paused), status.Interval, nextRun}
+		return env.out.print(status, []string{"ACTIVE", "PAUSED", "INTERVAL", "NEXT RUN"}, [][]string{row})
+	}
+}
+
+func exportFlags(fs *flag.FlagSet) handler {
+	format := fs.String("format", "nftables", "rule set format: nftables, ipset or iptables")
+	fqdns := fs.String("fqdn", "", "comma-separated list of FQDNs")
+	groups := fs.String("group", "", "comma-separated list of domain groups")
+	name := fs.String("name", "", "set or chain name (server default if empty)")
+	table := fs.String("table", "", "nftables table name (server default if empty)")
+	family := fs.String("family", "", "ipv4, ipv6 or empty for both (required for iptables)")
+	file := fs.String("file", "", "output file (stdout if empty)")
+
+	return func(ctx context.Context, env *env, args []string) error {
+		opts := client.FirewallOptions{
+			FQDNs:  splitList(*fqdns),
+			Groups: splitList(*groups),
+			Name:   *name,
+			Table:  *table,
+			Family: *family,
+		}
+		if len(args) > 0 {
+			return usageError("export takes no arguments")
+		}
+		if len(opts.FQDNs) == 0 && len(opts.Groups) == 0 {
+			return usageError("-fqdn or -group is required")
+		}
+
+		// Набор правил выводится как есть, формат -o к нему не относится
+		body, err := env.client.ExportFirewall(ctx, *format, opts)
+		if err != nil {
+			return err
+		}
+		if *file == "" {
+			_, err = os.Stdout.Write(body)
+			return err
+		}
+
+		// Не трогаем файл, если содержимое не изменилось
+		if current, err := os.ReadFile(*file); err == nil && bytes.Equal(current, body) {
+			return nil
+		}
+		return os.WriteFile(*file, body, 0o644)
+	}
+}
+
+func eventsFlags(fs *flag.FlagSet) handler {
+	fqdns := fs.String("fqdn", "", "comma-separated FQDN glob patterns, e.g. *.example.com")
+	types := fs.String("type", "", "comma-separated event types")
+	lastEventID := fs.String("last-event-id", "", "replay events after this ID")
+
+	return func(ctx context.Context, env *env, args []string) error {
+		if len(args) > 0 {
+			return usageError("events takes no arguments")
+		}
+
+		opts := client.EventOptions{FQDNs: splitList(*fqdns), Types: splitList(*types), LastEventID: *lastEventID}
+		var outErr error
+		for {
+			err := env.client.Events(ctx, opts, func(ev client.Event) error {
+				// После переподключения поток продолжится с этого события
+				opts.LastEventID = strconv.FormatUint(uint64(ev.ID), 10)
+				detail := ev.IP
+				if ev.Error != "" {
+					detail = ev.Error
+				}
+				outErr = env.out.stream(ev, []string{ev.OccurredAt.Format(time.RFC3339), ev.Type, ev.FQDN, detail})
+				return outErr
+			})
+
+			var apiErr *client.Error
+			switch {
+			case ctx.Err() != nil:
+				return nil
+			case outErr != nil, errors.As(err, &apiErr):
+				return err
+			case err != nil:
+				fmt.Fprintf(os.Stderr, "dnsctl: event stream interrupted: %v, reconnecting\n", err)
+			default:
+				fmt.Fprintln(os.Stderr, "dnsctl: event stream closed by server, reconnecting")
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(reconnectDelay):
+			}
+		}
+	}
+}
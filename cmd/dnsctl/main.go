@@ -0,0 +1,138 @@
+// Команда dnsctl управляет сервисом через REST API: добавляет и удаляет
+// домены, ищет адреса и имена, запускает обновление, выгружает наборы
+// правил файрвола и показывает поток событий.
+//
+//	export DNSCTL_SERVER=http://dns-resolver:8080 DNSCTL_API_KEY=...
+//	dnsctl add github.com api.github.com
+//	dnsctl lookup -o json github.com 140.82.121.4
+//	dnsctl export -format ipset -group payments-egress
+//	dnsctl events -fqdn '*.github.com'
+package main
+
+import (
+	"context"
+	"dns-resolver/client"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// command — подкоманда dnsctl
+type command struct {
+	name    string
+	usage   string
+	summary string
+	// flags регистрирует флаги подкоманды и возвращает ее обработчик,
+	// который получает аргументы после флагов
+	flags func(fs *flag.FlagSet) func(ctx context.Context, env *env, args []string) error
+	// stream — подкоманда работает до прерывания, общий таймаут к ней не применяется
+	stream bool
+}
+
+var commands = []command{
+	{name: "list", usage: "list [-limit N] [-sort name|-name]", summary: "list tracked domains", flags: listFlags},
+	{name: "add", usage: "add FQDN...", summary: "resolve and start tracking domains", flags: addFlags},
+	{name: "remove", usage: "remove FQDN...", summary: "stop tracking domains and drop their records", flags: removeFlags},
+	{name: "lookup", usage: "lookup FQDN|IP...", summary: "show IPs of a domain or domains of an IP", flags: lookupFlags},
+	{name: "refresh", usage: "refresh FQDN... | refresh -all", summary: "re-resolve domains now (admin role)", flags: refreshFlags},
+	{name: "export", usage: "export [-format nftables|ipset|iptables] -fqdn LIST | -group LIST", summary: "print a firewall rule set", flags: exportFlags},
+	{name: "events", usage: "events [-fqdn PATTERNS] [-type TYPES] [-last-event-id ID]", summary: "tail record change events", flags: eventsFlags, stream: true},
+}
+
+// env — общие для подкоманд клиент и вывод
+type env struct {
+	client *client.Client
+	out    *printer
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: dnsctl COMMAND [flags] [args]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun 'dnsctl COMMAND -h' for command flags.\n"+
+		"Server and credentials default to DNSCTL_SERVER, DNSCTL_API_KEY and DNSCTL_TOKEN.\n")
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "-help" || os.Args[1] == "help" {
+		usage()
+		os.Exit(2)
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == os.Args[1] {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "dnsctl: unknown command %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("dnsctl "+cmd.name, flag.ExitOnError)
+	server := fs.String("server", envOr("DNSCTL_SERVER", "http://localhost:8080"), "service base URL")
+	apiKey := fs.String("api-key", os.Getenv("DNSCTL_API_KEY"), "API key")
+	token := fs.String("token", os.Getenv("DNSCTL_TOKEN"), "JWT bearer token (instead of an API key)")
+	format := fs.String("o", string(formatTable), "output format: table, json or yaml")
+	timeout := fs.Duration("timeout", client.DefaultTimeout, "request timeout")
+	run := cmd.flags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: dnsctl %s\n\n%s%s.\n\nFlags:\n", cmd.usage, strings.ToUpper(cmd.summary[:1]), cmd.summary[1:])
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[2:])
+
+	out, err := newPrinter(os.Stdout, outputFormat(*format))
+	if err != nil {
+		fail(err)
+	}
+	c, err := client.New(*server, client.WithAPIKey(*apiKey), client.WithBearerToken(*token), client.WithUserAgent("dnsctl"))
+	if err != nil {
+		fail(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if !cmd.stream {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	err = run(ctx, &env{client: c, out: out}, fs.Args())
+	var usageErr usageError
+	switch {
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(os.Stderr, "dnsctl %s: %v\n\n", cmd.name, err)
+		fs.Usage()
+		os.Exit(2)
+	case err != nil:
+		fail(err)
+	}
+}
+
+// usageError — ошибка в аргументах командной строки
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "dnsctl: %v\n", err)
+	os.Exit(1)
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+type outputFormat string
+
+const (
+	formatTable outputFormat = "table"
+	formatJSON  outputFormat = "json"
+	formatYAML  outputFormat = "yaml"
+)
+
+// printer выводит результат подкоманды таблицей для человека
+// или JSON/YAML для скриптов
+type printer struct {
+	w      io.Writer
+	format outputFormat
+}
+
+func newPrinter(w io.Writer, format outputFormat) (*printer, error) {
+	switch format {
+	case formatTable, formatJSON, formatYAML:
+		return &printer{w: w, format: format}, nil
+	}
+	return nil, fmt.Errorf("unsupported output format %q", format)
+}
+
+// print выводит v как JSON или YAML, а в табличном формате — header и rows
+func (p *printer) print(v interface{}, header []string, rows [][]string) error {
+	switch p.format {
+	case formatJSON:
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case formatYAML:
+		return p.yaml(v)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// stream выводит один элемент потока: JSON построчно, YAML отдельными
+// документами, таблицу — строкой без выравнивания по соседним
+func (p *printer) stream(v interface{}, row []string) error {
+	switch p.format {
+	case formatJSON:
+		return json.NewEncoder(p.w).Encode(v)
+	case formatYAML:
+		if _, err := fmt.Fprintln(p.w, "---"); err != nil {
+			return err
+		}
+		return p.yaml(v)
+	}
+	_, err := fmt.Fprintln(p.w, strings.Join(row, "  "))
+	return err
+}
+
+func (p *printer) yaml(v interface{}) error {
+	enc := yaml.NewEncoder(p.w)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
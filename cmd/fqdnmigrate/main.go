@@ -0,0 +1,64 @@
+// Команда fqdnmigrate приводит уже сохраненные FQDN к каноническому виду
+// тем же валидатором, что и API: Unicode-имена и варианты регистра сливаются
+// с записями A-label, а строки с некорректными именами переносятся
+// в quarantined_records. Запуск повторно ничего не меняет.
+//
+//	fqdnmigrate -dry-run
+//	fqdnmigrate -dsn "host=postgres ..."
+package main
+
+import (
+	"context"
+	"dns-resolver/internal/repository"
+	"dns-resolver/internal/validator"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// errDryRun откатывает транзакцию пробного запуска
+var errDryRun = errors.New("dry run")
+
+func main() {
+	dsn := flag.String("dsn", repository.ProdDSN, "PostgreSQL DSN")
+	dryRun := flag.Bool("dry-run", false, "report changes and roll them back")
+	flag.Parse()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	if err := run(ctx, *dsn, *dryRun); err != nil {
+		fmt.Fprintf(os.Stderr, "fqdnmigrate: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, dsn string, dryRun bool) error {
+	db, err := repository.Open(dsn)
+	if err != nil {
+		return fmt.Errorf("failed to connect DB: %w", err)
+	}
+	repo := repository.NewDB(db)
+
+	var renamed, quarantined int64
+	err = repo.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		renamed, quarantined, err = repo.CanonicalizeFQDNs(ctx, validator.CanonicalFQDN)
+		if err == nil && dryRun {
+			return errDryRun
+		}
+		return err
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return err
+	}
+
+	fmt.Printf("renamed %d names, quarantined %d rows", renamed, quarantined)
+	if dryRun {
+		fmt.Print(" (dry run, nothing changed)")
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,112 @@
+// Команда fwgen формирует наборы правил файрвола из IP-адресов FQDN,
+// читая данные напрямую из базы сервиса.
+//
+//	fwgen -format nftables -fqdn github.com,api.github.com | nft -f -
+//	fwgen -format ipset -group payments-egress -o /etc/ipset.d/egress
+package main
+
+import (
+	"bytes"
+	"context"
+	"dns-resolver/internal/firewall"
+	"dns-resolver/internal/models"
+	"dns-resolver/internal/repository"
+	"dns-resolver/internal/validator"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+func main() {
+	format := flag.String("format", "nftables", "output format: nftables, ipset or iptables")
+	fqdnList := flag.String("fqdn", "", "comma-separated list of FQDNs")
+	groupList := flag.String("group", "", "comma-separated list of domain groups")
+	name := flag.String("name", firewall.DefaultName, "set or chain name")
+	table := flag.String("table", firewall.DefaultTable, "nftables table name")
+	family := flag.String("family", "", "ipv4, ipv6 or empty for both (required for iptables)")
+	dsn := flag.String("dsn", repository.ProdDSN, "PostgreSQL DSN")
+	tenant := flag.Uint("tenant", uint(models.DefaultTenantID), "tenant ID")
+	output := flag.String("o", "", "output file (stdout if empty)")
+	flag.Parse()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	ctx = models.WithTenant(ctx, *tenant)
+
+	if err := run(ctx, *format, *fqdnList, *groupList, *name, *table, *family, *dsn, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "fwgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func splitList(list string) []string {
+	var res []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+func run(ctx context.Context, format, fqdnList, groupList, name, table, family, dsn, output string) error {
+	fqdns, groups := splitList(fqdnList), splitList(groupList)
+	if len(fqdns) == 0 && len(groups) == 0 {
+		return fmt.Errorf("-fqdn or -group is required")
+	}
+
+	db, err := repository.Open(dsn)
+	if err != nil {
+		return fmt.Errorf("failed to connect DB: %w", err)
+	}
+	repo := repository.NewDB(db)
+
+	for i, raw := range fqdns {
+		fqdn, err := validator.CanonicalFQDN(raw)
+		if err != nil {
+			return err
+		}
+		fqdns[i] = fqdn
+	}
+
+	var ips []string
+	if len(fqdns) > 0 {
+		found, err := repo.GetIPsByFQDNs(ctx, fqdns)
+		if err != nil {
+			return fmt.Errorf("failed to get IPs: %w", err)
+		}
+		for _, fqdn := range fqdns {
+			ips = append(ips, found[fqdn]...)
+		}
+	}
+	for _, group := range groups {
+		res, err := repo.GetIPsByGroup(ctx, group)
+		if err != nil {
+			return fmt.Errorf("failed to get IPs for group %s: %w", group, err)
+		}
+		ips = append(ips, res...)
+	}
+
+	body, err := firewall.Render(firewall.Options{
+		Format: firewall.Format(format),
+		Name:   name,
+		Table:  table,
+		Family: firewall.Family(family),
+	}, ips)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(body)
+		return err
+	}
+
+	// Не трогаем файл, если содержимое не изменилось
+	if current, err := os.ReadFile(output); err == nil && bytes.Equal(current, body) {
+		return nil
+	}
+	return os.WriteFile(output, body, 0o644)
+}
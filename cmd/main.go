@@ -3,13 +3,24 @@ package main
 import (
 	"context"
 	"dns-resolver/internal/api"
+	"dns-resolver/internal/auth"
 	dnsresolver "dns-resolver/internal/dns_resolver"
+	"dns-resolver/internal/grpcapi"
+	"dns-resolver/internal/leader"
+	"dns-resolver/internal/logging"
+	"dns-resolver/internal/ratelimit"
 	"dns-resolver/internal/repository"
+	"dns-resolver/internal/tracing"
 	v "dns-resolver/internal/validator"
-	"log"
+	"dns-resolver/internal/webhook"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -17,38 +28,145 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 )
 
+// updateInterval — период перепроверки записей по умолчанию; он же их TTL
+// для клиентов. Меняется на лету через /api/admin/updater/interval
+const updateInterval = 5 * time.Minute
+
+// updaterLockKey — ключ advisory-блокировки, которую держит реплика-лидер
+const updaterLockKey int64 = 0x646e735f75706474 // "dns_updt"
+
 func main() {
-	logger := log.New(os.Stdout, "DNS_RESOLVER: ", log.LstdFlags|log.Lshortfile)
+	logger, err := newLogger()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	// Компоненты берут журнал по умолчанию при создании
+	slog.SetDefault(logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	spans, err := tracesOutput()
+	if err != nil {
+		fatal(logger, "Failed to open OTEL_TRACES_FILE", err)
+	}
+	shutdownTracing, err := tracing.Setup(ctx, os.Getenv("OTEL_TRACES_EXPORTER"), spans)
+	if err != nil {
+		fatal(logger, "Failed to set up tracing", err)
+	}
 
 	db, err := repository.ProdDB()
 	if err != nil {
-		logger.Fatalf("failed to connect DB: %v", err)
+		fatal(logger, "Failed to connect DB", err)
 	}
 
 	repo := repository.NewDB(db)
 	resolver := dnsresolver.NewResolver(repo)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	webhooks := webhook.NewService(repo)
+	resolver.AddNotifier(webhooks)
+	// Живые события приходят подписчикам любой реплики через LISTEN/NOTIFY
+	resolver.UseEventFeed(repo)
+
+	// API обслуживают все реплики. В режиме leader записи обновляет только лидер,
+	// в режиме queue их перепроверяют все реплики из общей очереди, а лидер
+	// лишь заводит в ней задания
+	var updater api.UpdaterControl
+	elector := leader.NewElector(repo.AdvisoryLocker(updaterLockKey))
+	switch mode := os.Getenv("UPDATER_MODE"); mode {
+	case "", "leader":
+		// Настройки хранятся в базе: их можно менять через любую реплику
+		leaderUpdater := dnsresolver.NewUpdater(resolver, updateInterval, dnsresolver.WithSettingsStore(repo))
+		go elector.Run(ctx, leaderUpdater.Run)
+		go leaderUpdater.Watch(ctx)
+		updater = leaderUpdater
+	case "queue":
+		// Интервал и пауза общие с режимом leader: те же настройки в базе
+		worker := dnsresolver.NewWorker(resolver, repo, workerID(), updateInterval, dnsresolver.WithWorkerSettings(repo))
+		go elector.Run(ctx, worker.Schedule)
+		go worker.Run(ctx)
+		updater = worker
+	default:
+		fatal(logger, "Invalid UPDATER_MODE", fmt.Errorf("%q: want leader or queue", mode))
+	}
+	go resolver.RunEventFeed(ctx)
+	go webhooks.Run(ctx, 5*time.Second)
 
-	go resolver.DNSUpdater(ctx, 5*time.Minute)
+	retention := dnsresolver.DefaultEventRetention
+	envDuration(logger, "EVENT_RETENTION", &retention)
+	go dnsresolver.PruneEvents(ctx, repo, retention, dnsresolver.DefaultPruneInterval)
 
 	e := echo.New()
 	e.HideBanner = true
-	e.Use(middleware.Logger())
+	e.HidePort = true
+	e.Use(api.Tracing())
+	e.Use(api.RequestID())
+	e.Use(api.RequestLogger(logging.Component("http")))
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORS())
 
 	e.Validator = v.New()
+	// За балансировщиком адрес клиента берется из X-Forwarded-For,
+	// но только если запрос пришел от прокси из TRUSTED_PROXIES
+	e.IPExtractor, err = api.IPExtractor(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		fatal(logger, "Invalid TRUSTED_PROXIES", err)
+	}
+
+	limits := ratelimit.DefaultConfig()
+	envFloat(logger, "RATE_LIMIT_READ_RPS", &limits.Read.Rate)
+	envInt(logger, "RATE_LIMIT_READ_BURST", &limits.Read.Burst)
+	envFloat(logger, "RATE_LIMIT_WRITE_RPS", &limits.Write.Rate)
+	envInt(logger, "RATE_LIMIT_WRITE_BURST", &limits.Write.Burst)
+
+	// Один ограничитель на REST и gRPC: бюджеты клиента общие для обоих
+	limiter := ratelimit.New(limits)
+	// Бюджеты, измененные через API, хранятся в базе и заменяют переменные окружения
+	go limiter.Watch(ctx, repo, ratelimit.DefaultWatchInterval)
+	adminKey := os.Getenv("ADMIN_API_KEY")
+	opts := []api.Option{
+		api.WithAdminKey(adminKey),
+		api.WithRateLimiter(limiter),
+		api.WithRateLimitStore(repo),
+		// Страницы с других origin могут открыть WebSocket, только если они перечислены
+		api.WithAllowedOrigins(envList("WS_ALLOWED_ORIGINS")),
+	}
+	opts = append(opts, api.WithUpdater(updater))
+	var verifier *auth.Verifier
+	if path := os.Getenv("JWKS_FILE"); path != "" {
+		verifier, err = auth.LoadVerifier(path, os.Getenv("JWT_ISSUER"), os.Getenv("JWT_AUDIENCE"))
+		if err != nil {
+			fatal(logger, "Failed to load JWKS", err)
+		}
+		opts = append(opts, api.WithJWT(verifier))
+	}
+
+	api.NewHandler(resolver, opts...).RegisterRoutes(e)
 
-	api.NewHandler(resolver).RegisterRoutes(e)
+	grpcServer := grpcapi.NewServer(resolver, auth.NewAuthenticator(repo, adminKey, verifier), limiter)
+	go func() {
+		port := os.Getenv("GRPC_PORT")
+		if port == "" {
+			port = "9090"
+		}
+
+		lis, err := net.Listen("tcp", ":"+port)
+		if err != nil {
+			fatal(logger, "gRPC listen error", err)
+		}
+		logger.Info("Starting gRPC server", "port", port)
+		if err := grpcServer.Serve(lis); err != nil {
+			fatal(logger, "gRPC server error", err)
+		}
+	}()
 
 	go func() {
 		port := "8080"
 
-		logger.Printf("Starting server on :%s", port)
+		logger.Info("Starting server", "port", port)
 		if err := e.Start(":" + port); err != nil && err != http.ErrServerClosed {
-			logger.Fatalf("Server error: %v", err)
+			fatal(logger, "Server error", err)
 		}
 	}()
 
@@ -57,16 +175,108 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	logger.Println("Shutting down server...")
+	logger.Info("Shutting down server")
 	cancel() // Останавливаем все горутины
 
 	// Graceful shutdown сервера
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer shutdownCancel()
+	// Потоки WatchEvents сами не завершаются: по таймауту закрываем их принудительно
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		grpcServer.Stop()
+	}
 	if err := e.Shutdown(shutdownCtx); err != nil {
-		logger.Fatalf("Server shutdown error: %v", err)
+		fatal(logger, "Server shutdown error", err)
+	}
+	// Отправляем спаны, накопленные к остановке
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("Failed to flush traces", "error", err)
+	}
+	if spans != os.Stderr {
+		spans.Close()
+	}
+
+	logger.Info("Server gracefully stopped")
+}
+
+// newLogger настраивает журнал по LOG_LEVEL (debug, info, warn, error)
+// и LOG_FORMAT (json, text)
+func newLogger() (*slog.Logger, error) {
+	level, err := logging.ParseLevel(os.Getenv("LOG_LEVEL"))
+	if err != nil {
+		return nil, err
 	}
+	return logging.New(os.Stdout, level, os.Getenv("LOG_FORMAT"))
+}
+
+// tracesOutput возвращает, куда экспортер stdout пишет спаны: в файл
+// OTEL_TRACES_FILE или в stderr. В stdout идет журнал, и спаны в нем
+// ломали бы разбор JSON-строк
+func tracesOutput() (*os.File, error) {
+	if path := os.Getenv("OTEL_TRACES_FILE"); path != "" {
+		return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	}
+	return os.Stderr, nil
+}
 
-	logger.Println("Server gracefully stopped")
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+func envFloat(logger *slog.Logger, name string, dst *float64) {
+	if raw := os.Getenv(name); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			fatal(logger, "Invalid "+name, err)
+		}
+		*dst = v
+	}
 }
 
+func envInt(logger *slog.Logger, name string, dst *int) {
+	if raw := os.Getenv(name); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			fatal(logger, "Invalid "+name, err)
+		}
+		*dst = v
+	}
+}
+
+func envDuration(logger *slog.Logger, name string, dst *time.Duration) {
+	if raw := os.Getenv(name); raw != "" {
+		v, err := time.ParseDuration(raw)
+		if err != nil {
+			fatal(logger, "Invalid "+name, err)
+		}
+		*dst = v
+	}
+}
+
+// envList разбирает список через запятую
+func envList(name string) []string {
+	var res []string
+	for _, item := range strings.Split(os.Getenv(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// workerID отличает реплику в очереди перепроверки
+func workerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
@@ -0,0 +1,9 @@
+// Пакет docs встраивает спецификацию OpenAPI в бинарник сервиса
+package docs
+
+import _ "embed"
+
+// OpenAPI — содержимое openapi.yaml
+//
+//go:embed openapi.yaml
+var OpenAPI []byte
@@ -1,20 +1,163 @@
 package api
 
 import (
+	"dns-resolver/internal/auth"
 	dnsresolver "dns-resolver/internal/dns_resolver"
+	"dns-resolver/internal/logging"
+	"dns-resolver/internal/models"
+	"dns-resolver/internal/ratelimit"
+	"log/slog"
+	"time"
+
+	"github.com/getkin/kin-openapi/routers"
 	"github.com/labstack/echo/v4"
 )
 
 type Handler struct {
 	resolver *dnsresolver.Resolver
+	adminKey string
+	jwt      *auth.Verifier
+	limiter  *ratelimit.Limiter
+	// limitStore сохраняет бюджеты для всех реплик; без него они меняются только здесь
+	limitStore ratelimit.Store
+	auth       *auth.Authenticator
+	logger     *slog.Logger
+
+	recordTTL time.Duration
+	updater   UpdaterControl
+	// allowedOrigins — откуда браузеру можно открыть WebSocket, кроме своего origin
+	allowedOrigins []string
+	// auditActions — действия аудита изменяющих маршрутов по "METHOD /path"
+	auditActions map[string]string
+
+	spec       routers.Router
+	reportSpec func(error)
+}
+
+type Option func(*Handler)
+
+// WithAdminKey задает служебный ключ с ролью admin в арендаторе по умолчанию
+func WithAdminKey(key string) Option {
+	return func(h *Handler) {
+		h.adminKey = key
+	}
+}
+
+// WithJWT включает прием JWT, подписанных ключами из JWKS
+func WithJWT(verifier *auth.Verifier) Option {
+	return func(h *Handler) {
+		h.jwt = verifier
+	}
+}
+
+// WithRateLimiter включает ограничение частоты запросов по адресу и учетным данным
+func WithRateLimiter(limiter *ratelimit.Limiter) Option {
+	return func(h *Handler) {
+		h.limiter = limiter
+	}
 }
 
-func NewHandler(resolver *dnsresolver.Resolver) *Handler {
-	return &Handler{resolver: resolver}
+// WithRateLimitStore сохраняет бюджеты, измененные через API, в store:
+// их применяют все реплики, и они переживают перезапуск
+func WithRateLimitStore(store ratelimit.Store) Option {
+	return func(h *Handler) {
+		h.limitStore = store
+	}
+}
+
+func NewHandler(resolver *dnsresolver.Resolver, opts ...Option) *Handler {
+	h := &Handler{resolver: resolver, logger: logging.Component("api"), auditActions: map[string]string{}}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.auth = auth.NewAuthenticator(resolver, h.adminKey, h.jwt)
+
+	spec, err := loadSpec()
+	if err != nil {
+		// Спецификация встроена при сборке, ошибка здесь — ошибка сборки
+		panic("api: invalid embedded OpenAPI spec: " + err.Error())
+	}
+	h.spec = spec
+	return h
 }
 
 func (h *Handler) RegisterRoutes(e *echo.Echo) {
-	e.POST("/api/fqdns", h.AddFQDN)
-	e.GET("/api/fqdns", h.GetFQDNsByIP)
-	e.GET("/api/ips", h.GetIPsByFQDN)
-}
\ No newline at end of file
+	e.HTTPErrorHandler = h.HandleError
+	// Адрес клиента не берется из заголовков, если доверенные прокси не заданы
+	if e.IPExtractor == nil {
+		e.IPExtractor = echo.ExtractIPDirect()
+	}
+
+	viewer := h.Require(models.RoleViewer)
+	editor := h.Require(models.RoleEditor)
+	admin := h.Require(models.RoleAdmin)
+
+	e.GET("/health", h.Health)
+	e.GET("/openapi.yaml", h.OpenAPISpec)
+	e.GET("/docs", h.SwaggerUI)
+
+	// Аудит стоит перед аутентификацией, чтобы отказы 401 и 403 тоже попадали в журнал
+	api := e.Group("/api", h.LimitByClient, h.Audit, h.Authenticate, h.LimitByCredential, h.ValidateSpec)
+
+	api.GET("/me", h.Me, viewer)
+
+	h.audited(api.POST("/fqdns", h.AddFQDN, editor), "fqdn.add")
+	api.GET("/fqdns", h.GetFQDNsByIP, viewer)
+	h.audited(api.DELETE("/fqdns/:fqdn", h.DeleteFQDN, editor), "fqdn.delete")
+	h.audited(api.POST("/fqdns/:fqdn/refresh", h.RefreshFQDN, admin), "fqdn.refresh")
+	api.GET("/ips", h.GetIPsByFQDN, viewer)
+	api.GET("/domains", h.SearchDomains, viewer)
+	api.POST("/fqdns\\:batchGet", h.BatchGetFQDNs, viewer)
+	api.POST("/ips\\:batchGet", h.BatchGetIPs, viewer)
+
+	v2 := api.Group("/v2")
+	v2.GET("/domains", h.ListDomains, viewer)
+	h.audited(v2.POST("/domains", h.CreateDomain, editor), "fqdn.add")
+	v2.GET("/domains/:fqdn", h.GetDomain, viewer)
+	h.audited(v2.DELETE("/domains/:fqdn", h.DeleteFQDN, editor), "fqdn.delete")
+	v2.GET("/domains/:fqdn/records", h.ListDomainRecords, viewer)
+	v2.GET("/addresses/:ip/domains", h.ListAddressDomains, viewer)
+
+	h.audited(api.POST("/webhooks", h.AddWebhook, editor), "webhook.create")
+	api.GET("/webhooks", h.ListWebhooks, viewer)
+	h.audited(api.DELETE("/webhooks/:id", h.DeleteWebhook, editor), "webhook.delete")
+	api.GET("/webhooks/:id/deliveries", h.ListWebhookDeliveries, viewer)
+
+	api.GET("/events", h.StreamEvents, viewer)
+	api.GET("/events/ws", h.StreamEventsWS, viewer)
+
+	h.audited(api.POST("/groups", h.AddGroup, editor), "group.create")
+	api.GET("/groups", h.ListGroups, viewer)
+	api.GET("/groups/:name", h.GetGroup, viewer)
+	h.audited(api.PUT("/groups/:name", h.UpdateGroup, editor), "group.update")
+	h.audited(api.DELETE("/groups/:name", h.DeleteGroup, editor), "group.delete")
+	api.GET("/groups/:name/fqdns", h.ListGroupFQDNs, viewer)
+	h.audited(api.PUT("/groups/:name/fqdns/:fqdn", h.AddGroupFQDN, editor), "group.member.add")
+	h.audited(api.DELETE("/groups/:name/fqdns/:fqdn", h.RemoveGroupFQDN, editor), "group.member.remove")
+	api.GET("/groups/:name/ips", h.GetGroupIPs, viewer)
+
+	api.GET("/export/firewall/:format", h.ExportFirewall, viewer)
+	api.GET("/export/kubernetes/:flavor", h.ExportKubernetes, viewer)
+
+	h.audited(api.POST("/keys", h.AddAPIKey, admin), "key.create")
+	api.GET("/keys", h.ListAPIKeys, admin)
+	h.audited(api.DELETE("/keys/:id", h.DeleteAPIKey, admin), "key.delete")
+
+	api.GET("/audit", h.ListAudit, admin)
+
+	system := api.Group("/admin", h.RequireAdmin)
+	h.audited(system.POST("/tenants", h.AddTenant), "tenant.create")
+	system.GET("/tenants", h.ListTenants)
+	h.audited(system.DELETE("/tenants/:id", h.DeleteTenant), "tenant.delete")
+	if h.limiter != nil {
+		system.GET("/ratelimits", h.GetRateLimits)
+		h.audited(system.PUT("/ratelimits", h.UpdateRateLimits), "ratelimits.update")
+	}
+	if h.updater != nil {
+		system.GET("/updater", h.GetUpdaterStatus)
+		h.audited(system.POST("/updater/pause", h.PauseUpdater), "updater.pause")
+		h.audited(system.POST("/updater/resume", h.ResumeUpdater), "updater.resume")
+		h.audited(system.PUT("/updater/interval", h.SetUpdaterInterval), "updater.interval")
+		h.audited(system.POST("/updater/refresh", h.RefreshAll), "updater.refresh")
+	}
+}
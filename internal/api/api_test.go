@@ -52,9 +52,15 @@ func setupTestDB(t *testing.T) *repository.DB {
 	require.NoError(t, err)
 
 	// Очищаем и мигрируем тестовую БД
-	err = db.Exec("DROP TABLE IF EXISTS dns_records").Error
+	err = db.Exec("DROP TABLE IF EXISTS dns_records, tenants, api_keys, audit_entries").Error
 	require.NoError(t, err)
-	err = db.AutoMigrate(&models.DNSRecord{})
+	err = db.AutoMigrate(&models.DNSRecord{}, &models.Tenant{}, &models.APIKey{}, &models.AuditEntry{})
+	require.NoError(t, err)
+
+	// Арендатор по умолчанию и ключ для запросов
+	err = db.Create(&models.Tenant{ID: models.DefaultTenantID, Name: "default"}).Error
+	require.NoError(t, err)
+	err = db.Create(&models.APIKey{TenantID: models.DefaultTenantID, Role: models.RoleEditor, Prefix: "dnsr_t", KeyHash: models.HashAPIKey(testAPIKey)}).Error
 	require.NoError(t, err)
 
 	return repository.NewDB(db)
@@ -70,47 +76,50 @@ func TestAPIWithRealDB(t *testing.T) {
 	e.Validator = v.New()
 	h.RegisterRoutes(e)
 
-	ctx := context.Background()
+	ctx := models.WithTenant(context.Background(), models.DefaultTenantID)
 
 	t.Run("POST /api/fqdns - успешное добавление", func(t *testing.T) {
 		body := `{"fqdn":"github.com."}`
 		req := httptest.NewRequest(http.MethodPost, "/api/fqdns", strings.NewReader(body))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, testAPIKey)
 		rec := httptest.NewRecorder()
 
 		e.ServeHTTP(rec, req)
 
 		assert.Equal(t, http.StatusCreated, rec.Code)
-		assert.Contains(t, rec.Body.String(), `"fqdn":"github.com."`)
+		assert.Contains(t, rec.Body.String(), `"fqdn":"github.com"`)
 		assert.Contains(t, rec.Body.String(), `"ips"`) // Проверяем что IP были получены
 	})
 
 	t.Run("GET /api/fqdns?ip=... - поиск по IP", func(t *testing.T) {
 		// Подготовка данных
-		err := db.AddOrUpdate(ctx, "github.com.", "140.82.121.4")
+		err := db.AddOrUpdate(ctx, "github.com", "140.82.121.4")
 		require.NoError(t, err)
 
 		req := httptest.NewRequest(http.MethodGet, "/api/fqdns?ip=140.82.121.4", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
 		rec := httptest.NewRecorder()
 
 		e.ServeHTTP(rec, req)
 
 		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.Contains(t, rec.Body.String(), `"fqdns":["github.com."]`)
+		assert.Contains(t, rec.Body.String(), `"fqdns":["github.com"]`)
 	})
 
-	t.Run("GET /api/ips?fqdn=github.com. - поиск по FQDN", func(t *testing.T) {
-		err := db.AddOrUpdate(ctx, "github.com.", "140.82.121.4")
+	t.Run("GET /api/ips?fqdn=GitHub.com. - поиск по FQDN", func(t *testing.T) {
+		err := db.AddOrUpdate(ctx, "github.com", "140.82.121.4")
 		require.NoError(t, err)
 
-		req := httptest.NewRequest(http.MethodGet, "/api/ips?fqdn=github.com.", nil)
+		req := httptest.NewRequest(http.MethodGet, "/api/ips?fqdn=GitHub.com.", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
 		rec := httptest.NewRecorder()
 
 		e.ServeHTTP(rec, req)
 
 		var response map[string]interface{}
 		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
-		assert.Equal(t, "github.com.", response["fqdn"])
+		assert.Equal(t, "github.com", response["fqdn"])
 		assert.NotEmpty(t, response["ips"])
 		assert.IsType(t, []interface{}{}, response["ips"])
 	})
@@ -119,6 +128,7 @@ func TestAPIWithRealDB(t *testing.T) {
 		body := `{"invalid":"data"}`
 		req := httptest.NewRequest(http.MethodPost, "/api/fqdns", strings.NewReader(body))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, testAPIKey)
 		rec := httptest.NewRecorder()
 
 		e.ServeHTTP(rec, req)
@@ -130,6 +140,7 @@ func TestAPIWithRealDB(t *testing.T) {
 		body := `{"fqdn":"support.microsoft.com"}`
 		req := httptest.NewRequest(http.MethodPost, "/api/fqdns", strings.NewReader(body))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, testAPIKey)
 		rec := httptest.NewRecorder()
 
 		e.ServeHTTP(rec, req)
@@ -146,6 +157,7 @@ func TestAPIWithRealDB(t *testing.T) {
 		q.Add("ip", "' OR '1'='1")
 		req.URL.RawQuery = q.Encode()
 
+		req.Header.Set(APIKeyHeader, testAPIKey)
 		rec := httptest.NewRecorder()
 
 		e.ServeHTTP(rec, req)
@@ -162,6 +174,7 @@ func TestAPIWithRealDB(t *testing.T) {
 		require.NoError(t, err)
 
 		req := httptest.NewRequest(http.MethodGet, "/api/fqdns?ip=104.16.85.20", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
 		rec := httptest.NewRecorder()
 
 		e.ServeHTTP(rec, req)
@@ -173,6 +186,7 @@ func TestAPIWithRealDB(t *testing.T) {
 
 	t.Run("GET /api/fqdns?ip=... - IP не найден", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/api/fqdns?ip=127.0.0.1", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
 		rec := httptest.NewRecorder()
 
 		e.ServeHTTP(rec, req)
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"dns-resolver/internal/models"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	auditTargetKey    = "audit_target"
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// setAuditTarget задает объект операции, если он приходит в теле запроса, а не в пути
+func setAuditTarget(c echo.Context, target string) {
+	c.Set(auditTargetKey, target)
+}
+
+func auditTarget(c echo.Context) string {
+	if target, ok := c.Get(auditTargetKey).(string); ok {
+		return target
+	}
+	for _, name := range []string{"fqdn", "name", "id"} {
+		if v := c.Param(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// audited отмечает маршрут изменяющей операции: Audit запишет ее как action
+func (h *Handler) audited(route *echo.Route, action string) {
+	h.auditActions[route.Method+" "+route.Path] = action
+}
+
+// Audit записывает в журнал аудита результат изменяющей операции, включая
+// отказы в доступе. Запросы без учетных данных не принадлежат ни одному
+// арендатору и попадают только в лог сервиса. Сбой записи не меняет ответ
+// клиенту, который к этому моменту уже сформирован
+func (h *Handler) Audit(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		action, ok := h.auditActions[c.Request().Method+" "+c.Path()]
+		if !ok {
+			return next(c)
+		}
+		err := next(c)
+
+		entry := models.AuditEntry{
+			Action:    action,
+			Target:    auditTarget(c),
+			RequestID: requestID(c),
+			// RealIP идет через e.IPExtractor: X-Forwarded-For учитывается
+			// только от доверенных прокси
+			SourceIP: c.RealIP(),
+			Result:   models.AuditSuccess,
+		}
+		ctx := c.Request().Context()
+		p, authenticated := principalFrom(c)
+		entry.Actor = p.Subject
+
+		entry.Status = c.Response().Status
+		if err != nil {
+			entry.Status = errorStatus(err)
+			entry.Error = err.Error()
+		}
+		if entry.Status >= http.StatusBadRequest {
+			entry.Result = models.AuditFailure
+		}
+		if !authenticated {
+			h.logger.WarnContext(ctx, "Unauthenticated mutation rejected", "action", action, "target", entry.Target,
+				"source_ip", entry.SourceIP, "status", entry.Status, "request_id", entry.RequestID)
+			return err
+		}
+
+		if auditErr := h.resolver.AppendAudit(ctx, &entry); auditErr != nil {
+			h.logger.ErrorContext(ctx, "Failed to write audit entry", "action", action, "error", auditErr)
+		}
+
+		return err
+	}
+}
+
+func parseAuditFilter(c echo.Context) (models.AuditFilter, error) {
+	filter := models.AuditFilter{
+		Actor:  c.QueryParam("actor"),
+		Action: c.QueryParam("action"),
+		Target: c.QueryParam("target"),
+		Limit:  defaultAuditLimit,
+	}
+
+	for name, dst := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if raw := c.QueryParam(name); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return filter, errors.New(name + " must be an RFC 3339 timestamp")
+			}
+			*dst = t
+		}
+	}
+
+	if raw := c.QueryParam("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxAuditLimit {
+			return filter, errors.New("limit must be between 1 and 1000")
+		}
+		filter.Limit = limit
+	}
+
+	if raw := c.QueryParam("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return filter, errors.New("offset must be a non-negative integer")
+		}
+		filter.Offset = offset
+	}
+
+	return filter, nil
+}
+
+func (h *Handler) ListAudit(c echo.Context) error {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	entries, err := h.resolver.ListAudit(c.Request().Context(), filter)
+	if err != nil {
+		return problemFromError(err)
+	}
+	if entries == nil {
+		entries = []models.AuditEntry{}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"entries": entries,
+		"limit":   filter.Limit,
+		"offset":  filter.Offset,
+	})
+}
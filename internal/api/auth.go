@@ -0,0 +1,94 @@
+package api
+
+import (
+	"crypto/rand"
+	"dns-resolver/internal/auth"
+	"dns-resolver/internal/models"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+
+	apiKeyPrefix = "dnsr_"
+	principalKey = "principal"
+)
+
+// Principal — аутентифицированный владелец запроса
+type Principal = auth.Principal
+
+func principalFrom(c echo.Context) (Principal, bool) {
+	p, ok := c.Get(principalKey).(Principal)
+	return p, ok
+}
+
+// extractCredential берет ключ или JWT из X-API-Key или Authorization: Bearer
+func extractCredential(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	return auth.BearerToken(r.Header.Get(echo.HeaderAuthorization))
+}
+
+// Authenticate проверяет API-ключ или JWT и привязывает запрос к арендатору:
+// все обращения к репозиторию дальше идут в его области
+func (h *Handler) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		p, err := h.auth.Authenticate(c.Request().Context(), extractCredential(c.Request()))
+		if errors.Is(err, auth.ErrUnauthenticated) {
+			return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+		}
+		if err != nil {
+			return problemFromError(err)
+		}
+
+		c.Set(principalKey, p)
+		c.SetRequest(c.Request().WithContext(models.WithTenant(c.Request().Context(), p.TenantID)))
+		return next(c)
+	}
+}
+
+// Require пропускает запрос, только если роль владельца не ниже role
+func (h *Handler) Require(role models.Role) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if p, ok := principalFrom(c); !ok || !p.Role.Allows(role) {
+				return echo.NewHTTPError(http.StatusForbidden, string(role)+" role required")
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireAdmin ограничивает управление сервисом служебным ключом оператора.
+// Администратор арендатора по умолчанию остается администратором арендатора
+func (h *Handler) RequireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		p, ok := principalFrom(c)
+		if !ok || !p.ServiceAdmin {
+			return echo.NewHTTPError(http.StatusForbidden, "service admin access required")
+		}
+		return next(c)
+	}
+}
+
+func (h *Handler) Me(c echo.Context) error {
+	p, _ := principalFrom(c)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"tenant_id": p.TenantID,
+		"subject":   p.Subject,
+		"role":      p.Role,
+	})
+}
+
+func generateAPIKey() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + hex.EncodeToString(buf), nil
+}
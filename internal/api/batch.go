@@ -0,0 +1,94 @@
+package api
+
+import (
+	"net/http"
+	"net/netip"
+
+	"github.com/labstack/echo/v4"
+)
+
+type BatchGetFQDNsRequest struct {
+	IPs []string `json:"ips" validate:"required,min=1,max=1000,dive,ip"`
+}
+
+type BatchGetIPsRequest struct {
+	FQDNs []string `json:"fqdns" validate:"required,min=1,max=1000"`
+}
+
+// BatchGetFQDNs ищет FQDN для списка IP одним запросом к базе.
+// Каждый запрошенный IP есть в ответе, для неизвестных — пустой список
+func (h *Handler) BatchGetFQDNs(c echo.Context) error {
+	var req BatchGetFQDNsRequest
+	if err := c.Bind(&req); err != nil {
+		return malformedRequest(err)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return validationProblem(err)
+	}
+
+	// Адреса приводятся к виду, в котором их сохраняет резолвер: "2001:DB8::1"
+	// ищется как "2001:db8::1", "::ffff:1.1.1.1" — как "1.1.1.1"
+	canonical := make(map[string]string, len(req.IPs))
+	query := make([]string, 0, len(req.IPs))
+	for _, raw := range req.IPs {
+		addr, err := netip.ParseAddr(raw)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid ip "+raw)
+		}
+		canonical[raw] = addr.Unmap().String()
+		query = append(query, canonical[raw])
+	}
+
+	found, err := h.resolver.GetFQDNsByIPs(c.Request().Context(), query)
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	res := make(map[string][]string, len(req.IPs))
+	for _, raw := range req.IPs {
+		res[raw] = nonNil(found[canonical[raw]])
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"fqdns": res,
+	})
+}
+
+// BatchGetIPs ищет IP для списка FQDN одним запросом к базе. Ключи ответа —
+// имена в том виде, в каком их прислал клиент
+func (h *Handler) BatchGetIPs(c echo.Context) error {
+	var req BatchGetIPsRequest
+	if err := c.Bind(&req); err != nil {
+		return malformedRequest(err)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return validationProblem(err)
+	}
+
+	canonical := make(map[string]string, len(req.FQDNs))
+	query := make([]string, 0, len(req.FQDNs))
+	for _, raw := range req.FQDNs {
+		fqdn, err := canonicalFQDN(raw)
+		if err != nil {
+			return err
+		}
+		canonical[raw] = fqdn
+		query = append(query, fqdn)
+	}
+
+	found, err := h.resolver.GetIPsByFQDNs(c.Request().Context(), query)
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	res := make(map[string][]string, len(req.FQDNs))
+	for _, raw := range req.FQDNs {
+		res[raw] = nonNil(found[canonical[raw]])
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ips": res,
+	})
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"crypto/sha256"
+	"dns-resolver/internal/models"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// WithRecordTTL задает срок актуальности ответов о записях для Cache-Control.
+// Записи перепроверяются раз в интервал обновления, поэтому он и служит их TTL
+func WithRecordTTL(ttl time.Duration) Option {
+	return func(h *Handler) {
+		h.recordTTL = ttl
+	}
+}
+
+// ttl — текущий интервал обновления, если им управляет WithUpdater
+func (h *Handler) ttl() time.Duration {
+	if h.updater != nil {
+		return h.updater.Interval()
+	}
+	return h.recordTTL
+}
+
+// recordETag строится по состоянию набора записей и параметрам запроса,
+// поэтому не требует чтения самих записей
+func recordETag(c echo.Context, version models.RecordVersion) string {
+	var updated int64
+	if version.UpdatedAt != nil {
+		updated = version.UpdatedAt.UnixNano()
+	}
+	key := fmt.Sprintf("%d|%s|%d|%d", models.TenantID(c.Request().Context()),
+		c.Request().URL.Query().Encode(), version.Count, updated)
+	sum := sha256.Sum256([]byte(key))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// checkRecordCache выставляет ETag, Last-Modified и Cache-Control и сообщает,
+// актуальна ли копия клиента. If-None-Match имеет приоритет над
+// If-Modified-Since (RFC 9110, 13.2.2)
+func (h *Handler) checkRecordCache(c echo.Context, version models.RecordVersion) bool {
+	req, header := c.Request(), c.Response().Header()
+
+	etag := recordETag(c, version)
+	header.Set("ETag", etag)
+	if ttl := h.ttl(); ttl > 0 {
+		header.Set("Cache-Control", "private, max-age="+strconv.Itoa(int(ttl.Seconds())))
+	} else {
+		header.Set("Cache-Control", "private, no-cache")
+	}
+	if version.UpdatedAt != nil {
+		header.Set("Last-Modified", version.UpdatedAt.UTC().Format(http.TimeFormat))
+	}
+
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+	if version.UpdatedAt == nil {
+		return false
+	}
+	since, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	// Last-Modified передается с точностью до секунды
+	return err == nil && !version.UpdatedAt.Truncate(time.Second).After(since)
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"dns-resolver/internal/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+)
+
+const lastEventIDHeader = "Last-Event-ID"
+
+// splitParam собирает значения повторяющегося параметра, разделенные запятыми
+func splitParam(values []string) []string {
+	var res []string
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				res = append(res, part)
+			}
+		}
+	}
+	return res
+}
+
+func parseEventFilter(c echo.Context) (models.EventFilter, error) {
+	filter := models.EventFilter{
+		TenantID: models.TenantID(c.Request().Context()),
+		Patterns: splitParam(c.QueryParams()["fqdn"]),
+	}
+	for _, t := range splitParam(c.QueryParams()["type"]) {
+		filter.Types = append(filter.Types, models.EventType(t))
+	}
+	return filter, filter.Validate()
+}
+
+// parseLastEventID берет курсор возобновления из заголовка Last-Event-ID
+// (его шлет EventSource при переподключении) или из параметра last_event_id
+func parseLastEventID(c echo.Context) (uint, bool, error) {
+	raw := c.Request().Header.Get(lastEventIDHeader)
+	if raw == "" {
+		raw = c.QueryParam("last_event_id")
+	}
+	if raw == "" {
+		return 0, false, nil
+	}
+
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid last event id %q", raw)
+	}
+	return uint(id), true, nil
+}
+
+// StreamEvents отдает изменения записей как Server-Sent Events
+func (h *Handler) StreamEvents(c echo.Context) error {
+	filter, err := parseEventFilter(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	lastID, resume, err := parseLastEventID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set(echo.HeaderConnection, "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+
+	send := func(ev models.Event) error {
+		data, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data); err != nil {
+			return err
+		}
+		w.Flush()
+		return nil
+	}
+	heartbeat := func() error {
+		if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+			return err
+		}
+		w.Flush()
+		return nil
+	}
+
+	// Ответ уже начат, ошибку вернуть клиенту нельзя — просто закрываем поток
+	if err := h.resolver.Stream(c.Request().Context(), filter, lastID, resume, send, heartbeat); err != nil {
+		h.logger.ErrorContext(c.Request().Context(), "Event stream closed", "error", err)
+	}
+	return nil
+}
+
+// WithAllowedOrigins разрешает открывать WebSocket со страниц этих origin
+// (например, "https://console.example.com"); "*" — с любых. По умолчанию
+// разрешен только тот же origin, что у API
+func WithAllowedOrigins(origins []string) Option {
+	return func(h *Handler) {
+		h.allowedOrigins = origins
+	}
+}
+
+// checkOrigin не дает чужой странице открыть поток в браузере пользователя.
+// Клиенты вне браузера Origin не присылают и не ограничиваются
+func (h *Handler) checkOrigin(r *http.Request) error {
+	origin := r.Header.Get(echo.HeaderOrigin)
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return fmt.Errorf("invalid origin %q", origin)
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return nil
+	}
+	for _, allowed := range h.allowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return nil
+		}
+	}
+	return fmt.Errorf("origin %q is not allowed", origin)
+}
+
+// StreamEventsWS — тот же поток событий через WebSocket, по JSON-сообщению на событие
+func (h *Handler) StreamEventsWS(c echo.Context) error {
+	filter, err := parseEventFilter(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	lastID, resume, err := parseLastEventID(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	server := websocket.Server{
+		// При отказе websocket.Server отвечает 403
+		Handshake: func(_ *websocket.Config, r *http.Request) error { return h.checkOrigin(r) },
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+
+			ctx, cancel := context.WithCancel(c.Request().Context())
+			defer cancel()
+
+			// Входящие сообщения не нужны, читаем только чтобы заметить закрытие
+			go func() {
+				defer cancel()
+				var discard string
+				for websocket.Message.Receive(ws, &discard) == nil {
+				}
+			}()
+
+			send := func(ev models.Event) error {
+				return websocket.JSON.Send(ws, ev)
+			}
+			heartbeat := func() error {
+				return websocket.JSON.Send(ws, map[string]string{"type": "ping"})
+			}
+
+			if err := h.resolver.Stream(ctx, filter, lastID, resume, send, heartbeat); err != nil {
+				h.logger.ErrorContext(ctx, "Event websocket closed", "error", err)
+			}
+		},
+	}
+	server.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"dns-resolver/internal/firewall"
+	"dns-resolver/internal/netpol"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// collectIPs объединяет IP-адреса всех перечисленных FQDN одним запросом
+func (h *Handler) collectIPs(ctx context.Context, fqdns []string) ([]string, error) {
+	if len(fqdns) == 0 {
+		return nil, nil
+	}
+	found, err := h.resolver.GetIPsByFQDNs(ctx, fqdns)
+	if err != nil {
+		return nil, err
+	}
+
+	var ips []string
+	for _, fqdn := range fqdns {
+		ips = append(ips, found[fqdn]...)
+	}
+	return ips, nil
+}
+
+// selectedIPs собирает адреса для экспорта из параметров fqdn и group
+func (h *Handler) selectedIPs(c echo.Context) ([]string, error) {
+	fqdns := splitParam(c.QueryParams()["fqdn"])
+	groups := splitParam(c.QueryParams()["group"])
+	if len(fqdns) == 0 && len(groups) == 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "fqdn or group parameter is required")
+	}
+
+	for i, raw := range fqdns {
+		fqdn, err := canonicalFQDN(raw)
+		if err != nil {
+			return nil, err
+		}
+		fqdns[i] = fqdn
+	}
+
+	ctx := c.Request().Context()
+	ips, err := h.collectIPs(ctx, fqdns)
+	if err != nil {
+		return nil, problemFromError(err)
+	}
+
+	for _, group := range groups {
+		res, err := h.resolver.GetIPsByGroup(ctx, group)
+		if err != nil {
+			return nil, groupError(err)
+		}
+		ips = append(ips, res...)
+	}
+
+	return ips, nil
+}
+
+// etagMatches проверяет заголовок If-None-Match, который может содержать список тегов
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeWithETag отдает тело с ETag или 304, если у клиента актуальная версия
+func writeWithETag(c echo.Context, contentType string, body []byte, etag string) error {
+	c.Response().Header().Set("ETag", etag)
+	if etagMatches(c.Request().Header.Get("If-None-Match"), etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.Blob(http.StatusOK, contentType, body)
+}
+
+func (h *Handler) ExportFirewall(c echo.Context) error {
+	opts := firewall.Options{
+		Format: firewall.Format(c.Param("format")),
+		Name:   c.QueryParam("name"),
+		Table:  c.QueryParam("table"),
+		Family: firewall.Family(c.QueryParam("family")),
+	}
+
+	ips, err := h.selectedIPs(c)
+	if err != nil {
+		return err
+	}
+
+	body, err := firewall.Render(opts, ips)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return writeWithETag(c, echo.MIMETextPlainCharsetUTF8, body, firewall.ETag(body))
+}
+
+func (h *Handler) ExportKubernetes(c echo.Context) error {
+	selector, err := netpol.ParseSelector(c.QueryParam("selector"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	opts := netpol.Options{
+		Flavor:    netpol.Flavor(c.Param("flavor")),
+		Name:      c.QueryParam("name"),
+		Namespace: c.QueryParam("namespace"),
+		Selector:  selector,
+	}
+	for _, raw := range splitParam(c.QueryParams()["port"]) {
+		port, err := netpol.ParsePort(raw)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		opts.Ports = append(opts.Ports, port)
+	}
+
+	ips, err := h.selectedIPs(c)
+	if err != nil {
+		return err
+	}
+
+	body, err := netpol.Render(opts, ips)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return writeWithETag(c, "application/yaml", body, firewall.ETag(body))
+}
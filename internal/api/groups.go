@@ -0,0 +1,208 @@
+package api
+
+import (
+	"dns-resolver/internal/models"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AddGroupRequest struct {
+	Name        string `json:"name" validate:"required,slug"`
+	Description string `json:"description"`
+}
+
+type UpdateGroupRequest struct {
+	Description string `json:"description"`
+}
+
+type GroupResponse struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func newGroupResponse(g models.Group) GroupResponse {
+	return GroupResponse{
+		Name:        g.Name,
+		Description: g.Description,
+		CreatedAt:   g.CreatedAt,
+		UpdatedAt:   g.UpdatedAt,
+	}
+}
+
+// groupError переводит ошибку репозитория в HTTP-ответ
+func groupError(err error) error {
+	if errors.Is(err, models.ErrNotFound) {
+		return newProblem(http.StatusNotFound, CodeNotFound, "group not found", err)
+	}
+	return problemFromError(err)
+}
+
+func (h *Handler) AddGroup(c echo.Context) error {
+	var req AddGroupRequest
+	if err := c.Bind(&req); err != nil {
+		return malformedRequest(err)
+	}
+	setAuditTarget(c, req.Name)
+
+	if err := c.Validate(req); err != nil {
+		return validationProblem(err)
+	}
+
+	group := models.Group{Name: req.Name, Description: req.Description}
+
+	ctx := c.Request().Context()
+	err := h.resolver.CreateGroup(ctx, &group)
+	if errors.Is(err, models.ErrConflict) {
+		return echo.NewHTTPError(http.StatusConflict, "group already exists")
+	}
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	return c.JSON(http.StatusCreated, newGroupResponse(group))
+}
+
+func (h *Handler) ListGroups(c echo.Context) error {
+	fqdn := c.QueryParam("fqdn")
+	if fqdn != "" {
+		var err error
+		if fqdn, err = canonicalFQDN(fqdn); err != nil {
+			return err
+		}
+	}
+
+	ctx := c.Request().Context()
+	groups, err := h.resolver.ListGroups(ctx, fqdn)
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	resp := make([]GroupResponse, len(groups))
+	for i, g := range groups {
+		resp[i] = newGroupResponse(g)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"groups": resp,
+	})
+}
+
+func (h *Handler) GetGroup(c echo.Context) error {
+	ctx := c.Request().Context()
+	group, err := h.resolver.GetGroup(ctx, c.Param("name"))
+	if err != nil {
+		return groupError(err)
+	}
+
+	fqdns, err := h.resolver.ListGroupMembers(ctx, group.Name)
+	if err != nil {
+		return groupError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"name":          group.Name,
+		"description":   group.Description,
+		"fqdns":         nonNil(fqdns),
+		"fqdns_unicode": unicodeFQDNs(fqdns),
+		"created_at":    group.CreatedAt,
+		"updated_at":    group.UpdatedAt,
+	})
+}
+
+func (h *Handler) UpdateGroup(c echo.Context) error {
+	var req UpdateGroupRequest
+	if err := c.Bind(&req); err != nil {
+		return malformedRequest(err)
+	}
+
+	ctx := c.Request().Context()
+	group, err := h.resolver.GetGroup(ctx, c.Param("name"))
+	if err != nil {
+		return groupError(err)
+	}
+
+	group.Description = req.Description
+	if err := h.resolver.UpdateGroup(ctx, group); err != nil {
+		return groupError(err)
+	}
+
+	return c.JSON(http.StatusOK, newGroupResponse(*group))
+}
+
+func (h *Handler) DeleteGroup(c echo.Context) error {
+	ctx := c.Request().Context()
+	if err := h.resolver.DeleteGroup(ctx, c.Param("name")); err != nil {
+		return groupError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) ListGroupFQDNs(c echo.Context) error {
+	ctx := c.Request().Context()
+	fqdns, err := h.resolver.ListGroupMembers(ctx, c.Param("name"))
+	if err != nil {
+		return groupError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"group":         c.Param("name"),
+		"fqdns":         nonNil(fqdns),
+		"fqdns_unicode": unicodeFQDNs(fqdns),
+	})
+}
+
+func (h *Handler) AddGroupFQDN(c echo.Context) error {
+	fqdn, err := canonicalFQDN(c.Param("fqdn"))
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	if err := h.resolver.AddGroupMember(ctx, c.Param("name"), fqdn); err != nil {
+		return groupError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) RemoveGroupFQDN(c echo.Context) error {
+	fqdn, err := canonicalFQDN(c.Param("fqdn"))
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	err = h.resolver.RemoveGroupMember(ctx, c.Param("name"), fqdn)
+	if err != nil {
+		return groupError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) GetGroupIPs(c echo.Context) error {
+	ctx := c.Request().Context()
+	ips, err := h.resolver.GetIPsByGroup(ctx, c.Param("name"))
+	if err != nil {
+		return groupError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"group": c.Param("name"),
+		"ips":   nonNil(ips),
+	})
+}
+
+// nonNil нужен, чтобы пустой список сериализовался как [], а не null
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
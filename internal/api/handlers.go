@@ -1,34 +1,64 @@
 package api
 
 import (
+	"dns-resolver/internal/models"
+	"dns-resolver/internal/validator"
+	"errors"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
 )
 
 type AddFQDNRequest struct {
-	FQDN string `json:"fqdn" validate:"required"`
+	FQDN string `json:"fqdn" validate:"required,dnsname"`
+}
+
+// canonicalFQDN нормализует FQDN из запроса, чтобы "GitHub.com." и "github.com"
+// указывали на одни и те же записи
+func canonicalFQDN(raw string) (string, error) {
+	fqdn, err := validator.CanonicalFQDN(raw)
+	if err != nil {
+		return "", problemFromError(err)
+	}
+	return fqdn, nil
+}
+
+// unicodeFQDNs возвращает U-label формы имен в том же порядке, что и fqdns
+func unicodeFQDNs(fqdns []string) []string {
+	res := make([]string, len(fqdns))
+	for i, fqdn := range fqdns {
+		res[i] = validator.UnicodeFQDN(fqdn)
+	}
+	return res
 }
 
 func (h *Handler) AddFQDN(c echo.Context) error {
 	var req AddFQDNRequest
 	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid request format")
+		return malformedRequest(err)
 	}
+	setAuditTarget(c, req.FQDN)
 
 	if err := c.Validate(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return validationProblem(err)
+	}
+
+	fqdn, err := canonicalFQDN(req.FQDN)
+	if err != nil {
+		return err
 	}
+	setAuditTarget(c, fqdn)
 
 	ctx := c.Request().Context()
-	ips, err := h.resolver.Resolve(ctx, req.FQDN)
+	ips, err := h.resolver.Resolve(ctx, fqdn)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusServiceUnavailable, "DNS resolution failed")
+		return problemFromError(err)
 	}
 
 	return c.JSON(http.StatusCreated, map[string]interface{}{
-		"fqdn": req.FQDN,
-		"ips":  ips,
+		"fqdn":         fqdn,
+		"fqdn_unicode": validator.UnicodeFQDN(fqdn),
+		"ips":          nonNil(ips),
 	})
 }
 
@@ -38,32 +68,86 @@ func (h *Handler) GetFQDNsByIP(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "ip parameter is required")
 	}
 
+	q, err := parseLookupQuery(c)
+	if err != nil {
+		return pageQueryError(err)
+	}
+
 	ctx := c.Request().Context()
-	fqdns, err := h.resolver.GetFQDNsByIP(ctx, ip)
+	page, err := h.resolver.ListFQDNsByIP(ctx, ip, q)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "db error")
+		return problemFromError(err)
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"ip":    ip,
-		"fqdns": fqdns,
-	})
+	setPageHeaders(c, page)
+	res := map[string]interface{}{
+		"ip":            ip,
+		"fqdns":         page.Items,
+		"fqdns_unicode": unicodeFQDNs(page.Items),
+	}
+	if page.Next != nil {
+		res["next_cursor"] = page.Next.Encode()
+	}
+	return c.JSON(http.StatusOK, res)
 }
 
 func (h *Handler) GetIPsByFQDN(c echo.Context) error {
-	fqdn := c.QueryParam("fqdn")
-	if fqdn == "" {
+	if c.QueryParam("fqdn") == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "fqdn parameter is required")
 	}
 
+	fqdn, err := canonicalFQDN(c.QueryParam("fqdn"))
+	if err != nil {
+		return err
+	}
+
+	q, err := parseLookupQuery(c)
+	if err != nil {
+		return pageQueryError(err)
+	}
+
 	ctx := c.Request().Context()
-	ips, err := h.resolver.GetIPsByFQDN(ctx, fqdn)
+	version, err := h.resolver.GetRecordVersion(ctx, fqdn)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "db error")
+		return problemFromError(err)
+	}
+	if h.checkRecordCache(c, version) {
+		return c.NoContent(http.StatusNotModified)
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"fqdn": fqdn,
-		"ips":    ips,
-	})
+	page, err := h.resolver.ListIPsByFQDN(ctx, fqdn, q)
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	setPageHeaders(c, page)
+	res := map[string]interface{}{
+		"fqdn":         fqdn,
+		"fqdn_unicode": validator.UnicodeFQDN(fqdn),
+		"ips":          page.Items,
+	}
+	if page.Next != nil {
+		res["next_cursor"] = page.Next.Encode()
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// DeleteFQDN прекращает отслеживание FQDN и удаляет его записи
+func (h *Handler) DeleteFQDN(c echo.Context) error {
+	fqdn, err := canonicalFQDN(c.Param("fqdn"))
+	if err != nil {
+		return err
+	}
+	setAuditTarget(c, fqdn)
+
+	ctx := c.Request().Context()
+	err = h.resolver.Remove(ctx, fqdn)
+	if errors.Is(err, models.ErrNotFound) {
+		return newProblem(http.StatusNotFound, CodeNotFound, "fqdn not found", err)
+	}
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
 }
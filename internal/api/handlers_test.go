@@ -1,23 +1,63 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"dns-resolver/internal/auth"
 	dnsresolver "dns-resolver/internal/dns_resolver"
 	v "dns-resolver/internal/validator"
+	"dns-resolver/internal/logging"
 	"dns-resolver/internal/models"
+	"dns-resolver/internal/ratelimit"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	testAPIKey   = "dnsr_test"
+	otherAPIKey  = "dnsr_other_tenant"
+	viewerAPIKey = "dnsr_viewer"
+	tenantAdmin  = "dnsr_tenant_admin"
+	defaultAdmin = "dnsr_default_admin"
+	testAdminKey = "admin-secret"
 )
 
 // MockRepository заменяет реальный репозиторий
 type MockRepository struct {
 	models.Repository 
+
+	mu    sync.Mutex
+	audit []models.AuditEntry
+	// domains заменяет список отслеживаемых FQDN по умолчанию
+	domains []string
+	// batchLookups считает запросы GetIPsByFQDNs
+	batchLookups int
 }
 
 func (m *MockRepository) AddOrUpdate(ctx context.Context, fqdn, ip string) error {
@@ -25,9 +65,21 @@ func (m *MockRepository) AddOrUpdate(ctx context.Context, fqdn, ip string) error
 }
 
 func (m *MockRepository) GetFQDNsByIP(ctx context.Context, ip string) ([]string, error) {
-	if ip == "1.1.1.1" {
+	if ip == "1.1.1.1" && models.TenantID(ctx) == models.DefaultTenantID {
 		return []string{"example.com"}, nil
 	}
+	// Общий адрес CDN, за которым несколько доменов
+	if ip == "203.0.113.10" {
+		return []string{"c.cdn.example", "a.cdn.example", "b.cdn.example"}, nil
+	}
+	// Адрес, доменов за которым больше страницы по умолчанию
+	if ip == "203.0.113.20" {
+		fqdns := make([]string, 150)
+		for i := range fqdns {
+			fqdns[i] = fmt.Sprintf("site%03d.cdn.example", i)
+		}
+		return fqdns, nil
+	}
 	return nil, nil
 }
 
@@ -38,6 +90,240 @@ func (m *MockRepository) GetIPsByFQDN(ctx context.Context, fqdn string) ([]strin
 	return nil, nil
 }
 
+// recordsModified — время изменения набора записей example.com
+var recordsModified = time.Date(2025, 3, 1, 12, 0, 0, 500, time.UTC)
+
+func (m *MockRepository) GetRecordVersion(ctx context.Context, fqdn string) (models.RecordVersion, error) {
+	if fqdn == "example.com" {
+		return models.RecordVersion{Count: 1, UpdatedAt: &recordsModified}, nil
+	}
+	return models.RecordVersion{}, nil
+}
+
+func (m *MockRepository) GetAllFQDNs(ctx context.Context) ([]string, error) {
+	if m.domains != nil {
+		return m.domains, nil
+	}
+	return []string{"example.com", "example.org"}, nil
+}
+
+func (m *MockRepository) GetFQDNsByIPs(ctx context.Context, ips []string) (map[string][]string, error) {
+	res := map[string][]string{}
+	for _, ip := range ips {
+		if ip == "1.1.1.1" && models.TenantID(ctx) == models.DefaultTenantID {
+			res[ip] = []string{"example.com"}
+		}
+	}
+	return res, nil
+}
+
+func (m *MockRepository) GetIPsByFQDNs(ctx context.Context, fqdns []string) (map[string][]string, error) {
+	m.mu.Lock()
+	m.batchLookups++
+	m.mu.Unlock()
+	res := map[string][]string{}
+	for _, fqdn := range fqdns {
+		if fqdn == "example.com" {
+			res[fqdn] = []string{"1.1.1.1"}
+		}
+	}
+	return res, nil
+}
+
+// pageOf режет отсортированный по имени список так же, как keyset-запрос в БД
+func pageOf(items []string, q models.PageQuery) models.Page {
+	items = slices.Clone(items)
+	slices.Sort(items)
+	if q.Desc {
+		slices.Reverse(items)
+	}
+
+	page := models.Page{Items: []string{}, Total: int64(len(items))}
+	for _, item := range items {
+		if q.After != nil && (q.Desc && item >= q.After.Name || !q.Desc && item <= q.After.Name) {
+			continue
+		}
+		if q.Limit > 0 && len(page.Items) == q.Limit {
+			page.Next = &models.Cursor{Sort: q.Sort, Desc: q.Desc, Name: page.Items[len(page.Items)-1]}
+			break
+		}
+		page.Items = append(page.Items, item)
+	}
+	return page
+}
+
+func (m *MockRepository) ListFQDNsByIP(ctx context.Context, ip string, q models.PageQuery) (models.Page, error) {
+	fqdns, err := m.GetFQDNsByIP(ctx, ip)
+	return pageOf(fqdns, q), err
+}
+
+func (m *MockRepository) ListIPsByFQDN(ctx context.Context, fqdn string, q models.PageQuery) (models.Page, error) {
+	ips, err := m.GetIPsByFQDN(ctx, fqdn)
+	return pageOf(ips, q), err
+}
+
+func (m *MockRepository) ListFQDNs(ctx context.Context, q models.PageQuery) (models.Page, error) {
+	fqdns, err := m.GetAllFQDNs(ctx)
+	return pageOf(fqdns, q), err
+}
+
+func (m *MockRepository) SearchFQDNs(ctx context.Context, search models.DomainQuery, q models.PageQuery) (models.Page, error) {
+	fqdns, err := m.GetAllFQDNs(ctx)
+	var res []string
+	for _, fqdn := range fqdns {
+		if matchesQuery(search, fqdn) {
+			res = append(res, fqdn)
+		}
+	}
+	return pageOf(res, q), err
+}
+
+// matchesQuery применяет запрос к имени в памяти так же, как поиск в БД
+func matchesQuery(q models.DomainQuery, fqdn string) bool {
+	if q.Suffix != "" && fqdn != q.Suffix && !strings.HasSuffix(fqdn, "."+q.Suffix) {
+		return false
+	}
+	if q.Pattern == "" {
+		return true
+	}
+
+	switch q.Match {
+	case models.MatchGlob, models.MatchRegex:
+		// Как ~* в Postgres: без учета регистра
+		re, err := regexp.Compile("(?i)" + q.Expr())
+		return err == nil && re.MatchString(fqdn)
+	default:
+		return strings.Contains(fqdn, strings.ToLower(q.Pattern))
+	}
+}
+
+func (m *MockRepository) DeleteRecord(ctx context.Context, fqdn, ip string) error {
+	return nil
+}
+
+func (m *MockRepository) CreateWebhook(ctx context.Context, hook *models.Webhook) error {
+	hook.ID = 1
+	return nil
+}
+
+func (m *MockRepository) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	return []models.Webhook{
+		{ID: 1, URL: "https://hooks.example.com", Secret: "secret", FQDNPattern: "*", EventTypes: "ip_added"},
+	}, nil
+}
+
+func (m *MockRepository) DeleteWebhook(ctx context.Context, id uint) error {
+	if id != 1 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (m *MockRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (m *MockRepository) AppendEvent(ctx context.Context, ev *models.Event) error {
+	return nil
+}
+
+func (m *MockRepository) ListEventsSince(ctx context.Context, afterID uint, limit int) ([]models.Event, error) {
+	log := []models.Event{
+		{ID: 1, TenantID: 1, Type: models.EventIPAdded, FQDN: "example.com", IP: "1.1.1.1"},
+		{ID: 2, TenantID: 1, Type: models.EventIPAdded, FQDN: "example.org", IP: "2.2.2.2"},
+		{ID: 3, TenantID: 1, Type: models.EventIPRemoved, FQDN: "example.com", IP: "1.1.1.1"},
+	}
+
+	var res []models.Event
+	for _, ev := range log {
+		if ev.ID > afterID && len(res) < limit {
+			res = append(res, ev)
+		}
+	}
+	return res, nil
+}
+
+func (m *MockRepository) CreateGroup(ctx context.Context, group *models.Group) error {
+	if group.Name == "payments-egress" {
+		return models.ErrConflict
+	}
+	group.ID = 2
+	return nil
+}
+
+func (m *MockRepository) GetIPsByGroup(ctx context.Context, group string) ([]string, error) {
+	if group != "payments-egress" {
+		return nil, models.ErrNotFound
+	}
+	return []string{"1.1.1.1", "2.2.2.2"}, nil
+}
+
+func (m *MockRepository) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	switch hash {
+	case models.HashAPIKey(testAPIKey):
+		return &models.APIKey{ID: 1, TenantID: models.DefaultTenantID, Role: models.RoleEditor}, nil
+	case models.HashAPIKey(otherAPIKey):
+		return &models.APIKey{ID: 2, TenantID: 2, Role: models.RoleEditor}, nil
+	case models.HashAPIKey(viewerAPIKey):
+		return &models.APIKey{ID: 3, TenantID: models.DefaultTenantID, Role: models.RoleViewer}, nil
+	case models.HashAPIKey(tenantAdmin):
+		return &models.APIKey{ID: 4, TenantID: 2, Role: models.RoleAdmin}, nil
+	case models.HashAPIKey(defaultAdmin):
+		return &models.APIKey{ID: 5, TenantID: models.DefaultTenantID, Role: models.RoleAdmin}, nil
+	}
+	return nil, models.ErrNotFound
+}
+
+func (m *MockRepository) CreateTenant(ctx context.Context, tenant *models.Tenant) error {
+	tenant.ID = 3
+	return nil
+}
+
+// DeleteTenant: у арендатора 4 есть записи аудита
+func (m *MockRepository) DeleteTenant(ctx context.Context, id uint) error {
+	switch id {
+	case 3:
+		return nil
+	case 4:
+		return fmt.Errorf("%w: tenant has audit entries", models.ErrConflict)
+	}
+	return models.ErrNotFound
+}
+
+func (m *MockRepository) AppendAudit(ctx context.Context, entry *models.AuditEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry.ID = uint(len(m.audit) + 1)
+	entry.TenantID = models.TenantID(ctx)
+	m.audit = append(m.audit, *entry)
+	return nil
+}
+
+func (m *MockRepository) ListAudit(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var res []models.AuditEntry
+	for i := len(m.audit) - 1; i >= 0; i-- {
+		e := m.audit[i]
+		if e.TenantID == models.TenantID(ctx) && (filter.Action == "" || e.Action == filter.Action) {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+func (m *MockRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	key.ID = 10
+	return nil
+}
+
+// specReporter проваливает тест, если запрос или ответ разошлись с docs/openapi.yaml
+func specReporter(t *testing.T) func(error) {
+	return func(err error) {
+		t.Errorf("OpenAPI spec mismatch: %v", err)
+	}
+}
+
 func TestAPIHandlers(t *testing.T) {
 	//Создаем мок репозитория
 	mockRepo := &MockRepository{}
@@ -49,13 +335,16 @@ func TestAPIHandlers(t *testing.T) {
 	e := echo.New()
 	e.Validator = v.New()
 
-	h := NewHandler(resolver)
+	h := NewHandler(resolver, WithAdminKey(testAdminKey), WithResponseValidation(specReporter(t)))
 	h.RegisterRoutes(e)
 
 	t.Run("AddFQDN success", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodPost, "/api/fqdns",
 			strings.NewReader(`{"fqdn":"example.com"}`))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		// Обработчик вызывается без middleware: арендатора задаем сами
+		req = req.WithContext(models.WithTenant(req.Context(), models.DefaultTenantID))
 		rec := httptest.NewRecorder()
 
 		c := e.NewContext(req, rec)
@@ -68,6 +357,7 @@ func TestAPIHandlers(t *testing.T) {
 
 	t.Run("AddFQDN - wrong method GET", func(t *testing.T) {
     req := httptest.NewRequest(http.MethodGet, "/api/fqdns", nil)
+    req.Header.Set(APIKeyHeader, testAPIKey)
     rec := httptest.NewRecorder()
     
     e.ServeHTTP(rec, req)
@@ -75,8 +365,143 @@ func TestAPIHandlers(t *testing.T) {
     assert.Equal(t, http.StatusBadRequest, rec.Code)
 	})
 
+	t.Run("AddFQDN rejects invalid names", func(t *testing.T) {
+		for _, fqdn := range []string{"not a domain", "1.1.1.1", "-bad.com"} {
+			req := httptest.NewRequest(http.MethodPost, "/api/fqdns", strings.NewReader(`{"fqdn":"`+fqdn+`"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(APIKeyHeader, testAPIKey)
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code, fqdn)
+			assert.Equal(t, MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+
+			var problem Problem
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+			assert.Equal(t, CodeFQDNInvalid, problem.Code, fqdn)
+			assert.Equal(t, "urn:dns-resolver:problem:validation.fqdn_invalid", problem.Type)
+			assert.Equal(t, http.StatusBadRequest, problem.Status)
+			assert.Equal(t, "/api/fqdns", problem.Instance)
+		}
+	})
+
+	t.Run("GetIPsByFQDN canonicalizes the name", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/ips?fqdn=Example.COM.", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"fqdn":"example.com","fqdn_unicode":"example.com","ips":["1.1.1.1"]}`, rec.Body.String())
+	})
+
+	t.Run("GetIPsByFQDN accepts Unicode names", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/ips?fqdn="+url.QueryEscape("Пример.рф"), nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"fqdn":"xn--e1afmkfd.xn--p1ai"`)
+		assert.Contains(t, rec.Body.String(), `"fqdn_unicode":"пример.рф"`)
+	})
+
+	t.Run("Batch lookups", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/fqdns:batchGet", strings.NewReader(`{"ips":["1.1.1.1","::ffff:1.1.1.1","9.9.9.9"]}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, viewerAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"fqdns":{"1.1.1.1":["example.com"],"::ffff:1.1.1.1":["example.com"],"9.9.9.9":[]}}`, rec.Body.String())
+
+		req = httptest.NewRequest(http.MethodPost, "/api/ips:batchGet", strings.NewReader(`{"fqdns":["Example.com.","unknown.org"]}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, viewerAPIKey)
+		rec = httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"ips":{"Example.com.":["1.1.1.1"],"unknown.org":[]}}`, rec.Body.String())
+
+		for _, body := range []string{`{"ips":[]}`, `{"ips":["not-an-ip"]}`} {
+			req = httptest.NewRequest(http.MethodPost, "/api/fqdns:batchGet", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(APIKeyHeader, viewerAPIKey)
+			rec = httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+		}
+	})
+
+	t.Run("GetFQDNsByIP is paginated", func(t *testing.T) {
+		get := func(query string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/api/fqdns?ip=203.0.113.10&"+query, nil)
+			req.Header.Set(APIKeyHeader, viewerAPIKey)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			return rec
+		}
+
+		rec := get("limit=2")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "3", rec.Header().Get("X-Total-Count"))
+
+		var first struct {
+			FQDNs      []string `json:"fqdns"`
+			NextCursor string   `json:"next_cursor"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &first))
+		assert.Equal(t, []string{"a.cdn.example", "b.cdn.example"}, first.FQDNs)
+		require.NotEmpty(t, first.NextCursor)
+		assert.Equal(t, `</api/fqdns?cursor=`+first.NextCursor+`&ip=203.0.113.10&limit=2>; rel="next"`, rec.Header().Get("Link"))
+
+		rec = get("limit=2&cursor=" + first.NextCursor)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"fqdns":["c.cdn.example"]`)
+		assert.NotContains(t, rec.Body.String(), "next_cursor")
+
+		rec = get("sort=-name")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"fqdns":["c.cdn.example","b.cdn.example","a.cdn.example"]`)
+
+		// Курсор, полученный при другом порядке, и мусор вместо курсора отклоняются
+		for _, query := range []string{"sort=-name&cursor=" + first.NextCursor, "cursor=garbage", "sort=ip", "limit=1001"} {
+			rec = get(query)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		}
+	})
+
+	t.Run("GetFQDNsByIP without limit returns every match", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/fqdns?ip=203.0.113.20", nil)
+		req.Header.Set(APIKeyHeader, viewerAPIKey)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		var res struct {
+			FQDNs      []string `json:"fqdns"`
+			NextCursor string   `json:"next_cursor"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		assert.Len(t, res.FQDNs, 150)
+		assert.Empty(t, res.NextCursor)
+		assert.Empty(t, rec.Header().Get("Link"))
+		assert.Equal(t, "150", rec.Header().Get("X-Total-Count"))
+	})
+
 	t.Run("GetFQDNsByIP success", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/api/fqdns?ip=1.1.1.1", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		req = req.WithContext(models.WithTenant(req.Context(), models.DefaultTenantID))
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 
@@ -84,12 +509,14 @@ func TestAPIHandlers(t *testing.T) {
 
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.JSONEq(t, `{"ip":"1.1.1.1","fqdns":["example.com"]}`, rec.Body.String())
+		assert.JSONEq(t, `{"ip":"1.1.1.1","fqdns":["example.com"],"fqdns_unicode":["example.com"]}`, rec.Body.String())
 	})
 	
 
 	t.Run("GetIPsByFQDN success", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/api/ips?fqdn=example.com", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		req = req.WithContext(models.WithTenant(req.Context(), models.DefaultTenantID))
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 
@@ -97,7 +524,1159 @@ func TestAPIHandlers(t *testing.T) {
 
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.JSONEq(t, `{"fqdn":"example.com", "ips":["1.1.1.1"]}`, rec.Body.String())
+		assert.JSONEq(t, `{"fqdn":"example.com","fqdn_unicode":"example.com","ips":["1.1.1.1"]}`, rec.Body.String())
+	})
+
+	t.Run("DeleteFQDN", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/api/fqdns/Example.COM.", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+
+		req = httptest.NewRequest(http.MethodDelete, "/api/fqdns/unknown.example", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec = httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"resource.not_found"`)
+
+		req = httptest.NewRequest(http.MethodDelete, "/api/fqdns/example.com", nil)
+		req.Header.Set(APIKeyHeader, viewerAPIKey)
+		rec = httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("AddWebhook success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/webhooks",
+			strings.NewReader(`{"url":"https://hooks.example.com","fqdn_pattern":"*.example.com","events":["ip_added","nxdomain"]}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"events":["ip_added","nxdomain"]`)
+		assert.Contains(t, rec.Body.String(), `"secret":"`)
+	})
+
+	t.Run("AddWebhook unknown event", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/webhooks",
+			strings.NewReader(`{"url":"https://hooks.example.com","events":["ip_changed"]}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("AddWebhook rejects bad pattern and internal targets", func(t *testing.T) {
+		for _, body := range []string{
+			`{"url":"https://hooks.example.com","fqdn_pattern":"[a-"}`,
+			`{"url":"ftp://hooks.example.com"}`,
+			`{"url":"http://169.254.169.254/latest/meta-data"}`,
+			`{"url":"http://localhost:8080/hook"}`,
+			`{"url":"http://[::1]/hook"}`,
+		} {
+			req := httptest.NewRequest(http.MethodPost, "/api/webhooks", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(APIKeyHeader, testAPIKey)
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+		}
+	})
+
+	t.Run("ListWebhooks hides secret", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/webhooks", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"url":"https://hooks.example.com"`)
+		assert.NotContains(t, rec.Body.String(), `"secret"`)
+	})
+
+	t.Run("DeleteWebhook not found", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/api/webhooks/42", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("StreamEvents resumes from Last-Event-ID", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/events?fqdn=example.com", nil).WithContext(ctx)
+		req.Header.Set("Last-Event-ID", "1")
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/event-stream", rec.Header().Get(echo.HeaderContentType))
+		body := rec.Body.String()
+		assert.Contains(t, body, "id: 3\nevent: ip_removed\n")
+		assert.NotContains(t, body, "id: 1\n")
+		assert.NotContains(t, body, "example.org")
+	})
+
+	t.Run("StreamEvents invalid type", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/events?type=ip_changed", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("ExportFirewall nftables with ETag", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/export/firewall/nftables?fqdn=example.com", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "add element inet dns_resolver dns_allow_v4 { 1.1.1.1 }")
+		etag := rec.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+
+		req = httptest.NewRequest(http.MethodGet, "/api/export/firewall/nftables?fqdn=example.com", nil)
+		req.Header.Set("If-None-Match", etag)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec = httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("ExportFirewall looks up all FQDNs at once", func(t *testing.T) {
+		before := mockRepo.batchLookups
+		req := httptest.NewRequest(http.MethodGet, "/api/export/firewall/ipset?fqdn=example.com,example.org&fqdn=Example.NET", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "1.1.1.1")
+		assert.Equal(t, before+1, mockRepo.batchLookups)
+	})
+
+	t.Run("ExportFirewall unknown format", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/export/firewall/pf?fqdn=example.com", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("ExportKubernetes cilium", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet,
+			"/api/export/kubernetes/cilium?fqdn=example.com&namespace=payments&selector=app=payments&port=443", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/yaml", rec.Header().Get(echo.HeaderContentType))
+		assert.Contains(t, rec.Body.String(), "kind: CiliumNetworkPolicy")
+		assert.Contains(t, rec.Body.String(), "- 1.1.1.1/32")
+		assert.NotEmpty(t, rec.Header().Get("ETag"))
+	})
+
+	t.Run("ExportKubernetes invalid selector", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/export/kubernetes/networkpolicy?fqdn=example.com&selector=app", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("AddGroup", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/groups",
+			strings.NewReader(`{"name":"team-search","description":"search backends"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"name":"team-search"`)
+	})
+
+	t.Run("AddGroup conflict and invalid name", func(t *testing.T) {
+		for body, code := range map[string]int{
+			`{"name":"payments-egress"}`: http.StatusConflict,
+			`{"name":"Payments Egress"}`: http.StatusBadRequest,
+		} {
+			req := httptest.NewRequest(http.MethodPost, "/api/groups", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(APIKeyHeader, testAPIKey)
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, code, rec.Code, body)
+		}
+	})
+
+	t.Run("GetGroupIPs", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/groups/payments-egress/ips", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"group":"payments-egress","ips":["1.1.1.1","2.2.2.2"]}`, rec.Body.String())
+	})
+
+	t.Run("GetGroupIPs unknown group", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/groups/unknown/ips", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("ExportFirewall by group", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/export/firewall/ipset?group=payments-egress&fqdn=example.com", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "add dns_allow_v4 1.1.1.1\nadd dns_allow_v4 2.2.2.2\n")
+	})
+
+	t.Run("Missing or invalid API key", func(t *testing.T) {
+		for _, key := range []string{"", "dnsr_unknown"} {
+			req := httptest.NewRequest(http.MethodGet, "/api/fqdns?ip=1.1.1.1", nil)
+			if key != "" {
+				req.Header.Set(APIKeyHeader, key)
+			}
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"code":"auth.unauthenticated"`)
+		}
+	})
+
+	t.Run("Bearer API key and tenant isolation", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/fqdns?ip=1.1.1.1", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+otherAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		// Данные арендатора по умолчанию не видны другому арендатору
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "example.com")
+	})
+
+	t.Run("Admin routes require admin key", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/tenants", strings.NewReader(`{"name":"team-search"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		req = httptest.NewRequest(http.MethodPost, "/api/admin/tenants", strings.NewReader(`{"name":"team-search"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, testAdminKey)
+		rec = httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"name":"team-search"`)
+		assert.Contains(t, rec.Body.String(), `"key":"dnsr_`)
+	})
+
+	t.Run("Delete tenant", func(t *testing.T) {
+		for path, code := range map[string]int{
+			"/api/admin/tenants/3": http.StatusNoContent,
+			"/api/admin/tenants/4": http.StatusConflict,
+			"/api/admin/tenants/1": http.StatusConflict,
+			"/api/admin/tenants/9": http.StatusNotFound,
+			"/api/admin/tenants/x": http.StatusBadRequest,
+		} {
+			req := httptest.NewRequest(http.MethodDelete, path, nil)
+			req.Header.Set(APIKeyHeader, testAdminKey)
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, code, rec.Code, path)
+		}
+	})
+
+	t.Run("Viewer key is read-only", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/fqdns?ip=1.1.1.1", nil)
+		req.Header.Set(APIKeyHeader, viewerAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		req = httptest.NewRequest(http.MethodPost, "/api/fqdns", strings.NewReader(`{"fqdn":"example.org"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, viewerAPIKey)
+		rec = httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Keys are managed by tenant admins", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/keys", strings.NewReader(`{"name":"ci","role":"viewer"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		req = httptest.NewRequest(http.MethodPost, "/api/keys", strings.NewReader(`{"name":"ci","role":"viewer"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, tenantAdmin)
+		rec = httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"role":"viewer"`)
+
+		req = httptest.NewRequest(http.MethodPost, "/api/keys", strings.NewReader(`{"name":"ci","role":"root"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, tenantAdmin)
+		rec = httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Mutations are audited", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/groups", strings.NewReader(`{"name":"Bad Name"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderXRequestID, "req-42")
+		req.Header.Set(APIKeyHeader, tenantAdmin)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+
+		req = httptest.NewRequest(http.MethodGet, "/api/audit?action=group.create", nil)
+		req.Header.Set(APIKeyHeader, tenantAdmin)
+		rec = httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		var body struct {
+			Entries []models.AuditEntry `json:"entries"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		require.Len(t, body.Entries, 1)
+		entry := body.Entries[0]
+		assert.Equal(t, "key:4", entry.Actor)
+		assert.Equal(t, "Bad Name", entry.Target)
+		assert.Equal(t, "req-42", entry.RequestID)
+		assert.Equal(t, models.AuditFailure, entry.Result)
+		assert.Equal(t, http.StatusBadRequest, entry.Status)
+
+		// Журнал доступен только администраторам арендатора
+		req = httptest.NewRequest(http.MethodGet, "/api/audit", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec = httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Rejected mutations are audited", func(t *testing.T) {
+		do := func(method, path, key string) {
+			req := httptest.NewRequest(method, path, strings.NewReader(`{"name":"ops"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(echo.HeaderXForwardedFor, "203.0.113.7")
+			if key != "" {
+				req.Header.Set(APIKeyHeader, key)
+			}
+			e.ServeHTTP(httptest.NewRecorder(), req)
+		}
+		do(http.MethodDelete, "/api/fqdns/example.com", viewerAPIKey)
+		do(http.MethodPost, "/api/admin/tenants", tenantAdmin)
+
+		// Анонимные попытки не пишутся в журнал ни одного арендатора
+		before := len(mockRepo.audit)
+		do(http.MethodPost, "/api/groups", "")
+		assert.Len(t, mockRepo.audit, before)
+
+		entries := mockRepo.audit[len(mockRepo.audit)-2:]
+		assert.Equal(t, "fqdn.delete", entries[0].Action)
+		assert.Equal(t, "key:3", entries[0].Actor)
+		assert.Equal(t, "example.com", entries[0].Target)
+		assert.Equal(t, http.StatusForbidden, entries[0].Status)
+
+		assert.Equal(t, "tenant.create", entries[1].Action)
+		assert.Equal(t, uint(2), entries[1].TenantID)
+		assert.Equal(t, http.StatusForbidden, entries[1].Status)
+
+		for _, entry := range entries {
+			assert.Equal(t, models.AuditFailure, entry.Result)
+			// Без доверенных прокси X-Forwarded-For не учитывается
+			assert.Equal(t, "192.0.2.1", entry.SourceIP)
+		}
+	})
+
+	t.Run("Tenant admin cannot manage tenants", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/tenants", nil)
+		req.Header.Set(APIKeyHeader, tenantAdmin)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+}
+
+func signTestJWT(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	enc := base64.RawURLEncoding
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	input := enc.EncodeToString(header) + "." + enc.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	return input + "." + enc.EncodeToString(sig)
+}
+
+func TestConditionalGet(t *testing.T) {
+	e := echo.New()
+	e.Validator = v.New()
+	NewHandler(dnsresolver.NewResolver(&MockRepository{}),
+		WithRecordTTL(5*time.Minute), WithResponseValidation(specReporter(t))).RegisterRoutes(e)
+
+	get := func(path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header = header
+		req.Header.Set(APIKeyHeader, viewerAPIKey)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/api/ips?fqdn=example.com", http.Header{})
+	require.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, "Sat, 01 Mar 2025 12:00:00 GMT", rec.Header().Get("Last-Modified"))
+	assert.Equal(t, "private, max-age=300", rec.Header().Get("Cache-Control"))
+
+	t.Run("If-None-Match", func(t *testing.T) {
+		rec := get("/api/ips?fqdn=example.com", http.Header{"If-None-Match": {`"stale", ` + etag}})
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Empty(t, rec.Body.String())
+		assert.Equal(t, etag, rec.Header().Get("ETag"))
+
+		// Другая страница — другой ответ и другой ETag
+		rec = get("/api/ips?fqdn=example.com&sort=-name", http.Header{"If-None-Match": {etag}})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+	})
+
+	t.Run("If-Modified-Since", func(t *testing.T) {
+		rec := get("/api/ips?fqdn=example.com", http.Header{"If-Modified-Since": {"Sat, 01 Mar 2025 12:00:00 GMT"}})
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+
+		rec = get("/api/ips?fqdn=example.com", http.Header{"If-Modified-Since": {"Sat, 01 Mar 2025 11:59:59 GMT"}})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		// При If-None-Match дата не учитывается
+		rec = get("/api/ips?fqdn=example.com", http.Header{
+			"If-None-Match":     {`"stale"`},
+			"If-Modified-Since": {"Sat, 01 Mar 2025 12:00:00 GMT"},
+		})
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Unknown FQDN has no Last-Modified", func(t *testing.T) {
+		rec := get("/api/ips?fqdn=unknown.example", http.Header{"If-Modified-Since": {"Sat, 01 Mar 2025 12:00:00 GMT"}})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("Last-Modified"))
+		assert.NotEmpty(t, rec.Header().Get("ETag"))
+	})
+}
+
+func TestUpdaterControl(t *testing.T) {
+	e := echo.New()
+	e.Validator = v.New()
+	repo := &MockRepository{}
+	resolver := dnsresolver.NewResolver(repo)
+	// Run не запущен: проверяется только управление и отчет о состоянии
+	updater := dnsresolver.NewUpdater(resolver, 5*time.Minute)
+	NewHandler(resolver, WithAdminKey(testAdminKey), WithUpdater(updater),
+		WithResponseValidation(specReporter(t))).RegisterRoutes(e)
+
+	do := func(method, path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, key)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	status := func(rec *httptest.ResponseRecorder) UpdaterStatusResponse {
+		var res UpdaterStatusResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		return res
+	}
+
+	t.Run("Status", func(t *testing.T) {
+		rec := do(http.MethodGet, "/api/admin/updater", testAdminKey, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		res := status(rec)
+		assert.Equal(t, "5m0s", res.Interval)
+		assert.Equal(t, float64(300), res.IntervalSeconds)
+		assert.False(t, res.Paused)
+		// Проходы выполняет только реплика, на которой запущен Run
+		assert.False(t, res.Active)
+		assert.Nil(t, res.NextRun)
+		assert.Nil(t, res.LastCycle)
+	})
+
+	t.Run("Pause and resume", func(t *testing.T) {
+		rec := do(http.MethodPost, "/api/admin/updater/pause", testAdminKey, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, status(rec).Paused)
+		assert.Nil(t, status(rec).NextRun)
+		assert.True(t, updater.Status().Paused)
+
+		rec = do(http.MethodPost, "/api/admin/updater/resume", testAdminKey, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.False(t, status(rec).Paused)
+	})
+
+	t.Run("Interval", func(t *testing.T) {
+		rec := do(http.MethodPut, "/api/admin/updater/interval", testAdminKey, `{"interval":"30s"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "30s", status(rec).Interval)
+		assert.Equal(t, 30*time.Second, updater.Interval())
+
+		for _, body := range []string{`{"interval":"soon"}`, `{"interval":"10ms"}`, `{}`} {
+			rec = do(http.MethodPut, "/api/admin/updater/interval", testAdminKey, body)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+		}
+
+		// Cache-Control следует текущему интервалу
+		rec = do(http.MethodGet, "/api/ips?fqdn=example.com", viewerAPIKey, "")
+		assert.Equal(t, "private, max-age=30", rec.Header().Get("Cache-Control"))
+	})
+
+	t.Run("Refresh all", func(t *testing.T) {
+		rec := do(http.MethodPost, "/api/admin/updater/refresh", testAdminKey, "")
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+	})
+
+	t.Run("Only the service admin controls the updater", func(t *testing.T) {
+		// Ключ администратора арендатора по умолчанию — не служебный ключ
+		for _, key := range []string{tenantAdmin, defaultAdmin, testAPIKey} {
+			rec := do(http.MethodPost, "/api/admin/updater/pause", key, "")
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+		}
+		assert.False(t, updater.Status().Paused)
+	})
+
+	t.Run("Refresh one FQDN", func(t *testing.T) {
+		rec := do(http.MethodPost, "/api/fqdns/unknown.example/refresh", testAdminKey, "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = do(http.MethodPost, "/api/fqdns/example.com/refresh", testAPIKey, "")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		var actions []string
+		for _, entry := range repo.audit {
+			actions = append(actions, entry.Action)
+		}
+		assert.Contains(t, actions, "fqdn.refresh")
+		assert.Contains(t, actions, "updater.interval")
+	})
+}
+
+func TestJWTAuthentication(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	enc := base64.RawURLEncoding
+	jwks, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"n":   enc.EncodeToString(key.N.Bytes()),
+			"e":   enc.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+	require.NoError(t, err)
+
+	verifier, err := auth.NewVerifier(jwks, "", "dns-resolver")
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Validator = v.New()
+	NewHandler(dnsresolver.NewResolver(&MockRepository{}), WithJWT(verifier), WithResponseValidation(specReporter(t))).RegisterRoutes(e)
+
+	request := func(claims map[string]interface{}) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/me", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+signTestJWT(t, key, claims))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request(map[string]interface{}{
+		"sub": "alice", "aud": "dns-resolver", "exp": time.Now().Add(time.Hour).Unix(),
+		"tenant_id": 2, "role": "viewer",
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"tenant_id":2,"subject":"alice","role":"viewer"}`, rec.Body.String())
+
+	rec = request(map[string]interface{}{
+		"sub": "alice", "aud": "dns-resolver", "exp": time.Now().Add(time.Hour).Unix(),
+		"tenant_id": 2, "role": "superuser",
+	})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = request(map[string]interface{}{
+		"sub": "alice", "aud": "dns-resolver", "exp": time.Now().Add(-time.Hour).Unix(),
+		"tenant_id": 2, "role": "viewer",
+	})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+// memLimitStore — общие бюджеты в памяти
+type memLimitStore struct {
+	config *ratelimit.Config
+}
+
+func (s *memLimitStore) GetRateLimits(ctx context.Context) (ratelimit.Config, bool, error) {
+	if s.config == nil {
+		return ratelimit.Config{}, false, nil
+	}
+	return *s.config, true, nil
+}
+
+func (s *memLimitStore) SaveRateLimits(ctx context.Context, config ratelimit.Config) error {
+	s.config = &config
+	return nil
+}
+
+func TestRateLimiting(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Config{
+		Read:  ratelimit.Budget{Rate: 100, Burst: 100},
+		Write: ratelimit.Budget{Rate: 0.01, Burst: 1},
+	})
+	store := &memLimitStore{}
+
+	e := echo.New()
+	e.Validator = v.New()
+	NewHandler(dnsresolver.NewResolver(&MockRepository{}), WithAdminKey(testAdminKey), WithRateLimiter(limiter),
+		WithRateLimitStore(store), WithResponseValidation(specReporter(t))).RegisterRoutes(e)
+
+	post := func(key, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/groups", strings.NewReader(`{"name":"web"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, key)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(testAPIKey, "10.0.0.1:1000")
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", rec.Header().Get("X-RateLimit-Remaining"))
+
+	// Тот же ключ с другого адреса упирается в лимит ключа
+	rec = post(testAPIKey, "10.0.0.2:1000")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "100", rec.Header().Get(echo.HeaderRetryAfter))
+
+	// Чтение расходует отдельный бюджет
+	req := httptest.NewRequest(http.MethodGet, "/api/webhooks", nil)
+	req.Header.Set(APIKeyHeader, testAPIKey)
+	req.RemoteAddr = "10.0.0.1:1000"
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Лимит по адресу действует и без валидного ключа
+	assert.Equal(t, http.StatusTooManyRequests, post("dnsr_unknown", "10.0.0.1:1000").Code)
+
+	// Подмена X-Forwarded-For не дает нового бюджета по адресу
+	spoofed := func(xff string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/groups", strings.NewReader(`{"name":"web"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, "dnsr_unknown")
+		req.Header.Set(echo.HeaderXForwardedFor, xff)
+		req.Header.Set(echo.HeaderXRealIP, xff)
+		req.RemoteAddr = "10.0.0.1:1000"
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusTooManyRequests, spoofed("203.0.113.7"))
+	assert.Equal(t, http.StatusTooManyRequests, spoofed("203.0.113.8"))
+
+	// Администратор меняет бюджеты на лету
+	req = httptest.NewRequest(http.MethodPut, "/api/admin/ratelimits",
+		strings.NewReader(`{"read":{"rate":100,"burst":100},"write":{"rate":100,"burst":100}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(APIKeyHeader, testAdminKey)
+	req.RemoteAddr = "10.0.0.9:1000"
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	assert.Equal(t, http.StatusCreated, post(testAPIKey, "10.0.0.1:1000").Code)
+	// Бюджеты сохранены для остальных реплик
+	require.NotNil(t, store.config)
+	assert.Equal(t, 100.0, store.config.Write.Rate)
+}
+
+func TestIPExtractor(t *testing.T) {
+	_, err := IPExtractor("10.0.0.0/33")
+	assert.Error(t, err)
+
+	extract, err := IPExtractor("10.0.0.0/8, fd00::/8")
+	require.NoError(t, err)
+	ip := func(remoteAddr string) string {
+		req := httptest.NewRequest(http.MethodGet, "/api/ips", nil)
+		req.Header.Set(echo.HeaderXForwardedFor, "203.0.113.7")
+		req.RemoteAddr = remoteAddr
+		return extract(req)
+	}
+	assert.Equal(t, "203.0.113.7", ip("10.1.2.3:1000"))
+	// Заголовок от недоверенного адреса, в том числе loopback, игнорируется
+	assert.Equal(t, "198.51.100.1", ip("198.51.100.1:1000"))
+	assert.Equal(t, "127.0.0.1", ip("127.0.0.1:1000"))
+}
+
+func TestProblemFromError(t *testing.T) {
+	for _, tc := range []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{&dnsresolver.LookupError{FQDN: "gone.example", Reason: dnsresolver.ErrNXDomain, Err: errors.New("no such host")}, http.StatusUnprocessableEntity, CodeNXDomain},
+		{&dnsresolver.LookupError{FQDN: "slow.example", Reason: dnsresolver.ErrTimeout, Err: errors.New("i/o timeout")}, http.StatusGatewayTimeout, CodeDNSTimeout},
+		{&dnsresolver.LookupError{FQDN: "bad.example", Reason: dnsresolver.ErrLookupFailed, Err: errors.New("server misbehaving")}, http.StatusBadGateway, CodeDNSLookupFailed},
+		{fmt.Errorf("%w: connection refused", models.ErrUnavailable), http.StatusServiceUnavailable, CodeStorageUnavailable},
+		{models.ErrNotFound, http.StatusNotFound, CodeNotFound},
+		{fmt.Errorf("%w: canceling statement due to statement timeout", models.ErrSearchTimeout), http.StatusUnprocessableEntity, CodeSearchTimeout},
+		{errors.New("syntax error"), http.StatusInternalServerError, CodeStorageError},
+	} {
+		he := problemFromError(tc.err)
+		assert.Equal(t, tc.status, he.Code, tc.err.Error())
+		assert.Equal(t, tc.code, he.Message.(problemDetail).Code, tc.err.Error())
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	logger, err := logging.New(&logs, slog.LevelInfo, logging.FormatJSON)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Validator = v.New()
+	e.Use(RequestID(), RequestLogger(logger))
+	NewHandler(dnsresolver.NewResolver(&MockRepository{}), WithResponseValidation(specReporter(t))).RegisterRoutes(e)
+
+	t.Run("Generated when missing", func(t *testing.T) {
+		logs.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/api/ips?fqdn=example.com", nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+		id := rec.Header().Get(echo.HeaderXRequestID)
+		require.NotEmpty(t, id)
+
+		var problem Problem
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+		assert.Equal(t, id, problem.RequestID)
+
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+		assert.Equal(t, id, entry[logging.RequestIDKey])
+		assert.Equal(t, float64(http.StatusUnauthorized), entry["status"])
+	})
+
+	t.Run("Client ID is echoed and logged", func(t *testing.T) {
+		logs.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/api/ips?fqdn=example.com", nil)
+		req.Header.Set(echo.HeaderXRequestID, "req-42")
+		req.Header.Set(APIKeyHeader, viewerAPIKey)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "req-42", rec.Header().Get(echo.HeaderXRequestID))
+		assert.Contains(t, logs.String(), `"request_id":"req-42"`)
+	})
+}
+
+func TestStreamEventsWSOrigin(t *testing.T) {
+	e := echo.New()
+	NewHandler(dnsresolver.NewResolver(&MockRepository{}),
+		WithAllowedOrigins([]string{"https://console.example.com"})).RegisterRoutes(e)
+	server := httptest.NewServer(e)
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/events/ws"
+
+	dial := func(origin string) error {
+		config, err := websocket.NewConfig(wsURL, "http://placeholder")
+		require.NoError(t, err)
+		config.Header.Set(APIKeyHeader, testAPIKey)
+		config.Origin, err = url.Parse(origin)
+		require.NoError(t, err)
+		ws, err := websocket.DialConfig(config)
+		if err == nil {
+			ws.Close()
+		}
+		return err
+	}
+
+	assert.NoError(t, dial(server.URL), "same origin")
+	assert.NoError(t, dial("https://console.example.com"), "allow-listed origin")
+	assert.Error(t, dial("https://evil.example.net"), "foreign origin")
+}
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var logs bytes.Buffer
+	logger, err := logging.New(&logs, slog.LevelInfo, logging.FormatJSON)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Validator = v.New()
+	e.Use(Tracing(), RequestID(), RequestLogger(logger))
+	NewHandler(dnsresolver.NewResolver(&MockRepository{})).RegisterRoutes(e)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/ips?fqdn=example.com", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set(APIKeyHeader, viewerAPIKey)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /api/ips", spans[0].Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	assert.Contains(t, logs.String(), `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`)
+
+	// Проверка живости не трассируется
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Len(t, recorder.Ended(), 1)
+}
+
+func TestSearchDomains(t *testing.T) {
+	e := echo.New()
+	e.Validator = v.New()
+	repo := &MockRepository{domains: []string{
+		"corp.example.com", "git.corp.example.com", "mail.corp.example.com",
+		"notcorp.example.com", "api-v2.example.net", "xn--e1afmkfd.xn--p1ai",
+	}}
+	NewHandler(dnsresolver.NewResolver(repo), WithResponseValidation(specReporter(t))).RegisterRoutes(e)
+
+	search := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/domains?"+query, nil)
+		req.Header.Set(APIKeyHeader, viewerAPIKey)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	fqdns := func(rec *httptest.ResponseRecorder) []string {
+		var res struct {
+			FQDNs []string `json:"fqdns"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		return res.FQDNs
+	}
+
+	for _, tc := range []struct {
+		query string
+		want  []string
+	}{
+		{"suffix=corp.example.com", []string{"corp.example.com", "git.corp.example.com", "mail.corp.example.com"}},
+		{"suffix=" + url.QueryEscape("*.Corp.Example.COM."), []string{"corp.example.com", "git.corp.example.com", "mail.corp.example.com"}},
+		{"suffix=" + url.QueryEscape("пример.рф"), []string{"xn--e1afmkfd.xn--p1ai"}},
+		{"q=CORP", []string{"corp.example.com", "git.corp.example.com", "mail.corp.example.com", "notcorp.example.com"}},
+		{"q=" + url.QueryEscape("*.corp.*") + "&match=glob", []string{"git.corp.example.com", "mail.corp.example.com"}},
+		{"q=" + url.QueryEscape("*.CORP.Example.com") + "&match=glob", []string{"git.corp.example.com", "mail.corp.example.com"}},
+		{"q=" + url.QueryEscape("*.рф") + "&match=glob", []string{"xn--e1afmkfd.xn--p1ai"}},
+		{"q=" + url.QueryEscape(`^API-`) + "&match=regex", []string{"api-v2.example.net"}},
+		{"q=" + url.QueryEscape("[gm]*") + "&match=glob&suffix=example.com", []string{"git.corp.example.com", "mail.corp.example.com"}},
+		{"q=" + url.QueryEscape(`-v\d+\.`) + "&match=regex", []string{"api-v2.example.net"}},
+		{"q=nothing", []string{}},
+	} {
+		rec := search(tc.query)
+		require.Equal(t, http.StatusOK, rec.Code, tc.query)
+		assert.Equal(t, tc.want, fqdns(rec), tc.query)
+	}
+
+	t.Run("Results are paginated", func(t *testing.T) {
+		rec := search("suffix=corp.example.com&limit=2&sort=-name")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []string{"mail.corp.example.com", "git.corp.example.com"}, fqdns(rec))
+		assert.Equal(t, "3", rec.Header().Get("X-Total-Count"))
+		assert.Contains(t, rec.Header().Get("Link"), `rel="next"`)
+	})
+
+	t.Run("Invalid queries", func(t *testing.T) {
+		for _, query := range []string{
+			"match=glob",
+			"q=a&match=fuzzy",
+			"q=" + url.QueryEscape("[a") + "&match=glob",
+			"q=" + url.QueryEscape("(a") + "&match=regex",
+			"q=" + url.QueryEscape(`(a)\1`) + "&match=regex",
+			"q=" + strings.Repeat("a", 257),
+			"suffix=bad_name..com",
+			"sort=updated_at",
+		} {
+			rec := search(query)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		}
+	})
+}
+
+func TestAPIV2(t *testing.T) {
+	e := echo.New()
+	e.Validator = v.New()
+	NewHandler(dnsresolver.NewResolver(&MockRepository{}), WithResponseValidation(specReporter(t))).RegisterRoutes(e)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(APIKeyHeader, viewerAPIKey)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("ListDomains is paginated", func(t *testing.T) {
+		rec := get("/api/v2/domains?limit=1")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		var first struct {
+			Data       []Domain   `json:"data"`
+			Pagination Pagination `json:"pagination"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &first))
+		assert.Equal(t, []Domain{newDomain("example.com")}, first.Data)
+		assert.Equal(t, int64(2), first.Pagination.Total)
+		require.NotEmpty(t, first.Pagination.NextCursor)
+		assert.Equal(t, "2", rec.Header().Get("X-Total-Count"))
+
+		rec = get("/api/v2/domains?limit=1&cursor=" + first.Pagination.NextCursor)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{
+			"data": [{"fqdn":"example.org","fqdn_unicode":"example.org"}],
+			"pagination": {"limit":1,"total":2}
+		}`, rec.Body.String())
+		assert.Empty(t, rec.Header().Get("Link"))
+
+		rec = get("/api/v2/domains?limit=0")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		// Домены сортируются только по имени
+		rec = get("/api/v2/domains?sort=updated_at")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = get("/api/v2/domains?sort=-name")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"data":[{"fqdn":"example.org","fqdn_unicode":"example.org"},{"fqdn":"example.com"`)
+	})
+
+	t.Run("GetDomain", func(t *testing.T) {
+		rec := get("/api/v2/domains/Example.COM.")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"data":{
+			"fqdn":"example.com","fqdn_unicode":"example.com",
+			"records":[{"ip":"1.1.1.1","family":"ipv4"}]
+		}}`, rec.Body.String())
+
+		rec = get("/api/v2/domains/unknown.example")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"resource.not_found"`)
+	})
+
+	t.Run("ListDomainRecords", func(t *testing.T) {
+		rec := get("/api/v2/domains/example.com/records")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{
+			"data": [{"ip":"1.1.1.1","family":"ipv4"}],
+			"pagination": {"limit":100,"total":1}
+		}`, rec.Body.String())
+
+		rec = get("/api/v2/domains/unknown.example/records")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("ListAddressDomains", func(t *testing.T) {
+		rec := get("/api/v2/addresses/::ffff:1.1.1.1/domains")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"data":[{"fqdn":"example.com","fqdn_unicode":"example.com"}]`)
+
+		rec = get("/api/v2/addresses/9.9.9.9/domains")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"data":[]`)
+
+		rec = get("/api/v2/addresses/not-an-ip/domains")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("CreateDomain rejects invalid names", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/domains", strings.NewReader(`{"fqdn":"-bad.com"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"validation.fqdn_invalid"`)
+	})
+
+	t.Run("DeleteDomain requires editor", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v2/domains/example.com", nil)
+		req.Header.Set(APIKeyHeader, viewerAPIKey)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		req = httptest.NewRequest(http.MethodDelete, "/api/v2/domains/example.com", nil)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
 	})
 }
 
+func TestOpenAPISpec(t *testing.T) {
+	e := echo.New()
+	e.Validator = v.New()
+	resolver := dnsresolver.NewResolver(&MockRepository{})
+	h := NewHandler(resolver, WithRateLimiter(ratelimit.New(ratelimit.DefaultConfig())),
+		WithUpdater(dnsresolver.NewUpdater(resolver, time.Minute)), WithResponseValidation(specReporter(t)))
+	h.RegisterRoutes(e)
+
+	t.Run("Every route is documented", func(t *testing.T) {
+		for _, r := range e.Routes() {
+			if strings.HasPrefix(r.Method, "echo_") || r.Method == echo.RouteNotFound {
+				continue
+			}
+			path := strings.ReplaceAll(r.Path, "\\:", ":")
+			for _, part := range strings.Split(path, "/") {
+				if strings.HasPrefix(part, ":") {
+					path = strings.Replace(path, part, "x", 1)
+				}
+			}
+
+			req := httptest.NewRequest(r.Method, path, nil)
+			_, _, err := h.spec.FindRoute(req)
+			assert.NoError(t, err, "%s %s", r.Method, r.Path)
+		}
+	})
+
+	t.Run("Spec and Swagger UI are served", func(t *testing.T) {
+		for path, contentType := range map[string]string{
+			"/openapi.yaml": "application/yaml",
+			"/docs":         echo.MIMETextHTMLCharsetUTF8,
+			"/health":       echo.MIMEApplicationJSON,
+		} {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code, path)
+			assert.Equal(t, contentType, rec.Header().Get(echo.HeaderContentType), path)
+		}
+	})
+
+	t.Run("Requests are validated against the spec", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/ips:batchGet", strings.NewReader(`{"fqdns":"example.com"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"validation.failed"`)
+
+		req = httptest.NewRequest(http.MethodGet, "/api/audit?limit=5000", nil)
+		req.Header.Set(APIKeyHeader, tenantAdmin)
+		rec = httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"request.invalid"`)
+	})
+}
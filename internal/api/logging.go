@@ -0,0 +1,65 @@
+package api
+
+import (
+	"dns-resolver/internal/logging"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// RequestID берет X-Request-ID клиента или выдает новый, возвращает его
+// в ответе и кладет в контекст запроса, откуда его видят журнал и аудит
+func RequestID() echo.MiddlewareFunc {
+	return middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		Generator: logging.NewRequestID,
+		RequestIDHandler: func(c echo.Context, id string) {
+			req := c.Request()
+			c.SetRequest(req.WithContext(logging.WithRequestID(req.Context(), id)))
+		},
+	})
+}
+
+// RequestLogger пишет в журнал строку на каждый запрос. Ставится после
+// RequestID, чтобы строка несла идентификатор запроса
+func RequestLogger(logger *slog.Logger) echo.MiddlewareFunc {
+	return middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+		LogMethod:   true,
+		LogURI:      true,
+		LogStatus:   true,
+		LogLatency:  true,
+		LogRemoteIP: true,
+		LogError:    true,
+		HandleError: true,
+		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
+			attrs := []slog.Attr{
+				slog.String("method", v.Method),
+				slog.String("uri", v.URI),
+				slog.Int("status", v.Status),
+				slog.Duration("latency", v.Latency),
+				slog.String("remote_ip", v.RemoteIP),
+			}
+			level := slog.LevelInfo
+			if v.Status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			if v.Error != nil {
+				attrs = append(attrs, slog.String("error", v.Error.Error()))
+			}
+			logger.LogAttrs(c.Request().Context(), level, "request", attrs...)
+			return nil
+		},
+	})
+}
+
+// requestID — идентификатор текущего запроса, если он есть
+func requestID(c echo.Context) string {
+	if id := logging.RequestID(c.Request().Context()); id != "" {
+		return id
+	}
+	if id := c.Response().Header().Get(echo.HeaderXRequestID); id != "" {
+		return id
+	}
+	return c.Request().Header.Get(echo.HeaderXRequestID)
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"dns-resolver/docs"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/labstack/echo/v4"
+)
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+  <meta charset="utf-8">
+  <title>DNS Resolver API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    SwaggerUIBundle({url: "/openapi.yaml", dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`
+
+// Спецификация встроена в бинарник, поэтому разбирается один раз на процесс
+var loadSpec = sync.OnceValues(func() (routers.Router, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(docs.OpenAPI)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return gorillamux.NewRouter(doc)
+})
+
+// specOptions: учетные данные проверяет Authenticate, а значения
+// по умолчанию подставляют сами обработчики
+var specOptions = &openapi3filter.Options{
+	AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+	SkipSettingDefaults: true,
+}
+
+// WithResponseValidation включает сверку ответов со спецификацией.
+// Расхождения передаются в report; ответ клиенту не меняется. Для тестов
+func WithResponseValidation(report func(error)) Option {
+	return func(h *Handler) {
+		h.reportSpec = report
+	}
+}
+
+func (h *Handler) Health(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *Handler) OpenAPISpec(c echo.Context) error {
+	return c.Blob(http.StatusOK, "application/yaml", docs.OpenAPI)
+}
+
+func (h *Handler) SwaggerUI(c echo.Context) error {
+	return c.HTML(http.StatusOK, swaggerUIPage)
+}
+
+// specProblem переводит ошибку проверки запроса в ответ с кодом ошибки
+func specProblem(err error) *echo.HTTPError {
+	var parseErr *openapi3filter.ParseError
+	if errors.As(err, &parseErr) {
+		return malformedRequest(err)
+	}
+
+	var reqErr *openapi3filter.RequestError
+	if errors.As(err, &reqErr) && reqErr.RequestBody != nil {
+		return newProblem(http.StatusBadRequest, CodeValidationFailed, err.Error(), err)
+	}
+	return newProblem(http.StatusBadRequest, CodeInvalidRequest, err.Error(), err)
+}
+
+// ValidateSpec проверяет запрос по спецификации OpenAPI, а при
+// WithResponseValidation — и ответ, чтобы документация не расходилась с кодом
+func (h *Handler) ValidateSpec(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+
+		route, params, err := h.spec.FindRoute(req)
+		if err != nil {
+			// Маршрут Echo, которого нет в спецификации
+			if h.reportSpec != nil {
+				h.reportSpec(fmt.Errorf("%s %s: %w", req.Method, req.URL.Path, err))
+			}
+			return next(c)
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: params,
+			Route:      route,
+			Options:    specOptions,
+		}
+		if err := openapi3filter.ValidateRequest(req.Context(), input); err != nil {
+			return specProblem(err)
+		}
+
+		if h.reportSpec == nil {
+			return next(c)
+		}
+
+		w := &teeWriter{ResponseWriter: c.Response().Writer}
+		c.Response().Writer = w
+		if err := next(c); err != nil {
+			// Ошибку отдает HandleError, после чего ответ можно сверить
+			c.Error(err)
+		}
+		c.Response().Writer = w.ResponseWriter
+
+		if w.streaming() || req.Header.Get(echo.HeaderUpgrade) != "" {
+			return nil
+		}
+		if err := validateResponse(req.Context(), input, c.Response(), w.body.Bytes()); err != nil {
+			h.reportSpec(fmt.Errorf("%s %s: %w", req.Method, req.URL.Path, err))
+		}
+		return nil
+	}
+}
+
+func validateResponse(ctx context.Context, input *openapi3filter.RequestValidationInput, res *echo.Response, body []byte) error {
+	// Успешные статусы должны быть описаны явно, ошибки описывает схема Problem
+	if res.Status < http.StatusBadRequest && input.Route.Operation.Responses.Status(res.Status) == nil {
+		return fmt.Errorf("status %d is not documented", res.Status)
+	}
+
+	out := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 res.Status,
+		Header:                 res.Header(),
+		Body:                   io.NopCloser(bytes.NewReader(body)),
+		Options:                specOptions,
+	}
+	return openapi3filter.ValidateResponse(ctx, out)
+}
+
+// teeWriter копирует тело ответа для сверки со спецификацией.
+// Потоки (SSE, WebSocket) не копируются
+type teeWriter struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *teeWriter) streaming() bool {
+	return strings.HasPrefix(w.Header().Get(echo.HeaderContentType), "text/event-stream")
+}
+
+func (w *teeWriter) Write(b []byte) (int, error) {
+	if !w.streaming() {
+		w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *teeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"dns-resolver/internal/models"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+
+	headerTotalCount = "X-Total-Count"
+)
+
+// parsePageQuery читает limit, cursor и sort. sort — имя поля, с "-" для
+// обратного порядка; sorts перечисляет поля, допустимые для эндпоинта
+func parsePageQuery(c echo.Context, sorts ...models.SortField) (models.PageQuery, error) {
+	q := models.PageQuery{Limit: defaultPageLimit, Sort: models.SortByName}
+	if raw := c.QueryParam("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return q, errors.New("limit must be between 1 and 1000")
+		}
+		q.Limit = limit
+	}
+
+	if raw := c.QueryParam("sort"); raw != "" {
+		field, desc := strings.CutPrefix(raw, "-")
+		if !slices.Contains(sorts, models.SortField(field)) {
+			return q, errors.New("unsupported sort " + raw)
+		}
+		q.Sort, q.Desc = models.SortField(field), desc
+	}
+
+	if raw := c.QueryParam("cursor"); raw != "" {
+		cursor, err := models.DecodeCursor(raw)
+		if err != nil {
+			return q, err
+		}
+		// Курсор указывает позицию только в том порядке, в котором он получен
+		if cursor.Sort != q.Sort || cursor.Desc != q.Desc {
+			return q, errors.New("cursor does not match sort order")
+		}
+		q.After = cursor
+	}
+	return q, nil
+}
+
+// parseLookupQuery разбирает параметры поиска v1. Без limit и cursor выдача
+// не ограничивается, как до появления пагинации: на это рассчитывают старые клиенты
+func parseLookupQuery(c echo.Context) (models.PageQuery, error) {
+	q, err := parsePageQuery(c, models.SortByName, models.SortByUpdatedAt)
+	if err == nil && c.QueryParam("limit") == "" && c.QueryParam("cursor") == "" {
+		q.Limit = 0
+	}
+	return q, err
+}
+
+func pageQueryError(err error) *echo.HTTPError {
+	return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+}
+
+// setPageHeaders отдает общее число совпадений и ссылку на следующую страницу
+func setPageHeaders(c echo.Context, page models.Page) {
+	header := c.Response().Header()
+	header.Set(headerTotalCount, strconv.FormatInt(page.Total, 10))
+	if page.Next == nil {
+		return
+	}
+
+	next := *c.Request().URL
+	query := next.Query()
+	query.Set("cursor", page.Next.Encode())
+	next.RawQuery = query.Encode()
+	header.Set("Link", "<"+next.RequestURI()+`>; rel="next"`)
+}
+
+func nextCursor(page models.Page) string {
+	if page.Next == nil {
+		return ""
+	}
+	return page.Next.Encode()
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	dnsresolver "dns-resolver/internal/dns_resolver"
+	"dns-resolver/internal/models"
+	"dns-resolver/internal/validator"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	playground "github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	MIMEApplicationProblemJSON = "application/problem+json"
+
+	problemTypePrefix = "urn:dns-resolver:problem:"
+)
+
+// Машинно-читаемые коды ошибок, поле code в теле problem+json
+const (
+	CodeInvalidRequest     = "request.invalid"
+	CodeMalformedRequest   = "request.malformed"
+	CodeMethodNotAllowed   = "request.method_not_allowed"
+	CodeValidationFailed   = "validation.failed"
+	CodeFQDNInvalid        = "validation.fqdn_invalid"
+	CodeUnauthenticated    = "auth.unauthenticated"
+	CodeForbidden          = "auth.forbidden"
+	CodeNotFound           = "resource.not_found"
+	CodeConflict           = "resource.conflict"
+	CodeRateLimited        = "rate_limit.exceeded"
+	CodeNXDomain           = "dns.nxdomain"
+	CodeDNSTimeout         = "dns.timeout"
+	CodeDNSLookupFailed    = "dns.lookup_failed"
+	CodeSearchTimeout      = "search.timeout"
+	CodeStorageUnavailable = "storage.unavailable"
+	CodeStorageError       = "storage.error"
+	CodeInternal           = "internal"
+)
+
+// Problem — тело ошибки по RFC 7807
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// problemDetail передается в echo.HTTPError.Message, когда обработчик
+// знает точный код ошибки; иначе код выводится из HTTP-статуса
+type problemDetail struct {
+	Code   string
+	Detail string
+}
+
+func (p problemDetail) String() string {
+	return p.Detail
+}
+
+func newProblem(status int, code, detail string, internal error) *echo.HTTPError {
+	return echo.NewHTTPError(status, problemDetail{Code: code, Detail: detail}).SetInternal(internal)
+}
+
+func malformedRequest(err error) *echo.HTTPError {
+	return newProblem(http.StatusBadRequest, CodeMalformedRequest, "invalid request format", err)
+}
+
+// validationProblem выделяет ошибки имени домена из ошибок валидации запроса
+func validationProblem(err error) *echo.HTTPError {
+	var fieldErrs playground.ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		for _, fe := range fieldErrs {
+			if fe.Tag() == "dnsname" {
+				return newProblem(http.StatusBadRequest, CodeFQDNInvalid, fe.Error(), err)
+			}
+		}
+	}
+	return newProblem(http.StatusBadRequest, CodeValidationFailed, err.Error(), err)
+}
+
+// problemFromError сопоставляет типизированные ошибки резолвера,
+// валидатора и репозитория HTTP-статусам и кодам
+func problemFromError(err error) *echo.HTTPError {
+	switch {
+	case errors.Is(err, validator.ErrInvalidFQDN):
+		return newProblem(http.StatusBadRequest, CodeFQDNInvalid, err.Error(), err)
+	case errors.Is(err, dnsresolver.ErrNXDomain):
+		return newProblem(http.StatusUnprocessableEntity, CodeNXDomain, err.Error(), err)
+	case errors.Is(err, dnsresolver.ErrTimeout):
+		return newProblem(http.StatusGatewayTimeout, CodeDNSTimeout, err.Error(), err)
+	case errors.Is(err, dnsresolver.ErrLookupFailed):
+		return newProblem(http.StatusBadGateway, CodeDNSLookupFailed, err.Error(), err)
+	case errors.Is(err, models.ErrSearchTimeout):
+		return newProblem(http.StatusUnprocessableEntity, CodeSearchTimeout, "pattern is too expensive, narrow the search", err)
+	case errors.Is(err, models.ErrNotFound):
+		return newProblem(http.StatusNotFound, CodeNotFound, "resource not found", err)
+	case errors.Is(err, models.ErrConflict):
+		return newProblem(http.StatusConflict, CodeConflict, "resource already exists", err)
+	case errors.Is(err, models.ErrUnavailable):
+		return newProblem(http.StatusServiceUnavailable, CodeStorageUnavailable, "storage is temporarily unavailable", err)
+	}
+	return newProblem(http.StatusInternalServerError, CodeStorageError, "storage error", err)
+}
+
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	}
+	if status >= 400 && status < 500 {
+		return CodeInvalidRequest
+	}
+	return CodeInternal
+}
+
+// errorStatus возвращает HTTP-статус, с которым будет отдана ошибка
+func errorStatus(err error) int {
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return he.Code
+	}
+	return http.StatusInternalServerError
+}
+
+// HandleError отдает любую ошибку обработчика как application/problem+json
+func (h *Handler) HandleError(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	p := Problem{Status: http.StatusInternalServerError, Code: CodeInternal, Detail: "internal error"}
+
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		p.Status = he.Code
+		if detail, ok := he.Message.(problemDetail); ok {
+			p.Code, p.Detail = detail.Code, detail.Detail
+		} else {
+			p.Code, p.Detail = codeForStatus(he.Code), fmt.Sprint(he.Message)
+		}
+		if he.Internal != nil && p.Status >= http.StatusInternalServerError {
+			h.logger.ErrorContext(c.Request().Context(), "Request failed", "error", he.Internal)
+		}
+	} else {
+		h.logger.ErrorContext(c.Request().Context(), "Request failed", "error", err)
+	}
+
+	p.Type = problemTypePrefix + p.Code
+	p.Title = http.StatusText(p.Status)
+	p.Instance = c.Request().URL.Path
+	p.RequestID = requestID(c)
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(p.Status)
+	} else {
+		var body []byte
+		if body, err = json.Marshal(p); err == nil {
+			err = c.Blob(p.Status, MIMEApplicationProblemJSON, body)
+		}
+	}
+	if err != nil {
+		h.logger.ErrorContext(c.Request().Context(), "Failed to write error response", "error", err)
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"dns-resolver/internal/ratelimit"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	rateLimitLimitHeader     = "X-RateLimit-Limit"
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+)
+
+func requestClass(c echo.Context) ratelimit.Class {
+	// Пакетный поиск передает списки в теле POST, но ничего не меняет
+	if strings.HasSuffix(c.Request().URL.Path, ":batchGet") {
+		return ratelimit.Read
+	}
+	switch c.Request().Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ratelimit.Read
+	}
+	return ratelimit.Write
+}
+
+func (h *Handler) limit(c echo.Context, key string) error {
+	d := h.limiter.Allow(key, requestClass(c))
+	if d.Limit == 0 {
+		return nil
+	}
+
+	header := c.Response().Header()
+	header.Set(rateLimitLimitHeader, strconv.Itoa(d.Limit))
+	header.Set(rateLimitRemainingHeader, strconv.Itoa(d.Remaining))
+	if !d.Allowed {
+		header.Set(echo.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(d.RetryAfter.Seconds()))))
+		return echo.NewHTTPError(http.StatusTooManyRequests, "rate limit exceeded")
+	}
+	return nil
+}
+
+// IPExtractor определяет адрес клиента для лимитов и аудита. Без доверенных
+// прокси берется адрес соединения: X-Forwarded-For и X-Real-IP клиент
+// подставляет сам, и по ним лимит обходится сменой заголовка. trustedProxies —
+// CIDR через запятую, заголовок X-Forwarded-For читается только от них
+func IPExtractor(trustedProxies string) (echo.IPExtractor, error) {
+	var trust []echo.TrustOption
+	for _, raw := range strings.Split(trustedProxies, ",") {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy range %q: %w", raw, err)
+		}
+		trust = append(trust, echo.TrustIPRange(ipNet))
+	}
+	if len(trust) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	// По умолчанию echo доверяет всем частным и loopback-адресам — отключаем
+	trust = append(trust, echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false))
+	return echo.ExtractIPFromXFFHeader(trust...), nil
+}
+
+// LimitByClient ограничивает запросы с одного адреса еще до проверки
+// учетных данных, чтобы перебор ключей тоже упирался в лимит
+func (h *Handler) LimitByClient(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if h.limiter == nil {
+			return next(c)
+		}
+		if err := h.limit(c, "ip:"+c.RealIP()); err != nil {
+			return err
+		}
+		return next(c)
+	}
+}
+
+// LimitByCredential ограничивает запросы по API-ключу или субъекту JWT
+// независимо от адреса, с которого они приходят
+func (h *Handler) LimitByCredential(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		p, ok := principalFrom(c)
+		if h.limiter == nil || !ok {
+			return next(c)
+		}
+		if err := h.limit(c, fmt.Sprintf("tenant:%d:%s", p.TenantID, p.Subject)); err != nil {
+			return err
+		}
+		return next(c)
+	}
+}
+
+func (h *Handler) GetRateLimits(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.limiter.Config())
+}
+
+func (h *Handler) UpdateRateLimits(c echo.Context) error {
+	var req ratelimit.Config
+	if err := c.Bind(&req); err != nil {
+		return malformedRequest(err)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return validationProblem(err)
+	}
+
+	if h.limitStore != nil {
+		if err := h.limitStore.SaveRateLimits(c.Request().Context(), req); err != nil {
+			return problemFromError(err)
+		}
+	}
+	h.limiter.SetConfig(req)
+	return c.JSON(http.StatusOK, req)
+}
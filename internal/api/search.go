@@ -0,0 +1,56 @@
+package api
+
+import (
+	"dns-resolver/internal/models"
+	"dns-resolver/internal/validator"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SearchDomains ищет среди отслеживаемых доменов по суффиксу и шаблону q.
+// match задает вид шаблона: substring (по умолчанию), glob или regex
+func (h *Handler) SearchDomains(c echo.Context) error {
+	q, err := parsePageQuery(c, models.SortByName)
+	if err != nil {
+		return pageQueryError(err)
+	}
+
+	search := models.DomainQuery{
+		Pattern: c.QueryParam("q"),
+		Match:   models.MatchMode(c.QueryParam("match")),
+	}
+	if search.Match != "" && search.Pattern == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "match requires q")
+	}
+	if err := search.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	// Имена хранятся в нижнем регистре и в punycode, как и для подстроки
+	if search.Match == models.MatchGlob {
+		search.Pattern = validator.CanonicalGlob(search.Pattern)
+	}
+
+	// "*.corp.example.com" — то же, что "corp.example.com"
+	if raw := strings.TrimPrefix(c.QueryParam("suffix"), "*."); raw != "" {
+		if search.Suffix, err = canonicalFQDN(raw); err != nil {
+			return err
+		}
+	}
+
+	page, err := h.resolver.SearchFQDNs(c.Request().Context(), search, q)
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	setPageHeaders(c, page)
+	res := map[string]interface{}{
+		"fqdns":         page.Items,
+		"fqdns_unicode": unicodeFQDNs(page.Items),
+	}
+	if page.Next != nil {
+		res["next_cursor"] = page.Next.Encode()
+	}
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"context"
+	"dns-resolver/internal/models"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AddTenantRequest struct {
+	Name string `json:"name" validate:"required,slug"`
+}
+
+type AddAPIKeyRequest struct {
+	Name string `json:"name"`
+	Role string `json:"role" validate:"omitempty,oneof=viewer editor admin"`
+}
+
+type APIKeyResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Prefix    string    `json:"prefix"`
+	Role      string    `json:"role"`
+	Key       string    `json:"key,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newAPIKeyResponse(k models.APIKey) APIKeyResponse {
+	return APIKeyResponse{ID: k.ID, Name: k.Name, Prefix: k.Prefix, Role: string(k.Role), CreatedAt: k.CreatedAt}
+}
+
+// issueAPIKey создает ключ арендатора и возвращает его в открытом виде
+func (h *Handler) issueAPIKey(ctx context.Context, tenantID uint, name string, role models.Role) (APIKeyResponse, error) {
+	key, err := generateAPIKey()
+	if err != nil {
+		return APIKeyResponse{}, err
+	}
+
+	apiKey := models.APIKey{
+		TenantID: tenantID,
+		Name:     name,
+		Role:     role,
+		Prefix:   key[:len(apiKeyPrefix)+6],
+		KeyHash:  models.HashAPIKey(key),
+	}
+	if err := h.resolver.CreateAPIKey(ctx, &apiKey); err != nil {
+		return APIKeyResponse{}, err
+	}
+
+	resp := newAPIKeyResponse(apiKey)
+	resp.Key = key
+	return resp, nil
+}
+
+func (h *Handler) AddTenant(c echo.Context) error {
+	var req AddTenantRequest
+	if err := c.Bind(&req); err != nil {
+		return malformedRequest(err)
+	}
+	setAuditTarget(c, req.Name)
+
+	if err := c.Validate(req); err != nil {
+		return validationProblem(err)
+	}
+
+	ctx := c.Request().Context()
+	tenant := models.Tenant{Name: req.Name}
+	err := h.resolver.CreateTenant(ctx, &tenant)
+	if errors.Is(err, models.ErrConflict) {
+		return echo.NewHTTPError(http.StatusConflict, "tenant already exists")
+	}
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	key, err := h.issueAPIKey(ctx, tenant.ID, "initial", models.RoleAdmin)
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"id":         tenant.ID,
+		"name":       tenant.Name,
+		"created_at": tenant.CreatedAt,
+		"api_key":    key,
+	})
+}
+
+func (h *Handler) ListTenants(c echo.Context) error {
+	ctx := c.Request().Context()
+	tenants, err := h.resolver.ListTenants(ctx)
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	resp := make([]map[string]interface{}, len(tenants))
+	for i, t := range tenants {
+		resp[i] = map[string]interface{}{
+			"id":         t.ID,
+			"name":       t.Name,
+			"created_at": t.CreatedAt,
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"tenants": resp,
+	})
+}
+
+func (h *Handler) DeleteTenant(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid tenant id")
+	}
+	if uint(id) == models.DefaultTenantID {
+		return echo.NewHTTPError(http.StatusConflict, "default tenant cannot be deleted")
+	}
+
+	ctx := c.Request().Context()
+	err = h.resolver.DeleteTenant(ctx, uint(id))
+	if errors.Is(err, models.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "tenant not found")
+	}
+	// Журнал аудита только дополняется и держит арендатора
+	if errors.Is(err, models.ErrConflict) {
+		return echo.NewHTTPError(http.StatusConflict, "tenant has audit entries and cannot be deleted")
+	}
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) AddAPIKey(c echo.Context) error {
+	var req AddAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return malformedRequest(err)
+	}
+	setAuditTarget(c, req.Name)
+
+	if err := c.Validate(req); err != nil {
+		return validationProblem(err)
+	}
+
+	role := models.Role(req.Role)
+	if role == "" {
+		role = models.RoleViewer
+	}
+
+	ctx := c.Request().Context()
+	key, err := h.issueAPIKey(ctx, models.TenantID(ctx), req.Name, role)
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	return c.JSON(http.StatusCreated, key)
+}
+
+func (h *Handler) ListAPIKeys(c echo.Context) error {
+	ctx := c.Request().Context()
+	keys, err := h.resolver.ListAPIKeys(ctx)
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	resp := make([]APIKeyResponse, len(keys))
+	for i, k := range keys {
+		resp[i] = newAPIKeyResponse(k)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"keys": resp,
+	})
+}
+
+func (h *Handler) DeleteAPIKey(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid key id")
+	}
+
+	ctx := c.Request().Context()
+	err = h.resolver.DeleteAPIKey(ctx, uint(id))
+	if errors.Is(err, models.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "key not found")
+	}
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,17 @@
+package api
+
+import (
+	"dns-resolver/internal/tracing"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+)
+
+// Tracing открывает серверный спан на каждый запрос, продолжая трассировку
+// из заголовков traceparent/tracestate. Ставится первым, чтобы спан был
+// в контексте у остальных middleware и обработчиков
+func Tracing() echo.MiddlewareFunc {
+	return otelecho.Middleware(tracing.ServiceName, otelecho.WithSkipper(func(c echo.Context) bool {
+		return c.Path() == "/health"
+	}))
+}
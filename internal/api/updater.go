@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	dnsresolver "dns-resolver/internal/dns_resolver"
+	"dns-resolver/internal/models"
+	"dns-resolver/internal/validator"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// UpdaterControl управляет фоновым обновлением записей. Его реализуют
+// dnsresolver.Updater в режиме leader и dnsresolver.Worker в режиме queue
+type UpdaterControl interface {
+	Status() dnsresolver.UpdaterStatus
+	Interval() time.Duration
+	Pause(ctx context.Context) error
+	Resume(ctx context.Context) error
+	SetInterval(ctx context.Context, interval time.Duration) error
+	RefreshAll(ctx context.Context) error
+}
+
+// WithUpdater включает управление фоновым обновлением записей
+func WithUpdater(updater UpdaterControl) Option {
+	return func(h *Handler) {
+		h.updater = updater
+	}
+}
+
+type CycleStatusResponse struct {
+	Trigger    dnsresolver.CycleTrigger `json:"trigger"`
+	StartedAt  time.Time                `json:"started_at"`
+	FinishedAt *time.Time               `json:"finished_at,omitempty"`
+	Total      int                      `json:"total"`
+	Succeeded  int                      `json:"succeeded"`
+	Failed     int                      `json:"failed"`
+}
+
+type UpdaterStatusResponse struct {
+	Active          bool                 `json:"active"`
+	Interval        string               `json:"interval"`
+	IntervalSeconds float64              `json:"interval_seconds"`
+	Paused          bool                 `json:"paused"`
+	NextRun         *time.Time           `json:"next_run,omitempty"`
+	CurrentCycle    *CycleStatusResponse `json:"current_cycle,omitempty"`
+	LastCycle       *CycleStatusResponse `json:"last_cycle,omitempty"`
+}
+
+type SetIntervalRequest struct {
+	// Интервал в формате Go: "30s", "2m", "1h30m"
+	Interval string `json:"interval" validate:"required"`
+}
+
+func newCycleStatus(s *dnsresolver.CycleStatus) *CycleStatusResponse {
+	if s == nil {
+		return nil
+	}
+	res := &CycleStatusResponse{
+		Trigger:   s.Trigger,
+		StartedAt: s.StartedAt,
+		Total:     s.Total,
+		Succeeded: s.Succeeded,
+		Failed:    s.Failed,
+	}
+	if !s.FinishedAt.IsZero() {
+		res.FinishedAt = &s.FinishedAt
+	}
+	return res
+}
+
+func (h *Handler) updaterStatus(c echo.Context, status int) error {
+	s := h.updater.Status()
+	res := UpdaterStatusResponse{
+		Active:          s.Active,
+		Interval:        s.Interval.String(),
+		IntervalSeconds: s.Interval.Seconds(),
+		Paused:          s.Paused,
+		CurrentCycle:    newCycleStatus(s.Current),
+		LastCycle:       newCycleStatus(s.Last),
+	}
+	if !s.NextRun.IsZero() {
+		res.NextRun = &s.NextRun
+	}
+	return c.JSON(status, res)
+}
+
+func (h *Handler) GetUpdaterStatus(c echo.Context) error {
+	return h.updaterStatus(c, http.StatusOK)
+}
+
+func (h *Handler) PauseUpdater(c echo.Context) error {
+	if err := h.updater.Pause(c.Request().Context()); err != nil {
+		return problemFromError(err)
+	}
+	return h.updaterStatus(c, http.StatusOK)
+}
+
+func (h *Handler) ResumeUpdater(c echo.Context) error {
+	if err := h.updater.Resume(c.Request().Context()); err != nil {
+		return problemFromError(err)
+	}
+	return h.updaterStatus(c, http.StatusOK)
+}
+
+func (h *Handler) SetUpdaterInterval(c echo.Context) error {
+	var req SetIntervalRequest
+	if err := c.Bind(&req); err != nil {
+		return malformedRequest(err)
+	}
+	setAuditTarget(c, req.Interval)
+
+	if err := c.Validate(req); err != nil {
+		return validationProblem(err)
+	}
+
+	interval, err := time.ParseDuration(req.Interval)
+	if err != nil {
+		return newProblem(http.StatusBadRequest, CodeValidationFailed, "invalid interval "+req.Interval, err)
+	}
+	err = h.updater.SetInterval(c.Request().Context(), interval)
+	if errors.Is(err, dnsresolver.ErrInvalidInterval) {
+		return newProblem(http.StatusBadRequest, CodeValidationFailed, err.Error(), err)
+	}
+	if err != nil {
+		return problemFromError(err)
+	}
+	return h.updaterStatus(c, http.StatusOK)
+}
+
+// RefreshAll запускает внеочередной проход по всем арендаторам и сразу отвечает;
+// ход прохода виден в current_cycle на реплике-лидере. В режиме очереди
+// все задания назначаются на сейчас, и их разбирают все реплики
+func (h *Handler) RefreshAll(c echo.Context) error {
+	if err := h.updater.RefreshAll(c.Request().Context()); err != nil {
+		return problemFromError(err)
+	}
+	return h.updaterStatus(c, http.StatusAccepted)
+}
+
+// RefreshFQDN немедленно перепроверяет одно отслеживаемое имя арендатора
+func (h *Handler) RefreshFQDN(c echo.Context) error {
+	fqdn, err := canonicalFQDN(c.Param("fqdn"))
+	if err != nil {
+		return err
+	}
+	setAuditTarget(c, fqdn)
+
+	ctx := c.Request().Context()
+	known, err := h.resolver.GetIPsByFQDN(ctx, fqdn)
+	if err != nil {
+		return problemFromError(err)
+	}
+	// Обновление не должно начинать отслеживание нового имени
+	if len(known) == 0 {
+		return newProblem(http.StatusNotFound, CodeNotFound, "fqdn not found", models.ErrNotFound)
+	}
+
+	ips, err := h.resolver.Resolve(ctx, fqdn)
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"fqdn":         fqdn,
+		"fqdn_unicode": validator.UnicodeFQDN(fqdn),
+		"ips":          nonNil(ips),
+	})
+}
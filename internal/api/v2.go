@@ -0,0 +1,181 @@
+package api
+
+import (
+	"dns-resolver/internal/models"
+	"dns-resolver/internal/validator"
+	"net/http"
+	"net/netip"
+
+	"github.com/labstack/echo/v4"
+)
+
+// API v2 построен вокруг ресурсов: домены, их записи и адреса.
+// Ответ всегда завернут в {"data": ...}, списки дополнительно несут "pagination"
+
+type Domain struct {
+	FQDN        string         `json:"fqdn"`
+	FQDNUnicode string         `json:"fqdn_unicode"`
+	Records     []DomainRecord `json:"records,omitempty"`
+}
+
+type DomainRecord struct {
+	IP     string `json:"ip"`
+	Family string `json:"family"`
+}
+
+type Pagination struct {
+	Limit      int    `json:"limit"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type Envelope struct {
+	Data interface{} `json:"data"`
+}
+
+type ListEnvelope struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+func newDomain(fqdn string) Domain {
+	return Domain{FQDN: fqdn, FQDNUnicode: validator.UnicodeFQDN(fqdn)}
+}
+
+func newDomainRecords(ips []string) []DomainRecord {
+	res := make([]DomainRecord, len(ips))
+	for i, ip := range ips {
+		res[i] = DomainRecord{IP: ip, Family: "ipv4"}
+		if addr, err := netip.ParseAddr(ip); err == nil && addr.Is6() {
+			res[i].Family = "ipv6"
+		}
+	}
+	return res
+}
+
+func newPagination(q models.PageQuery, page models.Page) Pagination {
+	return Pagination{Limit: q.Limit, Total: page.Total, NextCursor: nextCursor(page)}
+}
+
+func domainList(fqdns []string) []Domain {
+	res := make([]Domain, len(fqdns))
+	for i, fqdn := range fqdns {
+		res[i] = newDomain(fqdn)
+	}
+	return res
+}
+
+// domainRecords возвращает IP домена или 404, если домен не отслеживается
+func (h *Handler) domainRecords(c echo.Context) (string, []string, error) {
+	fqdn, err := canonicalFQDN(c.Param("fqdn"))
+	if err != nil {
+		return "", nil, err
+	}
+
+	ips, err := h.resolver.GetIPsByFQDN(c.Request().Context(), fqdn)
+	if err != nil {
+		return "", nil, problemFromError(err)
+	}
+	if len(ips) == 0 {
+		return "", nil, newProblem(http.StatusNotFound, CodeNotFound, "domain not found", models.ErrNotFound)
+	}
+	return fqdn, ips, nil
+}
+
+func (h *Handler) ListDomains(c echo.Context) error {
+	q, err := parsePageQuery(c, models.SortByName)
+	if err != nil {
+		return pageQueryError(err)
+	}
+
+	page, err := h.resolver.ListFQDNs(c.Request().Context(), q)
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	setPageHeaders(c, page)
+	return c.JSON(http.StatusOK, ListEnvelope{Data: domainList(page.Items), Pagination: newPagination(q, page)})
+}
+
+// CreateDomain — то же, что POST /api/fqdns: имя резолвится и начинает отслеживаться
+func (h *Handler) CreateDomain(c echo.Context) error {
+	var req AddFQDNRequest
+	if err := c.Bind(&req); err != nil {
+		return malformedRequest(err)
+	}
+	setAuditTarget(c, req.FQDN)
+
+	if err := c.Validate(req); err != nil {
+		return validationProblem(err)
+	}
+
+	fqdn, err := canonicalFQDN(req.FQDN)
+	if err != nil {
+		return err
+	}
+	setAuditTarget(c, fqdn)
+
+	ips, err := h.resolver.Resolve(c.Request().Context(), fqdn)
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	domain := newDomain(fqdn)
+	domain.Records = newDomainRecords(ips)
+	return c.JSON(http.StatusCreated, Envelope{Data: domain})
+}
+
+func (h *Handler) GetDomain(c echo.Context) error {
+	fqdn, ips, err := h.domainRecords(c)
+	if err != nil {
+		return err
+	}
+
+	domain := newDomain(fqdn)
+	domain.Records = newDomainRecords(ips)
+	return c.JSON(http.StatusOK, Envelope{Data: domain})
+}
+
+func (h *Handler) ListDomainRecords(c echo.Context) error {
+	q, err := parsePageQuery(c, models.SortByName, models.SortByUpdatedAt)
+	if err != nil {
+		return pageQueryError(err)
+	}
+
+	fqdn, err := canonicalFQDN(c.Param("fqdn"))
+	if err != nil {
+		return err
+	}
+
+	page, err := h.resolver.ListIPsByFQDN(c.Request().Context(), fqdn, q)
+	if err != nil {
+		return problemFromError(err)
+	}
+	if page.Total == 0 {
+		return newProblem(http.StatusNotFound, CodeNotFound, "domain not found", models.ErrNotFound)
+	}
+
+	setPageHeaders(c, page)
+	return c.JSON(http.StatusOK, ListEnvelope{Data: newDomainRecords(page.Items), Pagination: newPagination(q, page)})
+}
+
+// ListAddressDomains — обратный поиск: домены, которые сейчас резолвятся в адрес
+func (h *Handler) ListAddressDomains(c echo.Context) error {
+	q, err := parsePageQuery(c, models.SortByName, models.SortByUpdatedAt)
+	if err != nil {
+		return pageQueryError(err)
+	}
+
+	addr, err := netip.ParseAddr(c.Param("ip"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid ip "+c.Param("ip"))
+	}
+
+	page, err := h.resolver.ListFQDNsByIP(c.Request().Context(), addr.Unmap().String(), q)
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	setPageHeaders(c, page)
+	return c.JSON(http.StatusOK, ListEnvelope{Data: domainList(page.Items), Pagination: newPagination(q, page)})
+}
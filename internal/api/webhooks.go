@@ -0,0 +1,191 @@
+package api
+
+import (
+	"crypto/rand"
+	"dns-resolver/internal/models"
+	"dns-resolver/internal/webhook"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AddWebhookRequest struct {
+	URL         string   `json:"url" validate:"required,url"`
+	Secret      string   `json:"secret"`
+	FQDNPattern string   `json:"fqdn_pattern"`
+	Events      []string `json:"events" validate:"dive,oneof=fqdn_added ip_added ip_removed nxdomain resolve_failed"`
+}
+
+type WebhookResponse struct {
+	ID          uint      `json:"id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"secret,omitempty"`
+	FQDNPattern string    `json:"fqdn_pattern"`
+	Events      []string  `json:"events"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type DeliveryResponse struct {
+	ID            uint       `json:"id"`
+	EventType     string     `json:"event_type"`
+	Payload       string     `json:"payload"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	ResponseCode  int        `json:"response_code,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func newWebhookResponse(hook models.Webhook) WebhookResponse {
+	events := []string{}
+	for _, e := range hook.Events() {
+		events = append(events, string(e))
+	}
+
+	return WebhookResponse{
+		ID:          hook.ID,
+		URL:         hook.URL,
+		FQDNPattern: hook.FQDNPattern,
+		Events:      events,
+		CreatedAt:   hook.CreatedAt,
+	}
+}
+
+func (h *Handler) AddWebhook(c echo.Context) error {
+	var req AddWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return malformedRequest(err)
+	}
+	setAuditTarget(c, req.URL)
+
+	if err := c.Validate(req); err != nil {
+		return validationProblem(err)
+	}
+
+	if req.FQDNPattern == "" {
+		req.FQDNPattern = "*"
+	}
+	if err := (models.EventFilter{Patterns: []string{req.FQDNPattern}}).Validate(); err != nil {
+		return newProblem(http.StatusBadRequest, CodeValidationFailed, err.Error(), err)
+	}
+	if err := webhook.CheckURL(req.URL); err != nil {
+		return newProblem(http.StatusBadRequest, CodeValidationFailed, err.Error(), err)
+	}
+
+	if req.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return newProblem(http.StatusInternalServerError, CodeInternal, "failed to generate secret", err)
+		}
+		req.Secret = hex.EncodeToString(secret)
+	}
+
+	hook := models.Webhook{
+		URL:         req.URL,
+		Secret:      req.Secret,
+		FQDNPattern: req.FQDNPattern,
+		EventTypes:  strings.Join(req.Events, ","),
+	}
+
+	ctx := c.Request().Context()
+	if err := h.resolver.CreateWebhook(ctx, &hook); err != nil {
+		return problemFromError(err)
+	}
+
+	// Секрет отдаем только при создании
+	resp := newWebhookResponse(hook)
+	resp.Secret = hook.Secret
+
+	return c.JSON(http.StatusCreated, resp)
+}
+
+func (h *Handler) ListWebhooks(c echo.Context) error {
+	ctx := c.Request().Context()
+	hooks, err := h.resolver.ListWebhooks(ctx)
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	resp := make([]WebhookResponse, len(hooks))
+	for i, hook := range hooks {
+		resp[i] = newWebhookResponse(hook)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"webhooks": resp,
+	})
+}
+
+func (h *Handler) DeleteWebhook(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid webhook id")
+	}
+
+	ctx := c.Request().Context()
+	err = h.resolver.DeleteWebhook(ctx, uint(id))
+	if errors.Is(err, models.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "webhook not found")
+	}
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) ListWebhookDeliveries(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid webhook id")
+	}
+
+	limit := 100
+	if l := c.QueryParam("limit"); l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit < 1 || limit > 1000 {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and 1000")
+		}
+	}
+
+	ctx := c.Request().Context()
+	if _, err := h.resolver.GetWebhook(ctx, uint(id)); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "webhook not found")
+		}
+		return problemFromError(err)
+	}
+
+	deliveries, err := h.resolver.ListDeliveries(ctx, uint(id), limit)
+	if err != nil {
+		return problemFromError(err)
+	}
+
+	resp := make([]DeliveryResponse, len(deliveries))
+	for i, d := range deliveries {
+		resp[i] = DeliveryResponse{
+			ID:            d.ID,
+			EventType:     d.EventType,
+			Payload:       d.Payload,
+			Status:        d.Status,
+			Attempts:      d.Attempts,
+			ResponseCode:  d.ResponseCode,
+			LastError:     d.LastError,
+			NextAttemptAt: d.NextAttemptAt,
+			DeliveredAt:   d.DeliveredAt,
+			CreatedAt:     d.CreatedAt,
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"webhook_id": id,
+		"deliveries": resp,
+	})
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"dns-resolver/internal/models"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnauthenticated — учетные данные отсутствуют или не приняты
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Principal — аутентифицированный владелец запроса
+type Principal struct {
+	TenantID uint
+	// KeyID — ключ, которым выполнен запрос; 0 для JWT и ключа администратора
+	KeyID   uint
+	Subject string
+	Role    models.Role
+	// ServiceAdmin — запрос со служебным ключом оператора сервиса. Ключи и JWT
+	// администраторов арендаторов, в том числе арендатора по умолчанию, его не дают
+	ServiceAdmin bool
+}
+
+// KeyStore ищет API-ключи; реализуется репозиторием
+type KeyStore interface {
+	GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error)
+}
+
+// Authenticator проверяет учетные данные одинаково для REST и gRPC:
+// служебный ключ администратора, JWT (если задан Verifier) или API-ключ арендатора
+type Authenticator struct {
+	keys     KeyStore
+	adminKey string
+	jwt      *Verifier
+}
+
+func NewAuthenticator(keys KeyStore, adminKey string, jwt *Verifier) *Authenticator {
+	return &Authenticator{keys: keys, adminKey: adminKey, jwt: jwt}
+}
+
+// BearerToken извлекает токен из значения заголовка Authorization: Bearer
+func BearerToken(authorization string) string {
+	if len(authorization) > 7 && strings.EqualFold(authorization[:7], "Bearer ") {
+		return strings.TrimSpace(authorization[7:])
+	}
+	return ""
+}
+
+func (a *Authenticator) Authenticate(ctx context.Context, credential string) (Principal, error) {
+	if credential == "" {
+		return Principal{}, fmt.Errorf("%w: missing credentials", ErrUnauthenticated)
+	}
+
+	if a.adminKey != "" && subtle.ConstantTimeCompare([]byte(credential), []byte(a.adminKey)) == 1 {
+		return Principal{TenantID: models.DefaultTenantID, Subject: "admin", Role: models.RoleAdmin, ServiceAdmin: true}, nil
+	}
+
+	if a.jwt != nil && LooksLikeJWT(credential) {
+		claims, err := a.jwt.Verify(credential)
+		if err != nil {
+			return Principal{}, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+		}
+		role := models.Role(claims.Role)
+		if claims.TenantID == 0 || !role.Valid() {
+			return Principal{}, fmt.Errorf("%w: token has no tenant_id or valid role", ErrUnauthenticated)
+		}
+		return Principal{TenantID: claims.TenantID, Subject: claims.Subject, Role: role}, nil
+	}
+
+	apiKey, err := a.keys.GetAPIKeyByHash(ctx, models.HashAPIKey(credential))
+	if errors.Is(err, models.ErrNotFound) {
+		return Principal{}, fmt.Errorf("%w: invalid API key", ErrUnauthenticated)
+	}
+	if err != nil {
+		return Principal{}, err
+	}
+
+	return Principal{
+		TenantID: apiKey.TenantID,
+		KeyID:    apiKey.ID,
+		Subject:  "key:" + strconv.FormatUint(uint64(apiKey.ID), 10),
+		Role:     apiKey.Role,
+	}, nil
+}
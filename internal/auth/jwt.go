@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token expired")
+)
+
+// leeway компенсирует расхождение часов с издателем токенов
+const leeway = time.Minute
+
+// Claims — поля JWT, которые использует сервис
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	TenantID  uint     `json:"tenant_id"`
+	Role      string   `json:"role"`
+}
+
+// audience принимает aud и строкой, и массивом строк
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type verificationKey struct {
+	kid string
+	key crypto.PublicKey
+}
+
+// Verifier проверяет JWT (RS256, ES256) по ключам из JWKS
+type Verifier struct {
+	keys     []verificationKey
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+// LoadVerifier читает JWKS из файла. Пустые issuer и audience не проверяются
+func LoadVerifier(path, issuer, audience string) (*Verifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS: %w", err)
+	}
+	return NewVerifier(data, issuer, audience)
+}
+
+func NewVerifier(jwks []byte, issuer, audience string) (*Verifier, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(jwks, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	v := &Verifier{issuer: issuer, audience: audience, now: time.Now}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		v.keys = append(v.keys, verificationKey{kid: k.Kid, key: key})
+	}
+	if len(v.keys) == 0 {
+		return nil, errors.New("JWKS contains no signing keys")
+	}
+
+	return v, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// LooksLikeJWT отличает JWT от статического API-ключа
+func LooksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// Verify проверяет подпись и сроки токена и возвращает его claims
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+	verified := false
+	for _, k := range v.keys {
+		if header.Kid != "" && k.kid != "" && header.Kid != k.kid {
+			continue
+		}
+		if verifySignature(header.Alg, k.key, digest[:], sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	now := v.now()
+	if claims.ExpiresAt == 0 || now.After(time.Unix(claims.ExpiresAt, 0).Add(leeway)) {
+		return nil, ErrExpiredToken
+	}
+	if claims.NotBefore != 0 && now.Add(leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, ErrInvalidToken
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return nil, ErrInvalidToken
+	}
+	if v.audience != "" && !claims.Audience.contains(v.audience) {
+		return nil, ErrInvalidToken
+	}
+
+	return &claims, nil
+}
+
+func (a audience) contains(s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func verifySignature(alg string, key crypto.PublicKey, digest, sig []byte) bool {
+	switch alg {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest, sig) == nil
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		return ecdsa.Verify(pub, digest, r, s)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func sign(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	input := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		require.NoError(t, err)
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+
+	return input + "." + b64(sig)
+}
+
+func testJWKS(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) []byte {
+	jwks, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{"kid": "rsa-1", "kty": "RSA", "alg": "RS256", "use": "sig",
+				"n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kid": "ec-1", "kty": "EC", "crv": "P-256",
+				"x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+		},
+	})
+	require.NoError(t, err)
+	return jwks
+}
+
+func TestVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	v, err := NewVerifier(testJWKS(t, rsaKey, ecKey), "https://idp.example.com", "dns-resolver")
+	require.NoError(t, err)
+
+	claims := map[string]interface{}{
+		"sub":       "alice",
+		"iss":       "https://idp.example.com",
+		"aud":       []string{"dns-resolver", "other"},
+		"exp":       time.Now().Add(time.Hour).Unix(),
+		"tenant_id": 2,
+		"role":      "editor",
+	}
+
+	t.Run("RS256", func(t *testing.T) {
+		got, err := v.Verify(sign(t, "RS256", "rsa-1", rsaKey, claims))
+		require.NoError(t, err)
+		assert.Equal(t, "alice", got.Subject)
+		assert.Equal(t, uint(2), got.TenantID)
+		assert.Equal(t, "editor", got.Role)
+	})
+
+	t.Run("ES256 without kid", func(t *testing.T) {
+		_, err := v.Verify(sign(t, "ES256", "", ecKey, claims))
+		require.NoError(t, err)
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		_, err = v.Verify(sign(t, "RS256", "rsa-1", other, claims))
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		expired := map[string]interface{}{}
+		for k, val := range claims {
+			expired[k] = val
+		}
+		expired["exp"] = time.Now().Add(-time.Hour).Unix()
+		_, err := v.Verify(sign(t, "RS256", "rsa-1", rsaKey, expired))
+		assert.ErrorIs(t, err, ErrExpiredToken)
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		wrong := map[string]interface{}{}
+		for k, val := range claims {
+			wrong[k] = val
+		}
+		wrong["aud"] = "someone-else"
+		_, err := v.Verify(sign(t, "RS256", "rsa-1", rsaKey, wrong))
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("alg none", func(t *testing.T) {
+		parts := strings.Split(sign(t, "RS256", "rsa-1", rsaKey, claims), ".")
+		parts[0] = b64([]byte(`{"alg":"none"}`))
+		_, err := v.Verify(strings.Join(parts, "."))
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
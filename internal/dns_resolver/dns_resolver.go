@@ -2,83 +2,217 @@ package dnsresolver
 
 import (
 	"context"
+	"dns-resolver/internal/logging"
 	"dns-resolver/internal/models"
-	"log"
+	"dns-resolver/internal/validator"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net"
-	"os"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// Notifier получает события об изменении записей, обнаруженные при резолвинге.
+// Notify вызывается один раз на изменение со всеми его событиями в транзакции
+// изменения записей: ошибка откатывает изменение вместе с событиями,
+// и оно повторится при следующем обновлении
+type Notifier interface {
+	Notify(ctx context.Context, events []models.Event) error
+}
+
 type Resolver struct {
 	models.Repository
+
+	lookupIP  func(ctx context.Context, host string) ([]net.IP, error)
+	notifiers []Notifier
+	broker    *Broker
+	feed      EventFeed
+	logger    *slog.Logger
 }
 
 func NewResolver(repo models.Repository) *Resolver {
-	return &Resolver{repo}
+	return &Resolver{
+		Repository: repo,
+		lookupIP:   tracedLookup(net.DefaultResolver.LookupIP),
+		broker:     NewBroker(),
+		logger:     logging.Component("resolver"),
+	}
+}
+
+// Subscribe подписывает на поток изменений записей, см. Broker.Subscribe
+func (r *Resolver) Subscribe(filter models.EventFilter) (<-chan models.Event, func()) {
+	return r.broker.Subscribe(filter)
+}
+
+// AddNotifier подписывает n на события резолвера. Вызывать до запуска сервиса
+func (r *Resolver) AddNotifier(n Notifier) {
+	r.notifiers = append(r.notifiers, n)
 }
 
 func (r *Resolver) Resolve(ctx context.Context, fqdn string) ([]string, error) {
-	ips, err := net.LookupIP(fqdn)
+	ctx, span := tracer.Start(ctx, "Resolver.Resolve", trace.WithAttributes(attribute.String("dns.qname", fqdn)))
+	defer span.End()
+
+	ips, err := r.resolve(ctx, fqdn)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return ips, err
+}
+
+func (r *Resolver) resolve(ctx context.Context, fqdn string) ([]string, error) {
+	// Записи и события хранят A-label, в каком бы виде ни пришло имя
+	fqdn, err := validator.CanonicalFQDN(fqdn)
 	if err != nil {
 		return nil, err
 	}
 
-	var ipStrings []string 
-	for _, ip := range ips { 
-		ipStrings = append(ipStrings, ip.String())
-		r.AddOrUpdate(ctx, fqdn, ip.String())
+	known, err := r.GetIPsByFQDN(ctx, fqdn)
+	if err != nil {
+		return nil, err
 	}
 
-	return ipStrings, nil
-}
+	ips, err := r.lookupIP(ctx, fqdn)
+	if err != nil {
+		lookupErr := newLookupError(fqdn, err)
+		if len(known) > 0 {
+			evType := models.EventResolveFailed
+			if errors.Is(lookupErr, ErrNXDomain) {
+				evType = models.EventNXDomain
+			}
+			ev := models.Event{Type: evType, FQDN: fqdn, Error: err.Error()}
+			if err := r.commit(ctx, func(ctx context.Context, emit func(models.Event)) error {
+				emit(ev)
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		}
+		return nil, lookupErr
+	}
 
-func (r *Resolver) DNSUpdater(ctx context.Context, interval time.Duration) {
-	logger := log.New(os.Stdout, "DNS_UPDATER: ", log.LstdFlags|log.Lshortfile)
-	logger.Printf("Starting DNS updater with interval %v", interval)
-
-	ticker := time.NewTicker(interval)
-	defer func() {
-		ticker.Stop()
-		logger.Println("DNS updater stopped")
-	}()
-
-	for {
-		select {
-		case <-ticker.C:
-			startTime := time.Now()
-			logger.Println("Starting DNS records update cycle...")
-
-			fqdns, err := r.GetAllFQDNs(ctx)
-			if err != nil {
-				logger.Printf("Failed to get FQDNs: %v", err)
+	previous := make(map[string]bool, len(known))
+	for _, ip := range known {
+		previous[ip] = true
+	}
+
+	var ipStrings []string
+	current := make(map[string]bool, len(ips))
+	for _, ip := range ips {
+		ipStr := ip.String()
+		if !current[ipStr] {
+			current[ipStr] = true
+			ipStrings = append(ipStrings, ipStr)
+		}
+	}
+
+	err = r.commit(ctx, func(ctx context.Context, emit func(models.Event)) error {
+		if len(known) == 0 && len(ipStrings) > 0 {
+			emit(models.Event{Type: models.EventFQDNAdded, FQDN: fqdn})
+		}
+		for _, ip := range ipStrings {
+			if err := r.AddOrUpdate(ctx, fqdn, ip); err != nil {
+				return err
+			}
+			if !previous[ip] {
+				emit(models.Event{Type: models.EventIPAdded, FQDN: fqdn, IP: ip})
+			}
+		}
+
+		// Адреса, которые больше не возвращаются DNS, удаляем
+		for _, ip := range known {
+			if current[ip] {
 				continue
 			}
+			if err := r.DeleteRecord(ctx, fqdn, ip); err != nil {
+				return err
+			}
+			emit(models.Event{Type: models.EventIPRemoved, FQDN: fqdn, IP: ip})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ipStrings, nil
+}
+
+// Remove прекращает отслеживание FQDN: удаляет все его записи
+// и отправляет ip_removed по каждому адресу
+func (r *Resolver) Remove(ctx context.Context, fqdn string) error {
+	fqdn, err := validator.CanonicalFQDN(fqdn)
+	if err != nil {
+		return err
+	}
+
+	known, err := r.GetIPsByFQDN(ctx, fqdn)
+	if err != nil {
+		return err
+	}
+	if len(known) == 0 {
+		return models.ErrNotFound
+	}
 
-			logger.Printf("Found %d FQDNs to update", len(fqdns))
-
-			successCount := 0
-			for _, fqdn := range fqdns {
-				select {
-				case <-ctx.Done():
-					logger.Println("Update cycle interrupted by context")
-					return
-				default:
-					ips, err := r.Resolve(ctx, fqdn)
-					if err != nil {
-						logger.Printf("Failed to resolve %s: %v", fqdn, err)
-						continue
-					}
-					successCount++
-					logger.Printf("Updated %s -> %v", fqdn, ips)
-				}
+	return r.commit(ctx, func(ctx context.Context, emit func(models.Event)) error {
+		for _, ip := range known {
+			if err := r.DeleteRecord(ctx, fqdn, ip); err != nil {
+				return err
 			}
+			emit(models.Event{Type: models.EventIPRemoved, FQDN: fqdn, IP: ip})
+		}
+		return nil
+	})
+}
 
-			logger.Printf("Update cycle completed. Success: %d/%d, Duration: %v",
-				successCount, len(fqdns), time.Since(startTime))
+// commit выполняет change и записывает его события в журнал и в notifiers
+// одной транзакцией. Подписчикам события раздаются только после фиксации
+func (r *Resolver) commit(ctx context.Context, change func(ctx context.Context, emit func(models.Event)) error) error {
+	var events []models.Event
+	err := r.Transaction(ctx, func(ctx context.Context) error {
+		events = events[:0]
+		if err := change(ctx, func(ev models.Event) { events = append(events, ev) }); err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		for i := range events {
+			events[i].OccurredAt = now
+			events[i].TenantID = models.TenantID(ctx)
+			if err := r.AppendEvent(ctx, &events[i]); err != nil {
+				return fmt.Errorf("store event: %w", err)
+			}
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		if r.feed != nil {
+			if err := r.feed.PublishEvents(ctx, events); err != nil {
+				return fmt.Errorf("publish events: %w", err)
+			}
+		}
+		for _, n := range r.notifiers {
+			if err := n.Notify(ctx, events); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to commit record change", "error", err)
+		return err
+	}
 
-		case <-ctx.Done():
-			logger.Println("Shutting down DNS updater by context signal")
-			return
+	// С общей лентой событие вернется через RunEventFeed, как и на других репликах
+	if r.feed == nil {
+		for _, ev := range events {
+			r.broker.Publish(ev)
 		}
 	}
+	return nil
 }
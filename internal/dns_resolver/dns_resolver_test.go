@@ -2,16 +2,28 @@ package dnsresolver
 
 import (
 	"context"
+	"dns-resolver/internal/models"
+	"dns-resolver/internal/validator"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // MockRepository реализует интерфейс Repository для тестов
 type MockRepository struct {
 	mock.Mock
+	models.Repository
 }
 
 func (m *MockRepository) AddOrUpdate(ctx context.Context, fqdn, ip string) error {
@@ -34,6 +46,61 @@ func (m *MockRepository) GetAllFQDNs(ctx context.Context) ([]string, error) {
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *MockRepository) ListTenants(ctx context.Context) ([]models.Tenant, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Tenant), args.Error(1)
+}
+
+func (m *MockRepository) DeleteRecord(ctx context.Context, fqdn, ip string) error {
+	args := m.Called(ctx, fqdn, ip)
+	return args.Error(0)
+}
+
+func (m *MockRepository) AppendEvent(ctx context.Context, ev *models.Event) error {
+	args := m.Called(ctx, ev)
+	return args.Error(0)
+}
+
+// tenantCtx — контекст запроса арендатора по умолчанию, как после аутентификации
+func tenantCtx() context.Context {
+	return models.WithTenant(context.Background(), models.DefaultTenantID)
+}
+
+type txKey struct{}
+
+// Transaction помечает контекст, чтобы проверить, какие вызовы шли в транзакции
+func (m *MockRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(context.WithValue(ctx, txKey{}, true))
+}
+
+func inTx(ctx context.Context) bool {
+	return ctx.Value(txKey{}) != nil
+}
+
+// recordingNotifier запоминает все полученные события и возвращает err
+type recordingNotifier struct {
+	events []models.Event
+	err    error
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, events []models.Event) error {
+	if !inTx(ctx) {
+		return errors.New("notified outside of transaction")
+	}
+	n.events = append(n.events, events...)
+	return n.err
+}
+
+func staticLookup(ips ...string) func(ctx context.Context, host string) ([]net.IP, error) {
+	return func(ctx context.Context, host string) ([]net.IP, error) {
+		var res []net.IP
+		for _, ip := range ips {
+			res = append(res, net.ParseIP(ip))
+		}
+		return res, nil
+	}
+}
+
 func TestDNSUpdater(t *testing.T) {
 	// Создаем мок репозитория
 	mockRepo := new(MockRepository)
@@ -41,8 +108,11 @@ func TestDNSUpdater(t *testing.T) {
 
 	// Устанавливаем ожидания для мока
 	testFqdns := []string{"example.com", "test.com"}
+	mockRepo.On("ListTenants", mock.Anything).Return([]models.Tenant{{ID: models.DefaultTenantID, Name: "default"}}, nil)
 	mockRepo.On("GetAllFQDNs", mock.Anything).Return(testFqdns, nil)
+	mockRepo.On("GetIPsByFQDN", mock.Anything, mock.Anything).Return([]string{}, nil)
 	mockRepo.On("AddOrUpdate", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("AppendEvent", mock.Anything, mock.Anything).Return(nil)
 
 	// Создаем контекст с таймаутом
 	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
@@ -67,6 +137,7 @@ func TestDNSUpdater_ErrorHandling(t *testing.T) {
 	resolver := NewResolver(mockRepo)
 
 	// Устанавливаем ошибку при получении FQDNs
+	mockRepo.On("ListTenants", mock.Anything).Return([]models.Tenant{{ID: models.DefaultTenantID, Name: "default"}}, nil)
 	mockRepo.On("GetAllFQDNs", mock.Anything).Return([]string{}, assert.AnError)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
@@ -80,4 +151,726 @@ func TestDNSUpdater_ErrorHandling(t *testing.T) {
 
 	// Проверяем что AddOrUpdate не вызывался при ошибке
 	mockRepo.AssertNotCalled(t, "AddOrUpdate", mock.Anything, mock.Anything, mock.Anything)
-}
\ No newline at end of file
+}
+
+func TestResolve_Events(t *testing.T) {
+	mockRepo := new(MockRepository)
+	resolver := NewResolver(mockRepo)
+	resolver.lookupIP = staticLookup("1.1.1.1", "2.2.2.2")
+	notifier := &recordingNotifier{}
+	resolver.AddNotifier(notifier)
+
+	mockRepo.On("GetIPsByFQDN", mock.Anything, "example.com").Return([]string{"1.1.1.1", "3.3.3.3"}, nil)
+	mockRepo.On("AddOrUpdate", mock.Anything, "example.com", mock.Anything).Return(nil)
+	mockRepo.On("DeleteRecord", mock.Anything, "example.com", "3.3.3.3").Return(nil)
+	mockRepo.On("AppendEvent", mock.Anything, mock.Anything).Return(nil)
+
+	ips, err := resolver.Resolve(tenantCtx(), "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.1.1.1", "2.2.2.2"}, ips)
+
+	mockRepo.AssertCalled(t, "DeleteRecord", mock.Anything, "example.com", "3.3.3.3")
+	if assert.Len(t, notifier.events, 2) {
+		assert.Equal(t, models.EventIPAdded, notifier.events[0].Type)
+		assert.Equal(t, "2.2.2.2", notifier.events[0].IP)
+		assert.Equal(t, models.EventIPRemoved, notifier.events[1].Type)
+		assert.Equal(t, "3.3.3.3", notifier.events[1].IP)
+	}
+}
+
+func TestResolve_CommitsAtomically(t *testing.T) {
+	mockRepo := new(MockRepository)
+	resolver := NewResolver(mockRepo)
+	resolver.lookupIP = staticLookup("1.1.1.1")
+	notifier := &recordingNotifier{err: errors.New("outbox unavailable")}
+	resolver.AddNotifier(notifier)
+
+	txCtx := mock.MatchedBy(inTx)
+	mockRepo.On("GetIPsByFQDN", mock.Anything, "example.com").Return([]string{}, nil)
+	mockRepo.On("AddOrUpdate", txCtx, "example.com", "1.1.1.1").Return(nil)
+	mockRepo.On("AppendEvent", txCtx, mock.Anything).Return(nil)
+
+	events, unsubscribe := resolver.Subscribe(models.EventFilter{})
+	defer unsubscribe()
+
+	// Ошибка outbox откатывает изменение: подписчики его не видят
+	_, err := resolver.Resolve(tenantCtx(), "example.com")
+	assert.ErrorContains(t, err, "outbox unavailable")
+	assert.Empty(t, events)
+
+	notifier.err = nil
+	_, err = resolver.Resolve(tenantCtx(), "example.com")
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	t.Run("Storage error is returned", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		resolver := NewResolver(mockRepo)
+		resolver.lookupIP = staticLookup("1.1.1.1")
+		mockRepo.On("GetIPsByFQDN", mock.Anything, "example.com").Return([]string{}, nil)
+		mockRepo.On("AddOrUpdate", mock.Anything, "example.com", "1.1.1.1").Return(models.ErrUnavailable)
+
+		_, err := resolver.Resolve(tenantCtx(), "example.com")
+		assert.ErrorIs(t, err, models.ErrUnavailable)
+		mockRepo.AssertNotCalled(t, "AppendEvent", mock.Anything, mock.Anything)
+	})
+}
+
+func TestResolve_CanonicalizesIDN(t *testing.T) {
+	mockRepo := new(MockRepository)
+	resolver := NewResolver(mockRepo)
+	var looked string
+	resolver.lookupIP = func(ctx context.Context, host string) ([]net.IP, error) {
+		looked = host
+		return []net.IP{net.ParseIP("1.1.1.1")}, nil
+	}
+
+	const aLabel = "xn--e1afmkfd.xn--p1ai"
+	mockRepo.On("GetIPsByFQDN", mock.Anything, aLabel).Return([]string{"1.1.1.1"}, nil)
+	mockRepo.On("AddOrUpdate", mock.Anything, aLabel, "1.1.1.1").Return(nil)
+
+	_, err := resolver.Resolve(tenantCtx(), "Пример.РФ.")
+	assert.NoError(t, err)
+	assert.Equal(t, aLabel, looked)
+	mockRepo.AssertCalled(t, "AddOrUpdate", mock.Anything, aLabel, "1.1.1.1")
+
+	_, err = resolver.Resolve(tenantCtx(), "not a domain")
+	assert.ErrorIs(t, err, validator.ErrInvalidFQDN)
+}
+
+func TestResolve_NXDomain(t *testing.T) {
+	mockRepo := new(MockRepository)
+	resolver := NewResolver(mockRepo)
+	resolver.lookupIP = func(ctx context.Context, host string) ([]net.IP, error) {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	notifier := &recordingNotifier{}
+	resolver.AddNotifier(notifier)
+
+	mockRepo.On("GetIPsByFQDN", mock.Anything, "gone.example.com").Return([]string{"1.1.1.1"}, nil)
+	mockRepo.On("GetIPsByFQDN", mock.Anything, "never.example.com").Return([]string{}, nil)
+	mockRepo.On("AppendEvent", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := resolver.Resolve(tenantCtx(), "gone.example.com")
+	assert.ErrorIs(t, err, ErrNXDomain)
+	_, err = resolver.Resolve(tenantCtx(), "never.example.com")
+	assert.ErrorIs(t, err, ErrNXDomain)
+
+	// Событие отправляется только для уже отслеживаемого домена
+	if assert.Len(t, notifier.events, 1) {
+		assert.Equal(t, models.EventNXDomain, notifier.events[0].Type)
+		assert.Equal(t, "gone.example.com", notifier.events[0].FQDN)
+	}
+	mockRepo.AssertNotCalled(t, "AddOrUpdate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestResolve_PublishesToSubscribers(t *testing.T) {
+	mockRepo := new(MockRepository)
+	resolver := NewResolver(mockRepo)
+	resolver.lookupIP = staticLookup("1.1.1.1")
+
+	mockRepo.On("GetIPsByFQDN", mock.Anything, mock.Anything).Return([]string{}, nil)
+	mockRepo.On("AddOrUpdate", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("AppendEvent", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Event).ID = 7
+	}).Return(nil)
+
+	events, unsubscribe := resolver.Subscribe(models.EventFilter{Patterns: []string{"*.example.com"}})
+	defer unsubscribe()
+
+	_, err := resolver.Resolve(tenantCtx(), "other.org")
+	assert.NoError(t, err)
+	_, err = resolver.Resolve(tenantCtx(), "api.example.com")
+	assert.NoError(t, err)
+
+	ev := <-events
+	assert.Equal(t, models.EventFQDNAdded, ev.Type)
+	assert.Equal(t, "api.example.com", ev.FQDN)
+	assert.Equal(t, uint(7), ev.ID)
+
+	ev = <-events
+	assert.Equal(t, models.EventIPAdded, ev.Type)
+	assert.Equal(t, "1.1.1.1", ev.IP)
+	assert.Empty(t, events)
+}
+
+func TestBroker_DropsSlowSubscriber(t *testing.T) {
+	broker := NewBroker()
+	events, unsubscribe := broker.Subscribe(models.EventFilter{})
+	defer unsubscribe()
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		broker.Publish(models.Event{ID: uint(i), Type: models.EventIPAdded, FQDN: "example.com"})
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+	assert.Equal(t, subscriberBuffer, received)
+}
+
+// memFeed — общая лента событий реплик в памяти; закрытие fail обрывает слушателей
+type memFeed struct {
+	mu        sync.Mutex
+	listeners []chan models.Event
+	fail      chan struct{}
+}
+
+func (f *memFeed) PublishEvents(ctx context.Context, events []models.Event) error {
+	if !inTx(ctx) {
+		return errors.New("published outside of transaction")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, ch := range f.listeners {
+		for _, ev := range events {
+			ch <- ev
+		}
+	}
+	return nil
+}
+
+func (f *memFeed) ListenEvents(ctx context.Context, fn func(models.Event)) error {
+	ch := make(chan models.Event, subscriberBuffer)
+	f.mu.Lock()
+	f.listeners = append(f.listeners, ch)
+	f.mu.Unlock()
+	for {
+		select {
+		case ev := <-ch:
+			fn(ev)
+		case <-f.fail:
+			return errors.New("connection reset")
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (f *memFeed) listening() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.listeners)
+}
+
+func TestEventFeed_ReachesOtherReplicas(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetIPsByFQDN", mock.Anything, "example.com").Return([]string{}, nil)
+	mockRepo.On("AddOrUpdate", mock.Anything, "example.com", "1.1.1.1").Return(nil)
+	mockRepo.On("AppendEvent", mock.Anything, mock.Anything).Return(nil)
+
+	feed := &memFeed{fail: make(chan struct{})}
+	writer, reader := NewResolver(mockRepo), NewResolver(mockRepo)
+	writer.lookupIP = staticLookup("1.1.1.1")
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for _, r := range []*Resolver{writer, reader} {
+		r.UseEventFeed(feed)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.RunEventFeed(ctx)
+		}()
+	}
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	require.Eventually(t, func() bool { return feed.listening() == 2 }, time.Second, 5*time.Millisecond)
+
+	// Подписчик реплики, которая не резолвила имя, тоже видит изменение
+	filter := models.EventFilter{Types: []models.EventType{models.EventIPAdded}}
+	events, unsubscribe := reader.Subscribe(filter)
+	defer unsubscribe()
+	own, unsubscribeOwn := writer.Subscribe(filter)
+	defer unsubscribeOwn()
+	_, err := writer.Resolve(tenantCtx(), "example.com")
+	require.NoError(t, err)
+
+	for _, ch := range []<-chan models.Event{events, own} {
+		select {
+		case ev := <-ch:
+			assert.Equal(t, models.EventIPAdded, ev.Type)
+			assert.Equal(t, "1.1.1.1", ev.IP)
+		case <-time.After(time.Second):
+			t.Fatal("event not delivered")
+		}
+	}
+	assert.Empty(t, own, "writer publishes only through the feed")
+
+	// Обрыв ленты отключает подписчиков, чтобы они догнали журнал
+	close(feed.fail)
+	require.Eventually(t, func() bool {
+		select {
+		case _, ok := <-events:
+			return !ok
+		default:
+			return false
+		}
+	}, time.Second, 5*time.Millisecond)
+}
+
+// prunerFunc — EventPruner из функции
+type prunerFunc func(ctx context.Context, before time.Time) (int64, error)
+
+func (f prunerFunc) DeleteEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	return f(ctx, before)
+}
+
+func TestPruneEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var cutoffs []time.Time
+	pruner := prunerFunc(func(ctx context.Context, before time.Time) (int64, error) {
+		cutoffs = append(cutoffs, before)
+		if len(cutoffs) == 2 {
+			cancel()
+		}
+		return 1, nil
+	})
+
+	// Первое удаление — сразу при запуске, дальше по интервалу
+	PruneEvents(ctx, pruner, time.Hour, 10*time.Millisecond)
+	require.Len(t, cutoffs, 2)
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), cutoffs[0], time.Second)
+}
+
+func TestDNSUpdater_ScopesByTenant(t *testing.T) {
+	mockRepo := new(MockRepository)
+	resolver := NewResolver(mockRepo)
+	resolver.lookupIP = staticLookup("1.1.1.1")
+
+	mockRepo.On("ListTenants", mock.Anything).Return([]models.Tenant{{ID: 1, Name: "default"}, {ID: 2, Name: "search"}}, nil)
+	isTenant := func(id uint) interface{} {
+		return mock.MatchedBy(func(ctx context.Context) bool { return models.TenantID(ctx) == id })
+	}
+	mockRepo.On("GetAllFQDNs", isTenant(1)).Return([]string{"a.example.com"}, nil)
+	mockRepo.On("GetAllFQDNs", isTenant(2)).Return([]string{"b.example.com"}, nil)
+	mockRepo.On("GetIPsByFQDN", mock.Anything, mock.Anything).Return([]string{"1.1.1.1"}, nil)
+	mockRepo.On("AddOrUpdate", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 80*time.Millisecond)
+	defer cancel()
+	resolver.DNSUpdater(ctx, 50*time.Millisecond)
+
+	mockRepo.AssertCalled(t, "AddOrUpdate", isTenant(1), "a.example.com", "1.1.1.1")
+	mockRepo.AssertCalled(t, "AddOrUpdate", isTenant(2), "b.example.com", "1.1.1.1")
+	mockRepo.AssertNotCalled(t, "AddOrUpdate", isTenant(1), "b.example.com", mock.Anything)
+}
+
+func TestRemove(t *testing.T) {
+	mockRepo := new(MockRepository)
+	resolver := NewResolver(mockRepo)
+	notifier := &recordingNotifier{}
+	resolver.AddNotifier(notifier)
+
+	mockRepo.On("GetIPsByFQDN", mock.Anything, "example.com").Return([]string{"1.1.1.1", "2.2.2.2"}, nil)
+	mockRepo.On("GetIPsByFQDN", mock.Anything, "unknown.example").Return([]string{}, nil)
+	mockRepo.On("DeleteRecord", mock.Anything, "example.com", mock.Anything).Return(nil)
+	mockRepo.On("AppendEvent", mock.Anything, mock.Anything).Return(nil)
+
+	assert.NoError(t, resolver.Remove(tenantCtx(), "Example.com."))
+	mockRepo.AssertNumberOfCalls(t, "DeleteRecord", 2)
+	if assert.Len(t, notifier.events, 2) {
+		assert.Equal(t, models.EventIPRemoved, notifier.events[0].Type)
+		assert.Equal(t, "1.1.1.1", notifier.events[0].IP)
+	}
+
+	assert.ErrorIs(t, resolver.Remove(tenantCtx(), "unknown.example"), models.ErrNotFound)
+}
+
+func TestUpdater_Control(t *testing.T) {
+	mockRepo := new(MockRepository)
+	resolver := NewResolver(mockRepo)
+	resolver.lookupIP = staticLookup("1.1.1.1")
+
+	mockRepo.On("ListTenants", mock.Anything).Return([]models.Tenant{{ID: models.DefaultTenantID, Name: "default"}}, nil)
+	mockRepo.On("GetAllFQDNs", mock.Anything).Return([]string{"example.com", "test.com"}, nil)
+	mockRepo.On("GetIPsByFQDN", mock.Anything, mock.Anything).Return([]string{"1.1.1.1"}, nil)
+	mockRepo.On("AddOrUpdate", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	updater := NewUpdater(resolver, time.Hour)
+	assert.ErrorIs(t, updater.SetInterval(context.Background(), time.Millisecond), ErrInvalidInterval)
+	assert.ErrorIs(t, updater.SetInterval(context.Background(), 48*time.Hour), ErrInvalidInterval)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		updater.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// На паузе плановые проходы не выполняются, а ручной — выполняется
+	require.NoError(t, updater.Pause(ctx))
+	require.NoError(t, updater.SetInterval(ctx, time.Second))
+	assert.True(t, updater.Status().NextRun.IsZero())
+	time.Sleep(1200 * time.Millisecond)
+	assert.Nil(t, updater.Status().Last)
+
+	require.NoError(t, updater.RefreshAll(ctx))
+	require.Eventually(t, func() bool { return updater.Status().Last != nil }, time.Second, 10*time.Millisecond)
+	last := updater.Status().Last
+	assert.Equal(t, TriggerManual, last.Trigger)
+	assert.Equal(t, 2, last.Total)
+	assert.Equal(t, 2, last.Succeeded)
+	assert.False(t, last.FinishedAt.IsZero())
+
+	// После снятия паузы проход идет по новому интервалу
+	require.NoError(t, updater.Resume(ctx))
+	status := updater.Status()
+	assert.False(t, status.Paused)
+	assert.WithinDuration(t, last.StartedAt.Add(time.Second), status.NextRun, time.Millisecond)
+	require.Eventually(t, func() bool {
+		last := updater.Status().Last
+		return last != nil && last.Trigger == TriggerSchedule
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+// memSettings — общие настройки обновления в памяти
+type memSettings struct {
+	mu       sync.Mutex
+	settings models.UpdaterSettings
+}
+
+func (m *memSettings) GetUpdaterSettings(ctx context.Context) (models.UpdaterSettings, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.settings, nil
+}
+
+func (m *memSettings) UpdateUpdaterSettings(ctx context.Context, update models.UpdaterSettingsUpdate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if update.Interval != nil {
+		m.settings.IntervalSeconds = update.Interval.Seconds()
+	}
+	if update.Paused != nil {
+		m.settings.Paused = *update.Paused
+	}
+	m.settings.RefreshRequested = m.settings.RefreshRequested || update.RequestRefresh
+	return nil
+}
+
+func (m *memSettings) TakeUpdaterRefresh(ctx context.Context) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	taken := m.settings.RefreshRequested
+	m.settings.RefreshRequested = false
+	return taken, nil
+}
+
+func TestUpdater_SharedSettings(t *testing.T) {
+	mockRepo := new(MockRepository)
+	resolver := NewResolver(mockRepo)
+	resolver.lookupIP = staticLookup("1.1.1.1")
+
+	mockRepo.On("ListTenants", mock.Anything).Return([]models.Tenant{{ID: models.DefaultTenantID, Name: "default"}}, nil)
+	mockRepo.On("GetAllFQDNs", mock.Anything).Return([]string{"example.com"}, nil)
+	mockRepo.On("GetIPsByFQDN", mock.Anything, mock.Anything).Return([]string{"1.1.1.1"}, nil)
+	mockRepo.On("AddOrUpdate", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	store := &memSettings{}
+	opts := []UpdaterOption{WithSettingsStore(store), WithSettingsPoll(20 * time.Millisecond)}
+	leader := NewUpdater(resolver, time.Hour, opts...)
+	follower := NewUpdater(resolver, time.Hour, opts...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		leader.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		follower.Watch(ctx)
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	// Изменения, принятые репликой без Run, применяет лидер
+	require.NoError(t, follower.Pause(ctx))
+	require.NoError(t, follower.SetInterval(ctx, 2*time.Minute))
+	require.Eventually(t, func() bool {
+		s := leader.Status()
+		return s.Paused && s.Interval == 2*time.Minute
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, follower.RefreshAll(ctx))
+	require.Eventually(t, func() bool { return leader.Status().Last != nil }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, TriggerManual, leader.Status().Last.Trigger)
+	assert.Nil(t, follower.Status().Last)
+	settings, _ := store.GetUpdaterSettings(ctx)
+	assert.False(t, settings.RefreshRequested)
+
+	// Реплики без Run тоже видят изменения с других реплик
+	require.NoError(t, leader.Resume(ctx))
+	require.Eventually(t, func() bool { return !follower.Status().Paused }, time.Second, 10*time.Millisecond)
+}
+
+// memQueue — очередь перепроверки в памяти с той же семантикой аренды, что в Postgres
+type memQueue struct {
+	mu   sync.Mutex
+	jobs []*models.RefreshJob
+}
+
+func (q *memQueue) add(fqdns ...string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, fqdn := range fqdns {
+		q.jobs = append(q.jobs, &models.RefreshJob{ID: uint(len(q.jobs) + 1), TenantID: models.DefaultTenantID, FQDN: fqdn, DueAt: time.Now()})
+	}
+}
+
+func (q *memQueue) SyncRefreshJobs(ctx context.Context, interval time.Duration) (int64, int64, error) {
+	return 0, 0, nil
+}
+
+func (q *memQueue) ClaimRefreshJobs(ctx context.Context, owner string, limit int, visibility time.Duration) ([]models.RefreshJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	var claimed []models.RefreshJob
+	for _, job := range q.jobs {
+		if len(claimed) == limit {
+			break
+		}
+		if job.DueAt.After(now) || (job.LeaseUntil != nil && job.LeaseUntil.After(now)) {
+			continue
+		}
+		until := now.Add(visibility)
+		job.LeaseOwner, job.LeaseUntil = owner, &until
+		job.Claims++
+		claimed = append(claimed, *job)
+	}
+	return claimed, nil
+}
+
+func (q *memQueue) CompleteRefreshJob(ctx context.Context, claimed *models.RefreshJob, after time.Duration, jobErr error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, job := range q.jobs {
+		if job.ID != claimed.ID {
+			continue
+		}
+		if job.Claims != claimed.Claims {
+			return models.ErrLeaseLost
+		}
+		job.DueAt = time.Now().Add(after)
+		job.LeaseOwner, job.LeaseUntil = "", nil
+		return nil
+	}
+	return models.ErrLeaseLost
+}
+
+func (q *memQueue) RequeueRefreshJobs(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for _, job := range q.jobs {
+		if job.DueAt.After(now) {
+			job.DueAt = now
+			n++
+		}
+	}
+	return n, nil
+}
+
+// countingLookup считает запросы к DNS по каждому имени
+func countingLookup(mu *sync.Mutex, counts map[string]int) func(ctx context.Context, host string) ([]net.IP, error) {
+	return func(ctx context.Context, host string) ([]net.IP, error) {
+		mu.Lock()
+		counts[host]++
+		mu.Unlock()
+		return []net.IP{net.ParseIP("1.1.1.1")}, nil
+	}
+}
+
+func TestWorker_SharesQueue(t *testing.T) {
+	mockRepo := new(MockRepository)
+	resolver := NewResolver(mockRepo)
+	var mu sync.Mutex
+	counts := map[string]int{}
+	resolver.lookupIP = countingLookup(&mu, counts)
+	mockRepo.On("GetIPsByFQDN", mock.Anything, mock.Anything).Return([]string{"1.1.1.1"}, nil)
+	mockRepo.On("AddOrUpdate", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	queue := &memQueue{}
+	var fqdns []string
+	for i := range 200 {
+		fqdns = append(fqdns, fmt.Sprintf("host%d.example.com", i))
+	}
+	queue.add(fqdns...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for i := range 4 {
+		worker := NewWorker(resolver, queue, fmt.Sprintf("replica-%d", i), time.Hour,
+			WithBatchSize(5), WithPollInterval(5*time.Millisecond))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker.Run(ctx)
+		}()
+	}
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(counts) == len(fqdns)
+	}, 5*time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	// Каждое задание перепроверено ровно один раз за период
+	for _, fqdn := range fqdns {
+		assert.Equal(t, 1, counts[fqdn], fqdn)
+	}
+}
+
+func TestWorker_ReclaimsExpiredLease(t *testing.T) {
+	mockRepo := new(MockRepository)
+	resolver := NewResolver(mockRepo)
+	var mu sync.Mutex
+	counts := map[string]int{}
+	resolver.lookupIP = countingLookup(&mu, counts)
+	mockRepo.On("GetIPsByFQDN", mock.Anything, mock.Anything).Return([]string{"1.1.1.1"}, nil)
+	mockRepo.On("AddOrUpdate", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	queue := &memQueue{}
+	queue.add("a.example.com", "b.example.com")
+
+	// Реплика взяла задания и упала, не завершив их
+	ctx := context.Background()
+	stale, err := queue.ClaimRefreshJobs(ctx, "crashed", 10, 50*time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, stale, 2)
+
+	worker := NewWorker(resolver, queue, "survivor", time.Hour)
+	n, err := worker.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "leased jobs are invisible")
+
+	time.Sleep(60 * time.Millisecond)
+	n, err = worker.ProcessDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, map[string]int{"a.example.com": 1, "b.example.com": 1}, counts)
+
+	// Запоздавший результат упавшей реплики отбрасывается
+	assert.ErrorIs(t, queue.CompleteRefreshJob(ctx, &stale[0], time.Hour, nil), models.ErrLeaseLost)
+}
+
+func TestWorker_SharedSettings(t *testing.T) {
+	mockRepo := new(MockRepository)
+	resolver := NewResolver(mockRepo)
+	var mu sync.Mutex
+	counts := map[string]int{}
+	resolver.lookupIP = countingLookup(&mu, counts)
+	mockRepo.On("GetIPsByFQDN", mock.Anything, mock.Anything).Return([]string{"1.1.1.1"}, nil)
+	mockRepo.On("AddOrUpdate", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	resolved := func(fqdn string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return counts[fqdn]
+	}
+
+	queue := &memQueue{}
+	queue.add("a.example.com")
+	store := &memSettings{}
+	opts := []WorkerOption{WithWorkerSettings(store), WithWorkerSettingsPoll(20 * time.Millisecond), WithPollInterval(5 * time.Millisecond)}
+	worker := NewWorker(resolver, queue, "replica-0", time.Hour, opts...)
+	// Реплика, принимающая запросы API, сама очередь не разбирает
+	other := NewWorker(resolver, queue, "replica-1", time.Hour, opts...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		worker.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	require.Eventually(t, func() bool { return resolved("a.example.com") == 1 }, time.Second, 5*time.Millisecond)
+
+	assert.ErrorIs(t, other.SetInterval(ctx, time.Millisecond), ErrInvalidInterval)
+	require.NoError(t, other.SetInterval(ctx, 2*time.Minute))
+	require.NoError(t, other.Pause(ctx))
+	require.Eventually(t, func() bool {
+		s := worker.Status()
+		return s.Active && s.Paused && s.Interval == 2*time.Minute
+	}, time.Second, 5*time.Millisecond)
+
+	// На паузе задания, назначенные на сейчас, ждут ее снятия
+	require.NoError(t, other.RefreshAll(ctx))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, resolved("a.example.com"))
+
+	require.NoError(t, other.Resume(ctx))
+	require.Eventually(t, func() bool { return resolved("a.example.com") == 2 }, time.Second, 5*time.Millisecond)
+	assert.False(t, other.Status().Active)
+}
+
+func TestTracedLookup(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	answers := map[string]error{}
+	lookup := tracedLookup(func(ctx context.Context, network, host string) ([]net.IP, error) {
+		if err := answers[network]; err != nil {
+			return nil, err
+		}
+		if network == "ip4" {
+			return []net.IP{net.ParseIP("1.1.1.1")}, nil
+		}
+		return []net.IP{net.ParseIP("2001:db8::1")}, nil
+	})
+	spanAttrs := func() map[string]map[string]string {
+		res := map[string]map[string]string{}
+		for _, span := range recorder.Ended() {
+			attrs := map[string]string{}
+			for _, kv := range span.Attributes() {
+				attrs[string(kv.Key)] = kv.Value.Emit()
+			}
+			res[span.Name()] = attrs
+		}
+		return res
+	}
+	notFound := &net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true}
+	timeout := &net.DNSError{Err: "i/o timeout", Name: "example.com", IsTimeout: true}
+
+	// Нет AAAA — не ошибка, если есть A
+	answers["ip6"] = notFound
+	ips, err := lookup(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []net.IP{net.ParseIP("1.1.1.1")}, ips)
+
+	spans := spanAttrs()
+	require.Len(t, spans, 2)
+	assert.Equal(t, map[string]string{"dns.qname": "example.com", "dns.qtype": "A", "dns.answers": "1", "dns.rcode": "NOERROR"}, spans["dns.query A"])
+	assert.Equal(t, "NXDOMAIN", spans["dns.query AAAA"]["dns.rcode"])
+
+	// Если не ответили оба запроса, возвращается сбой, а не отсутствие записей
+	answers["ip4"] = timeout
+	_, err = lookup(context.Background(), "example.com")
+	assert.ErrorIs(t, newLookupError("example.com", err), ErrTimeout)
+	for _, span := range recorder.Ended()[2:] {
+		if span.Name() == "dns.query A" {
+			assert.Equal(t, codes.Error, span.Status().Code)
+		}
+	}
+	assert.NotContains(t, spanAttrs()["dns.query A"], "dns.rcode", "timeout has no response code")
+}
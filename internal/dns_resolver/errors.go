@@ -0,0 +1,45 @@
+package dnsresolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+var (
+	ErrNXDomain     = errors.New("domain does not exist")
+	ErrTimeout      = errors.New("DNS lookup timed out")
+	ErrLookupFailed = errors.New("DNS lookup failed")
+
+	// ErrInvalidInterval — интервал обновления вне допустимых границ
+	ErrInvalidInterval = errors.New("invalid update interval")
+)
+
+// LookupError — сбой DNS-запроса. Reason — одна из ошибок ErrNXDomain,
+// ErrTimeout, ErrLookupFailed, по ней вызывающий код выбирает реакцию
+type LookupError struct {
+	FQDN   string
+	Reason error
+	Err    error
+}
+
+func (e *LookupError) Error() string {
+	return fmt.Sprintf("%s: %v: %v", e.FQDN, e.Reason, e.Err)
+}
+
+func (e *LookupError) Unwrap() []error {
+	return []error{e.Reason, e.Err}
+}
+
+func newLookupError(fqdn string, err error) *LookupError {
+	reason := ErrLookupFailed
+	var dnsErr *net.DNSError
+	switch {
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		reason = ErrNXDomain
+	case errors.As(err, &dnsErr) && dnsErr.IsTimeout, errors.Is(err, context.DeadlineExceeded):
+		reason = ErrTimeout
+	}
+	return &LookupError{FQDN: fqdn, Reason: reason, Err: err}
+}
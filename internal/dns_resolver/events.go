@@ -0,0 +1,219 @@
+package dnsresolver
+
+import (
+	"context"
+	"dns-resolver/internal/logging"
+	"dns-resolver/internal/models"
+	"sync"
+	"time"
+)
+
+const subscriberBuffer = 64
+
+type subscriber struct {
+	ch     chan models.Event
+	filter models.EventFilter
+}
+
+// Broker раздает события живым подписчикам (SSE, WebSocket).
+// Медленный подписчик отключается: он может переподключиться и догнать
+// пропущенное из журнала событий по последнему полученному ID
+type Broker struct {
+	mu     sync.Mutex
+	nextID int
+	subs   map[int]*subscriber
+}
+
+func NewBroker() *Broker {
+	return &Broker{subs: make(map[int]*subscriber)}
+}
+
+// Subscribe возвращает канал событий и функцию отписки.
+// Канал закрывается при отписке или переполнении буфера
+func (b *Broker) Subscribe(filter models.EventFilter) (<-chan models.Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	sub := &subscriber{ch: make(chan models.Event, subscriberBuffer), filter: filter}
+	b.subs[id] = sub
+
+	return sub.ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[id]; ok {
+			delete(b.subs, id)
+			close(sub.ch)
+		}
+	}
+}
+
+// Reset отключает всех подписчиков: они переподключатся и догонят
+// пропущенное из журнала
+func (b *Broker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for id, sub := range b.subs {
+		delete(b.subs, id)
+		close(sub.ch)
+	}
+}
+
+func (b *Broker) Publish(ev models.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for id, sub := range b.subs {
+		if !sub.filter.Match(ev) {
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+			delete(b.subs, id)
+			close(sub.ch)
+		}
+	}
+}
+
+const (
+	replayPage      = 500
+	streamHeartbeat = 15 * time.Second
+)
+
+// Stream догоняет журнал после lastID (если resume) и затем отдает
+// живые события до отмены контекста или отключения подписчика брокером.
+// heartbeat, если задан, вызывается в паузах, чтобы держать соединение
+func (r *Resolver) Stream(ctx context.Context, filter models.EventFilter, lastID uint, resume bool,
+	send func(models.Event) error, heartbeat func() error) error {
+	// Подписываемся до чтения журнала, чтобы не потерять события между ними
+	events, unsubscribe := r.Subscribe(filter)
+	defer unsubscribe()
+
+	if resume {
+		for {
+			page, err := r.ListEventsSince(ctx, lastID, replayPage)
+			if err != nil {
+				return err
+			}
+			for _, ev := range page {
+				lastID = ev.ID
+				if !filter.Match(ev) {
+					continue
+				}
+				if err := send(ev); err != nil {
+					return err
+				}
+			}
+			if len(page) < replayPage {
+				break
+			}
+		}
+	}
+
+	var tick <-chan time.Time
+	if heartbeat != nil {
+		ticker := time.NewTicker(streamHeartbeat)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				// Подписчик не успевал читать: клиент переподключится с последним ID
+				return nil
+			}
+			if ev.ID != 0 && ev.ID <= lastID {
+				continue
+			}
+			if err := send(ev); err != nil {
+				return err
+			}
+			if ev.ID != 0 {
+				lastID = ev.ID
+			}
+		case <-tick:
+			if err := heartbeat(); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// EventFeed разносит зафиксированные события по всем репликам, чтобы живые
+// подписчики любой из них видели изменения, сделанные другими
+type EventFeed interface {
+	// PublishEvents вызывается в транзакции изменения: события уходят только после фиксации
+	PublishEvents(ctx context.Context, events []models.Event) error
+	// ListenEvents передает fn события всех реплик до отмены ctx или обрыва соединения
+	ListenEvents(ctx context.Context, fn func(models.Event)) error
+}
+
+const feedReconnectDelay = 2 * time.Second
+
+// UseEventFeed раздает события подписчикам через feed, а не только в этом
+// процессе. Вызывать до запуска сервиса; события доставляет RunEventFeed
+func (r *Resolver) UseEventFeed(feed EventFeed) {
+	r.feed = feed
+}
+
+// RunEventFeed передает подписчикам события всех реплик до отмены ctx.
+// Пока соединение восстанавливается, события не приходят, поэтому при обрыве
+// подписчики отключаются и догоняют пропущенное из журнала
+func (r *Resolver) RunEventFeed(ctx context.Context) {
+	for {
+		err := r.feed.ListenEvents(ctx, r.broker.Publish)
+		if ctx.Err() != nil {
+			return
+		}
+		r.logger.ErrorContext(ctx, "Event feed interrupted", "error", err)
+		r.broker.Reset()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(feedReconnectDelay):
+		}
+	}
+}
+
+const (
+	DefaultEventRetention = 7 * 24 * time.Hour
+	DefaultPruneInterval  = time.Hour
+)
+
+// EventPruner удаляет из журнала старые события
+type EventPruner interface {
+	DeleteEventsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// PruneEvents раз в interval удаляет события старше retention до отмены ctx.
+// Клиент, отставший больше чем на retention, продолжит с самого старого
+// оставшегося события. Удаление идемпотентно, его можно запускать на всех репликах
+func PruneEvents(ctx context.Context, pruner EventPruner, retention, interval time.Duration) {
+	logger := logging.Component("event_log")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := pruner.DeleteEventsBefore(ctx, time.Now().Add(-retention))
+		switch {
+		case err != nil && ctx.Err() == nil:
+			logger.ErrorContext(ctx, "Failed to prune events", "error", err)
+		case deleted > 0:
+			logger.InfoContext(ctx, "Pruned old events", "deleted", deleted, "retention", retention)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package dnsresolver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("dns-resolver/internal/dns_resolver")
+
+// dnsQueries — запросы, из которых складывается поиск адресов имени
+var dnsQueries = []struct {
+	qtype   string
+	network string
+}{
+	{"A", "ip4"},
+	{"AAAA", "ip6"},
+}
+
+// tracedLookup ищет адреса отдельными запросами A и AAAA, каждый в своем спане.
+// Нет записей одного типа — не ошибка, если нашлись записи другого
+func tracedLookup(lookup func(ctx context.Context, network, host string) ([]net.IP, error)) func(ctx context.Context, host string) ([]net.IP, error) {
+	return func(ctx context.Context, host string) ([]net.IP, error) {
+		type result struct {
+			ips []net.IP
+			err error
+		}
+		results := make([]result, len(dnsQueries))
+
+		var wg sync.WaitGroup
+		for i, q := range dnsQueries {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ips, err := query(ctx, lookup, q.qtype, q.network, host)
+				results[i] = result{ips, err}
+			}()
+		}
+		wg.Wait()
+
+		var ips []net.IP
+		var err error
+		for _, r := range results {
+			ips = append(ips, r.ips...)
+			// Если не ответили оба, сбой важнее отсутствия записей
+			if r.err != nil && (err == nil || isNotFound(err) && !isNotFound(r.err)) {
+				err = r.err
+			}
+		}
+		if len(ips) > 0 {
+			return ips, nil
+		}
+		return nil, err
+	}
+}
+
+func query(ctx context.Context, lookup func(ctx context.Context, network, host string) ([]net.IP, error), qtype, network, host string) ([]net.IP, error) {
+	ctx, span := tracer.Start(ctx, "dns.query "+qtype,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("dns.qname", host), attribute.String("dns.qtype", qtype)),
+	)
+	defer span.End()
+
+	ips, err := lookup(ctx, network, host)
+	span.SetAttributes(attribute.Int("dns.answers", len(ips)))
+	if rcode := responseCode(err); rcode != "" {
+		span.SetAttributes(attribute.String("dns.rcode", rcode))
+	}
+	if err != nil && !isNotFound(err) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return ips, err
+}
+
+// responseCode восстанавливает код ответа по ошибке net.Resolver. Отсутствие
+// записей нужного типа он тоже сообщает как «no such host», поэтому NXDOMAIN
+// здесь включает и NODATA. Без ответа (таймаут, отмена) кода нет
+func responseCode(err error) string {
+	var dnsErr *net.DNSError
+	switch {
+	case err == nil:
+		return "NOERROR"
+	case !errors.As(err, &dnsErr), dnsErr.IsTimeout:
+		return ""
+	case dnsErr.IsNotFound:
+		return "NXDOMAIN"
+	default:
+		return "SERVFAIL"
+	}
+}
+
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
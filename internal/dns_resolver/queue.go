@@ -0,0 +1,311 @@
+package dnsresolver
+
+import (
+	"context"
+	"dns-resolver/internal/logging"
+	"dns-resolver/internal/models"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	DefaultRefreshBatch      = 10
+	DefaultVisibilityTimeout = 2 * time.Minute
+	DefaultPollInterval      = time.Second
+	DefaultSyncInterval      = 30 * time.Second
+)
+
+// RefreshQueue — очередь перепроверки FQDN, общая для реплик
+type RefreshQueue interface {
+	SyncRefreshJobs(ctx context.Context, interval time.Duration) (added, removed int64, err error)
+	ClaimRefreshJobs(ctx context.Context, owner string, limit int, visibility time.Duration) ([]models.RefreshJob, error)
+	CompleteRefreshJob(ctx context.Context, job *models.RefreshJob, after time.Duration, jobErr error) error
+	// RequeueRefreshJobs делает все задания подошедшими; возвращает их число
+	RequeueRefreshJobs(ctx context.Context) (int64, error)
+}
+
+// Worker перепроверяет FQDN из общей очереди. Его запускает каждая реплика,
+// и задания делятся между ними: одно задание в каждый момент у одного обработчика.
+// С SettingsStore интервал и пауза общие для реплик, как у Updater
+type Worker struct {
+	resolver *Resolver
+	queue    RefreshQueue
+	owner    string
+	logger   *slog.Logger
+	store    SettingsStore
+	// defaultInterval действует, пока интервал не задан в хранилище
+	defaultInterval time.Duration
+
+	batch        int
+	visibility   time.Duration
+	pollInterval time.Duration
+	syncInterval time.Duration
+	settingsPoll time.Duration
+
+	mu       sync.Mutex
+	active   bool
+	interval time.Duration
+	paused   bool
+}
+
+type WorkerOption func(*Worker)
+
+// WithBatchSize задает, сколько заданий обработчик берет за раз
+func WithBatchSize(n int) WorkerOption {
+	return func(w *Worker) {
+		w.batch = n
+	}
+}
+
+// WithVisibilityTimeout задает срок аренды пачки заданий. Если обработчик
+// не успел за это время, оставшиеся задания достаются другим
+func WithVisibilityTimeout(d time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.visibility = d
+	}
+}
+
+// WithPollInterval задает паузу между опросами пустой очереди
+func WithPollInterval(d time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.pollInterval = d
+	}
+}
+
+// WithSyncInterval задает, как часто Schedule сверяет очередь с записями
+func WithSyncInterval(d time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.syncInterval = d
+	}
+}
+
+// WithWorkerSettings хранит интервал и паузу в store: их можно менять
+// через любую реплику, и все обработчики подхватят изменение
+func WithWorkerSettings(store SettingsStore) WorkerOption {
+	return func(w *Worker) {
+		w.store = store
+	}
+}
+
+// WithWorkerSettingsPoll задает, как часто перечитываются общие настройки
+func WithWorkerSettingsPoll(d time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.settingsPoll = d
+	}
+}
+
+// NewWorker создает обработчик; owner должен быть уникален среди реплик,
+// interval — период перепроверки каждого FQDN
+func NewWorker(resolver *Resolver, queue RefreshQueue, owner string, interval time.Duration, opts ...WorkerOption) *Worker {
+	w := &Worker{
+		resolver:        resolver,
+		queue:           queue,
+		owner:           owner,
+		logger:          logging.Component("refresh_worker"),
+		defaultInterval: interval,
+		batch:           DefaultRefreshBatch,
+		visibility:      DefaultVisibilityTimeout,
+		pollInterval:    DefaultPollInterval,
+		syncInterval:    DefaultSyncInterval,
+		settingsPoll:    DefaultSettingsPoll,
+		interval:        interval,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Run разбирает очередь до отмены ctx. Пока задания выдаются полными
+// пачками, следующая берется без паузы. На паузе задания не берутся
+func (w *Worker) Run(ctx context.Context) {
+	w.mu.Lock()
+	w.active = true
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		w.active = false
+		w.mu.Unlock()
+	}()
+
+	w.sync(ctx)
+	synced := time.Now()
+	w.logger.InfoContext(ctx, "Refresh worker started", "owner", w.owner, "interval", w.Interval())
+	for {
+		if w.store != nil && time.Since(synced) >= w.settingsPoll {
+			w.sync(ctx)
+			synced = time.Now()
+		}
+
+		if !w.Status().Paused {
+			n, err := w.ProcessDue(ctx)
+			if err != nil && ctx.Err() == nil {
+				w.logger.ErrorContext(ctx, "Failed to claim refresh jobs", "error", err)
+			}
+			if n == w.batch && err == nil {
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			w.logger.InfoContext(ctx, "Refresh worker stopped")
+			return
+		case <-time.After(w.pollInterval):
+		}
+	}
+}
+
+// sync применяет общие настройки из хранилища
+func (w *Worker) sync(ctx context.Context) {
+	if w.store == nil {
+		return
+	}
+
+	settings, err := w.store.GetUpdaterSettings(ctx)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "Failed to load updater settings", "error", err)
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.interval = w.defaultInterval
+	if settings.IntervalSeconds > 0 {
+		w.interval = time.Duration(settings.IntervalSeconds * float64(time.Second))
+	}
+	w.paused = settings.Paused
+}
+
+// apply сохраняет изменение в хранилище и применяет его к этой реплике;
+// остальные подхватят его при следующем чтении настроек
+func (w *Worker) apply(ctx context.Context, update models.UpdaterSettingsUpdate) error {
+	if w.store != nil {
+		if err := w.store.UpdateUpdaterSettings(ctx, update); err != nil {
+			return err
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if update.Interval != nil {
+		w.interval = *update.Interval
+	}
+	if update.Paused != nil {
+		w.paused = *update.Paused
+	}
+	return nil
+}
+
+// Pause останавливает выдачу заданий на всех репликах. Уже выданные
+// задания дорабатываются
+func (w *Worker) Pause(ctx context.Context) error {
+	paused := true
+	return w.apply(ctx, models.UpdaterSettingsUpdate{Paused: &paused})
+}
+
+func (w *Worker) Resume(ctx context.Context) error {
+	paused := false
+	return w.apply(ctx, models.UpdaterSettingsUpdate{Paused: &paused})
+}
+
+// SetInterval меняет период перепроверки. Новый интервал действует
+// со следующего завершения каждого задания
+func (w *Worker) SetInterval(ctx context.Context, interval time.Duration) error {
+	if err := checkInterval(interval); err != nil {
+		return err
+	}
+	return w.apply(ctx, models.UpdaterSettingsUpdate{Interval: &interval})
+}
+
+// RefreshAll назначает перепроверку всех FQDN на сейчас. На паузе
+// задания дождутся ее снятия
+func (w *Worker) RefreshAll(ctx context.Context) error {
+	n, err := w.queue.RequeueRefreshJobs(ctx)
+	if err != nil {
+		return err
+	}
+	w.logger.InfoContext(ctx, "Refresh jobs requeued", "jobs", n)
+	return nil
+}
+
+func (w *Worker) Interval() time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.interval
+}
+
+// Status показывает настройки очереди. Проходов у очереди нет, поэтому
+// NextRun и сведения о проходах пусты
+func (w *Worker) Status() UpdaterStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return UpdaterStatus{Active: w.active, Interval: w.interval, Paused: w.paused}
+}
+
+// ProcessDue берет одну пачку подошедших заданий и перепроверяет их.
+// Возвращает число выданных заданий
+func (w *Worker) ProcessDue(ctx context.Context) (int, error) {
+	// Срок аренды по своим часам, с запасом на время запроса
+	deadline := time.Now().Add(w.visibility)
+	jobs, err := w.queue.ClaimRefreshJobs(ctx, w.owner, w.batch, w.visibility)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range jobs {
+		// Задания с истекшей арендой, возможно, уже у другого обработчика
+		if ctx.Err() != nil || time.Now().After(deadline) {
+			break
+		}
+		w.refresh(ctx, &jobs[i])
+	}
+	return len(jobs), nil
+}
+
+func (w *Worker) refresh(ctx context.Context, job *models.RefreshJob) {
+	ctx, span := tracer.Start(models.WithTenant(ctx, job.TenantID), "Worker.refresh",
+		trace.WithAttributes(attribute.String("dns.qname", job.FQDN), attribute.Int("refresh.claims", job.Claims)))
+	defer span.End()
+
+	_, err := w.resolver.Resolve(ctx, job.FQDN)
+	if err != nil {
+		w.logger.WarnContext(ctx, "Failed to resolve", "fqdn", job.FQDN, "error", err)
+	}
+
+	err = w.queue.CompleteRefreshJob(ctx, job, w.Interval(), err)
+	switch {
+	case errors.Is(err, models.ErrLeaseLost):
+		w.logger.WarnContext(ctx, "Lease expired before completion", "fqdn", job.FQDN)
+	case err != nil:
+		w.logger.ErrorContext(ctx, "Failed to complete refresh", "fqdn", job.FQDN, "error", err)
+	}
+}
+
+// Schedule сверяет очередь с записями: заводит задания новых FQDN и удаляет
+// задания удаленных. Достаточно одной реплики, поэтому его запускает лидер
+func (w *Worker) Schedule(ctx context.Context) {
+	ticker := time.NewTicker(w.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		added, removed, err := w.queue.SyncRefreshJobs(ctx, w.Interval())
+		switch {
+		case err != nil && ctx.Err() == nil:
+			w.logger.ErrorContext(ctx, "Failed to sync refresh jobs", "error", err)
+		case added > 0 || removed > 0:
+			w.logger.InfoContext(ctx, "Refresh jobs synced", "added", added, "removed", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
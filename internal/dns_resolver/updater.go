@@ -0,0 +1,406 @@
+package dnsresolver
+
+import (
+	"context"
+	"dns-resolver/internal/logging"
+	"dns-resolver/internal/models"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	MinUpdateInterval = time.Second
+	MaxUpdateInterval = 24 * time.Hour
+
+	DefaultSettingsPoll = 2 * time.Second
+)
+
+type CycleTrigger string
+
+const (
+	TriggerSchedule CycleTrigger = "schedule"
+	TriggerManual   CycleTrigger = "manual"
+)
+
+// CycleStatus — ход одного прохода по записям всех арендаторов.
+// FinishedAt пуст, пока проход идет
+type CycleStatus struct {
+	Trigger    CycleTrigger
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Total      int
+	Succeeded  int
+	Failed     int
+}
+
+type UpdaterStatus struct {
+	// Active — Run выполняется в этом процессе (при выборах лидера — только у лидера)
+	Active   bool
+	Interval time.Duration
+	Paused   bool
+	// NextRun — время следующего планового прохода; пусто на паузе
+	NextRun time.Time
+	Current *CycleStatus
+	Last    *CycleStatus
+}
+
+// SettingsStore хранит настройки обновления, общие для реплик
+type SettingsStore interface {
+	GetUpdaterSettings(ctx context.Context) (models.UpdaterSettings, error)
+	UpdateUpdaterSettings(ctx context.Context, update models.UpdaterSettingsUpdate) error
+	// TakeUpdaterRefresh снимает запрос внеочередного прохода; true — он был
+	TakeUpdaterRefresh(ctx context.Context) (bool, error)
+}
+
+// Updater периодически перепроверяет записи всех арендаторов. Интервал,
+// паузу и внеочередной проход можно менять на лету из других горутин,
+// а с SettingsStore — и с других реплик
+type Updater struct {
+	resolver *Resolver
+	logger   *slog.Logger
+	store    SettingsStore
+	// defaultInterval действует, пока интервал не задан в хранилище
+	defaultInterval time.Duration
+	settingsPoll    time.Duration
+
+	mu       sync.Mutex
+	active   bool
+	interval time.Duration
+	paused   bool
+	// anchor — начало текущего периода: следующий проход в anchor+interval
+	anchor  time.Time
+	refresh bool
+	current *CycleStatus
+	last    *CycleStatus
+
+	// wake будит Run после изменения настроек
+	wake chan struct{}
+}
+
+type UpdaterOption func(*Updater)
+
+// WithSettingsStore хранит настройки в store, чтобы их можно было менять
+// через любую реплику, а не только через лидера
+func WithSettingsStore(store SettingsStore) UpdaterOption {
+	return func(u *Updater) {
+		u.store = store
+	}
+}
+
+// WithSettingsPoll задает, как часто перечитываются общие настройки
+func WithSettingsPoll(d time.Duration) UpdaterOption {
+	return func(u *Updater) {
+		u.settingsPoll = d
+	}
+}
+
+func NewUpdater(resolver *Resolver, interval time.Duration, opts ...UpdaterOption) *Updater {
+	u := &Updater{
+		resolver:        resolver,
+		logger:          logging.Component("updater"),
+		defaultInterval: interval,
+		settingsPoll:    DefaultSettingsPoll,
+		interval:        interval,
+		anchor:          time.Now(),
+		wake:            make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
+// DNSUpdater запускает обновление с фиксированным интервалом до отмены ctx
+func (r *Resolver) DNSUpdater(ctx context.Context, interval time.Duration) {
+	NewUpdater(r, interval).Run(ctx)
+}
+
+func (u *Updater) notify() {
+	select {
+	case u.wake <- struct{}{}:
+	default:
+	}
+}
+
+// apply сохраняет изменение в хранилище и применяет его к этой реплике.
+// Лидер подхватит изменение из хранилища, на какой бы реплике оно ни пришло
+func (u *Updater) apply(ctx context.Context, update models.UpdaterSettingsUpdate) error {
+	if u.store != nil {
+		if err := u.store.UpdateUpdaterSettings(ctx, update); err != nil {
+			return err
+		}
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if update.Interval != nil {
+		u.interval = *update.Interval
+	}
+	if update.Paused != nil {
+		u.paused = *update.Paused
+	}
+	// С хранилищем запрос забирает лидер, иначе проход выполнит эта реплика
+	if update.RequestRefresh && u.store == nil {
+		u.refresh = true
+	}
+	u.notify()
+	return nil
+}
+
+func (u *Updater) Pause(ctx context.Context) error {
+	paused := true
+	return u.apply(ctx, models.UpdaterSettingsUpdate{Paused: &paused})
+}
+
+// Resume снимает паузу. Если плановый проход просрочен, он начнется сразу
+func (u *Updater) Resume(ctx context.Context) error {
+	paused := false
+	return u.apply(ctx, models.UpdaterSettingsUpdate{Paused: &paused})
+}
+
+func checkInterval(interval time.Duration) error {
+	if interval < MinUpdateInterval || interval > MaxUpdateInterval {
+		return fmt.Errorf("%w: interval must be between %v and %v", ErrInvalidInterval, MinUpdateInterval, MaxUpdateInterval)
+	}
+	return nil
+}
+
+// SetInterval меняет интервал; следующий проход отсчитывается от начала предыдущего
+func (u *Updater) SetInterval(ctx context.Context, interval time.Duration) error {
+	if err := checkInterval(interval); err != nil {
+		return err
+	}
+	return u.apply(ctx, models.UpdaterSettingsUpdate{Interval: &interval})
+}
+
+// RefreshAll запрашивает внеочередной проход, в том числе на паузе.
+// Если проход уже идет, следующий начнется сразу после него
+func (u *Updater) RefreshAll(ctx context.Context) error {
+	return u.apply(ctx, models.UpdaterSettingsUpdate{RequestRefresh: true})
+}
+
+// sync применяет общие настройки из хранилища. Запрос внеочередного
+// прохода забирает только лидер
+func (u *Updater) sync(ctx context.Context, leader bool) {
+	if u.store == nil {
+		return
+	}
+
+	settings, err := u.store.GetUpdaterSettings(ctx)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "Failed to load updater settings", "error", err)
+		return
+	}
+	refresh := false
+	if leader {
+		if refresh, err = u.store.TakeUpdaterRefresh(ctx); err != nil {
+			u.logger.ErrorContext(ctx, "Failed to take refresh request", "error", err)
+		}
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.interval = u.defaultInterval
+	if settings.IntervalSeconds > 0 {
+		u.interval = time.Duration(settings.IntervalSeconds * float64(time.Second))
+	}
+	u.paused = settings.Paused
+	u.refresh = u.refresh || refresh
+}
+
+// Watch перечитывает общие настройки до отмены ctx, чтобы состояние и TTL
+// записей на репликах, не занятых обновлением, совпадали с лидером
+func (u *Updater) Watch(ctx context.Context) {
+	if u.store == nil {
+		return
+	}
+	ticker := time.NewTicker(u.settingsPoll)
+	defer ticker.Stop()
+
+	for {
+		u.sync(ctx, false)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (u *Updater) Interval() time.Duration {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.interval
+}
+
+func (u *Updater) Status() UpdaterStatus {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	s := UpdaterStatus{Active: u.active, Interval: u.interval, Paused: u.paused}
+	if u.active && !u.paused {
+		s.NextRun = u.anchor.Add(u.interval)
+	}
+	if u.current != nil {
+		current := *u.current
+		s.Current = &current
+	}
+	if u.last != nil {
+		last := *u.last
+		s.Last = &last
+	}
+	return s
+}
+
+// next решает, пора ли начинать проход, и сколько ждать, если нет.
+// Нулевое ожидание при ok=false означает паузу без срока
+func (u *Updater) next(now time.Time) (trigger CycleTrigger, ok bool, wait time.Duration) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.refresh {
+		u.refresh = false
+		return TriggerManual, true, 0
+	}
+	if u.paused {
+		return "", false, 0
+	}
+	if due := u.anchor.Add(u.interval); now.Before(due) {
+		return "", false, due.Sub(now)
+	}
+	return TriggerSchedule, true, 0
+}
+
+// Run выполняет плановые и ручные проходы до отмены ctx. Его можно запускать
+// повторно, например при каждом избрании лидером; настройки сохраняются
+func (u *Updater) Run(ctx context.Context) {
+	u.mu.Lock()
+	u.active = true
+	// Новый лидер не знает, когда был последний проход, и начинает период заново
+	u.anchor = time.Now()
+	u.mu.Unlock()
+
+	u.sync(ctx, true)
+	u.logger.InfoContext(ctx, "DNS updater started", "interval", u.Interval())
+
+	// Изменения с других реплик приходят только через хранилище
+	var poll <-chan time.Time
+	if u.store != nil {
+		ticker := time.NewTicker(u.settingsPoll)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+	defer func() {
+		u.mu.Lock()
+		u.active = false
+		u.mu.Unlock()
+		u.logger.InfoContext(ctx, "DNS updater stopped")
+	}()
+
+	for {
+		trigger, ok, wait := u.next(time.Now())
+		if ok {
+			if !u.cycle(ctx, trigger) {
+				u.logger.InfoContext(ctx, "Update cycle interrupted by context")
+				return
+			}
+			continue
+		}
+
+		// На паузе таймера нет: ждем только изменения настроек
+		var timer *time.Timer
+		var fired <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			fired = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			u.logger.InfoContext(ctx, "Shutting down DNS updater by context signal")
+			return
+		case <-u.wake:
+			u.sync(ctx, true)
+		case <-poll:
+			u.sync(ctx, true)
+		case <-fired:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// cycle перепроверяет записи всех арендаторов. Возвращает false, если ctx отменен
+func (u *Updater) cycle(ctx context.Context, trigger CycleTrigger) bool {
+	// Спан прохода объединяет запросы к DNS и БД, сделанные вне HTTP-запросов
+	ctx, span := tracer.Start(ctx, "Updater.cycle", trace.WithAttributes(attribute.String("updater.trigger", string(trigger))))
+	defer span.End()
+
+	status := &CycleStatus{Trigger: trigger, StartedAt: time.Now()}
+	u.mu.Lock()
+	u.anchor = status.StartedAt
+	u.current = status
+	u.mu.Unlock()
+
+	defer func() {
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		status.FinishedAt = time.Now()
+		u.current, u.last = nil, status
+		span.SetAttributes(attribute.Int("updater.total", status.Total),
+			attribute.Int("updater.succeeded", status.Succeeded), attribute.Int("updater.failed", status.Failed))
+	}()
+
+	u.logger.InfoContext(ctx, "Update cycle started", "trigger", trigger)
+
+	tenants, err := u.resolver.ListTenants(ctx)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "Failed to get tenants", "error", err)
+		return ctx.Err() == nil
+	}
+
+	for _, tenant := range tenants {
+		tenantCtx := models.WithTenant(ctx, tenant.ID)
+		fqdns, err := u.resolver.GetAllFQDNs(tenantCtx)
+		if err != nil {
+			u.logger.ErrorContext(tenantCtx, "Failed to get FQDNs", "tenant", tenant.Name, "error", err)
+			continue
+		}
+
+		u.logger.InfoContext(tenantCtx, "Updating tenant FQDNs", "tenant", tenant.Name, "count", len(fqdns))
+		u.mu.Lock()
+		status.Total += len(fqdns)
+		u.mu.Unlock()
+
+		for _, fqdn := range fqdns {
+			if ctx.Err() != nil {
+				return false
+			}
+
+			ips, err := u.resolver.Resolve(tenantCtx, fqdn)
+			u.mu.Lock()
+			if err != nil {
+				status.Failed++
+			} else {
+				status.Succeeded++
+			}
+			u.mu.Unlock()
+
+			if err != nil {
+				u.logger.WarnContext(tenantCtx, "Failed to resolve", "fqdn", fqdn, "error", err)
+				continue
+			}
+			u.logger.DebugContext(tenantCtx, "Updated", "fqdn", fqdn, "ips", ips)
+		}
+	}
+
+	u.logger.InfoContext(ctx, "Update cycle completed", "trigger", trigger,
+		"succeeded", status.Succeeded, "total", status.Total, "duration", time.Since(status.StartedAt))
+	return true
+}
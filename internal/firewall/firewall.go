@@ -0,0 +1,216 @@
+package firewall
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatNFTables Format = "nftables"
+	FormatIPSet    Format = "ipset"
+	FormatIPTables Format = "iptables"
+)
+
+type Family string
+
+const (
+	FamilyAll  Family = ""
+	FamilyIPv4 Family = "ipv4"
+	FamilyIPv6 Family = "ipv6"
+)
+
+const (
+	DefaultName  = "dns_allow"
+	DefaultTable = "dns_resolver"
+)
+
+var nameRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,24}$`)
+
+type Options struct {
+	Format Format
+	// Name — имя набора (nftables/ipset) или цепочки (iptables).
+	// Для наборов добавляются суффиксы _v4 и _v6
+	Name string
+	// Table — таблица nftables
+	Table  string
+	Family Family
+}
+
+func (o *Options) normalize() error {
+	if o.Name == "" {
+		o.Name = DefaultName
+	}
+	if o.Table == "" {
+		o.Table = DefaultTable
+	}
+	if !nameRe.MatchString(o.Name) {
+		return fmt.Errorf("invalid name %q", o.Name)
+	}
+	if !nameRe.MatchString(o.Table) {
+		return fmt.Errorf("invalid table %q", o.Table)
+	}
+
+	switch o.Family {
+	case FamilyAll, FamilyIPv4, FamilyIPv6:
+	default:
+		return fmt.Errorf("unknown family %q", o.Family)
+	}
+
+	switch o.Format {
+	case FormatNFTables, FormatIPSet:
+	case FormatIPTables:
+		// iptables-restore и ip6tables-restore принимают разные файлы, а молча
+		// выдать одно семейство значило бы потерять адреса другого
+		if o.Family == FamilyAll {
+			return errors.New("iptables format requires family ipv4 or ipv6")
+		}
+	default:
+		return fmt.Errorf("unknown format %q", o.Format)
+	}
+
+	return nil
+}
+
+// Split разбирает адреса на IPv4 и IPv6, убирая дубликаты и некорректные
+// значения. Результат отсортирован, чтобы вывод не менялся без изменения данных
+func Split(ips []string) (v4, v6 []netip.Addr) {
+	seen := make(map[netip.Addr]bool, len(ips))
+	for _, raw := range ips {
+		addr, err := netip.ParseAddr(raw)
+		if err != nil {
+			continue
+		}
+		addr = addr.Unmap()
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+
+		if addr.Is4() {
+			v4 = append(v4, addr)
+		} else {
+			v6 = append(v6, addr)
+		}
+	}
+
+	sort.Slice(v4, func(i, j int) bool { return v4[i].Less(v4[j]) })
+	sort.Slice(v6, func(i, j int) bool { return v6[i].Less(v6[j]) })
+	return v4, v6
+}
+
+// Render формирует файл правил для загрузки через nft -f, ipset restore
+// или iptables-restore --noflush
+func Render(opts Options, ips []string) ([]byte, error) {
+	if err := opts.normalize(); err != nil {
+		return nil, err
+	}
+
+	v4, v6 := Split(ips)
+	if opts.Family == FamilyIPv6 {
+		v4 = nil
+	}
+	if opts.Family == FamilyIPv4 {
+		v6 = nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# generated by dns-resolver, do not edit\n")
+
+	switch opts.Format {
+	case FormatNFTables:
+		renderNFTables(&buf, opts, v4, v6)
+	case FormatIPSet:
+		renderIPSet(&buf, opts, v4, v6)
+	case FormatIPTables:
+		renderIPTables(&buf, opts, v4, v6)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func joinAddrs(addrs []netip.Addr) string {
+	parts := make([]string, len(addrs))
+	for i, a := range addrs {
+		parts[i] = a.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// renderNFTables объявляет наборы и заменяет их содержимое атомарно в рамках nft -f
+func renderNFTables(buf *bytes.Buffer, opts Options, v4, v6 []netip.Addr) {
+	type set struct {
+		name, typ string
+		addrs     []netip.Addr
+	}
+	var sets []set
+	if opts.Family != FamilyIPv6 {
+		sets = append(sets, set{opts.Name + "_v4", "ipv4_addr", v4})
+	}
+	if opts.Family != FamilyIPv4 {
+		sets = append(sets, set{opts.Name + "_v6", "ipv6_addr", v6})
+	}
+
+	fmt.Fprintf(buf, "table inet %s {\n", opts.Table)
+	for _, s := range sets {
+		fmt.Fprintf(buf, "\tset %s {\n\t\ttype %s\n\t}\n", s.name, s.typ)
+	}
+	buf.WriteString("}\n")
+
+	for _, s := range sets {
+		fmt.Fprintf(buf, "flush set inet %s %s\n", opts.Table, s.name)
+		if len(s.addrs) > 0 {
+			fmt.Fprintf(buf, "add element inet %s %s { %s }\n", opts.Table, s.name, joinAddrs(s.addrs))
+		}
+	}
+}
+
+func renderIPSet(buf *bytes.Buffer, opts Options, v4, v6 []netip.Addr) {
+	if opts.Family != FamilyIPv6 {
+		name := opts.Name + "_v4"
+		fmt.Fprintf(buf, "create %s hash:ip family inet -exist\n", name)
+		fmt.Fprintf(buf, "flush %s\n", name)
+		for _, a := range v4 {
+			fmt.Fprintf(buf, "add %s %s\n", name, a)
+		}
+	}
+	if opts.Family != FamilyIPv4 {
+		name := opts.Name + "_v6"
+		fmt.Fprintf(buf, "create %s hash:ip family inet6 -exist\n", name)
+		fmt.Fprintf(buf, "flush %s\n", name)
+		for _, a := range v6 {
+			fmt.Fprintf(buf, "add %s %s\n", name, a)
+		}
+	}
+}
+
+// renderIPTables описывает отдельную цепочку для iptables-restore (ipv4) или
+// ip6tables-restore (ipv6): при загрузке с --noflush объявленная цепочка очищается и заполняется заново
+func renderIPTables(buf *bytes.Buffer, opts Options, v4, v6 []netip.Addr) {
+	chain := strings.ToUpper(opts.Name)
+	addrs, bits := v4, 32
+	if opts.Family == FamilyIPv6 {
+		addrs, bits = v6, 128
+	}
+
+	buf.WriteString("*filter\n")
+	fmt.Fprintf(buf, ":%s - [0:0]\n", chain)
+	for _, a := range addrs {
+		fmt.Fprintf(buf, "-A %s -d %s/%d -j ACCEPT\n", chain, a, bits)
+	}
+	buf.WriteString("COMMIT\n")
+}
+
+// ETag — сильный валидатор содержимого для условных запросов агентов
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
@@ -0,0 +1,90 @@
+package firewall
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testIPs = []string{"10.0.0.2", "2001:db8::1", "10.0.0.10", "bogus", "10.0.0.2", "::ffff:10.0.0.3"}
+
+func TestSplit(t *testing.T) {
+	v4, v6 := Split(testIPs)
+
+	require.Len(t, v4, 3)
+	assert.Equal(t, "10.0.0.2", v4[0].String())
+	assert.Equal(t, "10.0.0.3", v4[1].String())
+	assert.Equal(t, "10.0.0.10", v4[2].String())
+	require.Len(t, v6, 1)
+	assert.Equal(t, "2001:db8::1", v6[0].String())
+}
+
+func TestRenderNFTables(t *testing.T) {
+	out, err := Render(Options{Format: FormatNFTables}, testIPs)
+	require.NoError(t, err)
+
+	expected := `# generated by dns-resolver, do not edit
+table inet dns_resolver {
+	set dns_allow_v4 {
+		type ipv4_addr
+	}
+	set dns_allow_v6 {
+		type ipv6_addr
+	}
+}
+flush set inet dns_resolver dns_allow_v4
+add element inet dns_resolver dns_allow_v4 { 10.0.0.2, 10.0.0.3, 10.0.0.10 }
+flush set inet dns_resolver dns_allow_v6
+add element inet dns_resolver dns_allow_v6 { 2001:db8::1 }
+`
+	assert.Equal(t, expected, string(out))
+}
+
+func TestRenderIPSet(t *testing.T) {
+	out, err := Render(Options{Format: FormatIPSet, Name: "egress", Family: FamilyIPv6}, testIPs)
+	require.NoError(t, err)
+
+	expected := `# generated by dns-resolver, do not edit
+create egress_v6 hash:ip family inet6 -exist
+flush egress_v6
+add egress_v6 2001:db8::1
+`
+	assert.Equal(t, expected, string(out))
+}
+
+func TestRenderIPTables(t *testing.T) {
+	out, err := Render(Options{Format: FormatIPTables, Family: FamilyIPv4}, testIPs)
+	require.NoError(t, err)
+
+	expected := `# generated by dns-resolver, do not edit
+*filter
+:DNS_ALLOW - [0:0]
+-A DNS_ALLOW -d 10.0.0.2/32 -j ACCEPT
+-A DNS_ALLOW -d 10.0.0.3/32 -j ACCEPT
+-A DNS_ALLOW -d 10.0.0.10/32 -j ACCEPT
+COMMIT
+`
+	assert.Equal(t, expected, string(out))
+}
+
+func TestRenderInvalidOptions(t *testing.T) {
+	_, err := Render(Options{Format: "pf"}, testIPs)
+	assert.Error(t, err)
+
+	_, err = Render(Options{Format: FormatIPSet, Name: "bad name; rm -rf"}, testIPs)
+	assert.Error(t, err)
+
+	// Одним файлом iptables оба семейства не загрузить
+	_, err = Render(Options{Format: FormatIPTables}, testIPs)
+	assert.Error(t, err)
+}
+
+func TestETagStable(t *testing.T) {
+	a, err := Render(Options{Format: FormatNFTables}, []string{"1.1.1.1", "2.2.2.2"})
+	require.NoError(t, err)
+	b, err := Render(Options{Format: FormatNFTables}, []string{"2.2.2.2", "1.1.1.1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, ETag(a), ETag(b))
+}
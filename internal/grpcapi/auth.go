@@ -0,0 +1,235 @@
+package grpcapi
+
+import (
+	"context"
+	"dns-resolver/internal/auth"
+	"dns-resolver/internal/grpcapi/resolverv1"
+	"dns-resolver/internal/logging"
+	"dns-resolver/internal/models"
+	"dns-resolver/internal/validator"
+	"errors"
+	"net/http"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	APIKeyMetadata    = "x-api-key"
+	requestIDMetadata = "x-request-id"
+)
+
+// requiredRoles — методы, которым мало роли viewer
+var requiredRoles = map[string]models.Role{
+	resolverv1.ResolverService_AddFQDN_FullMethodName:    models.RoleEditor,
+	resolverv1.ResolverService_DeleteFQDN_FullMethodName: models.RoleEditor,
+}
+
+// auditActions — изменяющие методы и их действия в журнале аудита, как у REST
+var auditActions = map[string]string{
+	resolverv1.ResolverService_AddFQDN_FullMethodName:    "fqdn.add",
+	resolverv1.ResolverService_DeleteFQDN_FullMethodName: "fqdn.delete",
+}
+
+type principalKey struct{}
+
+// PrincipalFrom возвращает владельца запроса, проверенного перехватчиком
+func PrincipalFrom(ctx context.Context) (auth.Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(auth.Principal)
+	return p, ok
+}
+
+func metadataValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// authenticate проверяет учетные данные из метаданных x-api-key или
+// authorization: Bearer и привязывает контекст к арендатору
+func (s *Server) authenticate(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	credential := metadataValue(md, APIKeyMetadata)
+	if credential == "" {
+		credential = auth.BearerToken(metadataValue(md, "authorization"))
+	}
+
+	p, err := s.auth.Authenticate(ctx, credential)
+	if errors.Is(err, auth.ErrUnauthenticated) {
+		return ctx, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		return ctx, statusFromError(err)
+	}
+
+	// Субъект попадает в контекст и при отказе в доступе — для аудита
+	ctx = models.WithTenant(context.WithValue(ctx, principalKey{}, p), p.TenantID)
+	role, ok := requiredRoles[method]
+	if !ok {
+		role = models.RoleViewer
+	}
+	if !p.Role.Allows(role) {
+		return ctx, status.Error(codes.PermissionDenied, string(role)+" role required")
+	}
+	return ctx, nil
+}
+
+// withRequestID берет идентификатор запроса из метаданных x-request-id
+// или выдает новый и кладет его в контекст вызова
+func withRequestID(ctx context.Context) (context.Context, string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	id := metadataValue(md, requestIDMetadata)
+	if id == "" {
+		id = logging.NewRequestID()
+	}
+	return logging.WithRequestID(ctx, id), id
+}
+
+// admit пропускает вызов через лимит по адресу, аутентификацию и лимит
+// по учетным данным — в том же порядке, что и middleware REST
+func (s *Server) admit(ctx context.Context, method string) (context.Context, error) {
+	if err := s.limitByPeer(ctx, method); err != nil {
+		return ctx, err
+	}
+	ctx, err := s.authenticate(ctx, method)
+	if err != nil {
+		return ctx, err
+	}
+	if err := s.limitByCredential(ctx, method); err != nil {
+		return ctx, err
+	}
+	return ctx, nil
+}
+
+func (s *Server) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, id := withRequestID(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, id))
+
+	action, audited := auditActions[info.FullMethod]
+	ctx, err := s.admit(ctx, info.FullMethod)
+	if err != nil {
+		// Отказы тоже попадают в журнал, анонимные — только в лог сервиса
+		if code := status.Code(err); audited && (code == codes.Unauthenticated || code == codes.PermissionDenied) {
+			s.audit(ctx, action, req, err)
+		}
+		return nil, err
+	}
+
+	resp, err := handler(ctx, req)
+	if audited {
+		s.audit(ctx, action, req, err)
+	}
+	return resp, err
+}
+
+func (s *Server) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, id := withRequestID(ss.Context())
+	ss.SetHeader(metadata.Pairs(requestIDMetadata, id))
+
+	ctx, err := s.admit(ctx, info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+// recoverUnary превращает панику обработчика в codes.Internal, как
+// middleware.Recover в REST, чтобы она не уронила процесс
+func (s *Server) recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = s.recovered(ctx, info.FullMethod, r)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func (s *Server) recoverStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = s.recovered(ss.Context(), info.FullMethod, r)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+func (s *Server) recovered(ctx context.Context, method string, r any) error {
+	s.logger.ErrorContext(ctx, "Panic in gRPC handler", "method", method, "panic", r, "stack", string(debug.Stack()))
+	return status.Error(codes.Internal, "internal error")
+}
+
+// serverStream подменяет контекст потока на контекст арендатора
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// audit записывает результат изменяющего вызова. Статус хранится
+// в HTTP-эквиваленте, чтобы записи REST и gRPC фильтровались одинаково
+func (s *Server) audit(ctx context.Context, action string, req any, err error) {
+	entry := models.AuditEntry{
+		Action: action,
+		Result: models.AuditSuccess,
+		Status: http.StatusOK,
+	}
+
+	if r, ok := req.(interface{ GetFqdn() string }); ok {
+		entry.Target = r.GetFqdn()
+		if fqdn, err := validator.CanonicalFQDN(entry.Target); err == nil {
+			entry.Target = fqdn
+		}
+	}
+	p, authenticated := PrincipalFrom(ctx)
+	entry.Actor = p.Subject
+	if addr, ok := peerIP(ctx); ok {
+		entry.SourceIP = addr
+	}
+	entry.RequestID = logging.RequestID(ctx)
+
+	if err != nil {
+		entry.Result = models.AuditFailure
+		entry.Status = httpStatus(status.Code(err))
+		entry.Error = err.Error()
+	}
+	// Вызовы без учетных данных не принадлежат ни одному арендатору,
+	// как и в REST они попадают только в лог сервиса
+	if !authenticated {
+		s.logger.WarnContext(ctx, "Unauthenticated mutation rejected", "action", action, "target", entry.Target,
+			"source_ip", entry.SourceIP, "status", entry.Status, "request_id", entry.RequestID)
+		return
+	}
+
+	if auditErr := s.resolver.AppendAudit(ctx, &entry); auditErr != nil {
+		s.logger.ErrorContext(ctx, "Failed to write audit entry", "action", action, "error", auditErr)
+	}
+}
+
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,65 @@
+package grpcapi
+
+import (
+	"context"
+	"dns-resolver/internal/ratelimit"
+	"fmt"
+	"math"
+	"net"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// methodClass относит изменяющие методы к бюджету записи, как POST и DELETE
+// в REST: AddFQDN вызывает внешний DNS-запрос
+func methodClass(method string) ratelimit.Class {
+	if _, ok := auditActions[method]; ok {
+		return ratelimit.Write
+	}
+	return ratelimit.Read
+}
+
+func (s *Server) limit(key, method string) error {
+	d := s.limiter.Allow(key, methodClass(method))
+	if d.Limit == 0 || d.Allowed {
+		return nil
+	}
+	return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry after %ds", int(math.Ceil(d.RetryAfter.Seconds())))
+}
+
+// limitByPeer ограничивает вызовы с одного адреса еще до проверки учетных
+// данных. Бюджет общий с REST: ключи те же, что у LimitByClient
+func (s *Server) limitByPeer(ctx context.Context, method string) error {
+	if s.limiter == nil {
+		return nil
+	}
+	addr, ok := peerIP(ctx)
+	if !ok {
+		return nil
+	}
+	return s.limit("ip:"+addr, method)
+}
+
+// peerIP возвращает адрес клиента без порта
+func peerIP(ctx context.Context) (string, bool) {
+	pr, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	addr := pr.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return addr, true
+}
+
+// limitByCredential ограничивает вызовы по ключу или субъекту JWT
+func (s *Server) limitByCredential(ctx context.Context, method string) error {
+	p, ok := PrincipalFrom(ctx)
+	if s.limiter == nil || !ok {
+		return nil
+	}
+	return s.limit(fmt.Sprintf("tenant:%d:%s", p.TenantID, p.Subject), method)
+}
@@ -0,0 +1,776 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: dnsresolver/v1/resolver.proto
+
+package resolverv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AddFQDNRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fqdn          string                 `protobuf:"bytes,1,opt,name=fqdn,proto3" json:"fqdn,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddFQDNRequest) Reset() {
+	*x = AddFQDNRequest{}
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddFQDNRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddFQDNRequest) ProtoMessage() {}
+
+func (x *AddFQDNRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddFQDNRequest.ProtoReflect.Descriptor instead.
+func (*AddFQDNRequest) Descriptor() ([]byte, []int) {
+	return file_dnsresolver_v1_resolver_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AddFQDNRequest) GetFqdn() string {
+	if x != nil {
+		return x.Fqdn
+	}
+	return ""
+}
+
+type AddFQDNResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fqdn          string                 `protobuf:"bytes,1,opt,name=fqdn,proto3" json:"fqdn,omitempty"`
+	FqdnUnicode   string                 `protobuf:"bytes,2,opt,name=fqdn_unicode,json=fqdnUnicode,proto3" json:"fqdn_unicode,omitempty"`
+	Ips           []string               `protobuf:"bytes,3,rep,name=ips,proto3" json:"ips,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddFQDNResponse) Reset() {
+	*x = AddFQDNResponse{}
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddFQDNResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddFQDNResponse) ProtoMessage() {}
+
+func (x *AddFQDNResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddFQDNResponse.ProtoReflect.Descriptor instead.
+func (*AddFQDNResponse) Descriptor() ([]byte, []int) {
+	return file_dnsresolver_v1_resolver_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AddFQDNResponse) GetFqdn() string {
+	if x != nil {
+		return x.Fqdn
+	}
+	return ""
+}
+
+func (x *AddFQDNResponse) GetFqdnUnicode() string {
+	if x != nil {
+		return x.FqdnUnicode
+	}
+	return ""
+}
+
+func (x *AddFQDNResponse) GetIps() []string {
+	if x != nil {
+		return x.Ips
+	}
+	return nil
+}
+
+type GetIPsByFQDNRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fqdn          string                 `protobuf:"bytes,1,opt,name=fqdn,proto3" json:"fqdn,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetIPsByFQDNRequest) Reset() {
+	*x = GetIPsByFQDNRequest{}
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetIPsByFQDNRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetIPsByFQDNRequest) ProtoMessage() {}
+
+func (x *GetIPsByFQDNRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetIPsByFQDNRequest.ProtoReflect.Descriptor instead.
+func (*GetIPsByFQDNRequest) Descriptor() ([]byte, []int) {
+	return file_dnsresolver_v1_resolver_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetIPsByFQDNRequest) GetFqdn() string {
+	if x != nil {
+		return x.Fqdn
+	}
+	return ""
+}
+
+type GetIPsByFQDNResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fqdn          string                 `protobuf:"bytes,1,opt,name=fqdn,proto3" json:"fqdn,omitempty"`
+	FqdnUnicode   string                 `protobuf:"bytes,2,opt,name=fqdn_unicode,json=fqdnUnicode,proto3" json:"fqdn_unicode,omitempty"`
+	Ips           []string               `protobuf:"bytes,3,rep,name=ips,proto3" json:"ips,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetIPsByFQDNResponse) Reset() {
+	*x = GetIPsByFQDNResponse{}
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetIPsByFQDNResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetIPsByFQDNResponse) ProtoMessage() {}
+
+func (x *GetIPsByFQDNResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetIPsByFQDNResponse.ProtoReflect.Descriptor instead.
+func (*GetIPsByFQDNResponse) Descriptor() ([]byte, []int) {
+	return file_dnsresolver_v1_resolver_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetIPsByFQDNResponse) GetFqdn() string {
+	if x != nil {
+		return x.Fqdn
+	}
+	return ""
+}
+
+func (x *GetIPsByFQDNResponse) GetFqdnUnicode() string {
+	if x != nil {
+		return x.FqdnUnicode
+	}
+	return ""
+}
+
+func (x *GetIPsByFQDNResponse) GetIps() []string {
+	if x != nil {
+		return x.Ips
+	}
+	return nil
+}
+
+type GetFQDNsByIPRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ip            string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFQDNsByIPRequest) Reset() {
+	*x = GetFQDNsByIPRequest{}
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFQDNsByIPRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFQDNsByIPRequest) ProtoMessage() {}
+
+func (x *GetFQDNsByIPRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFQDNsByIPRequest.ProtoReflect.Descriptor instead.
+func (*GetFQDNsByIPRequest) Descriptor() ([]byte, []int) {
+	return file_dnsresolver_v1_resolver_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetFQDNsByIPRequest) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+type GetFQDNsByIPResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ip            string                 `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	Fqdns         []string               `protobuf:"bytes,2,rep,name=fqdns,proto3" json:"fqdns,omitempty"`
+	FqdnsUnicode  []string               `protobuf:"bytes,3,rep,name=fqdns_unicode,json=fqdnsUnicode,proto3" json:"fqdns_unicode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFQDNsByIPResponse) Reset() {
+	*x = GetFQDNsByIPResponse{}
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFQDNsByIPResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFQDNsByIPResponse) ProtoMessage() {}
+
+func (x *GetFQDNsByIPResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFQDNsByIPResponse.ProtoReflect.Descriptor instead.
+func (*GetFQDNsByIPResponse) Descriptor() ([]byte, []int) {
+	return file_dnsresolver_v1_resolver_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetFQDNsByIPResponse) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *GetFQDNsByIPResponse) GetFqdns() []string {
+	if x != nil {
+		return x.Fqdns
+	}
+	return nil
+}
+
+func (x *GetFQDNsByIPResponse) GetFqdnsUnicode() []string {
+	if x != nil {
+		return x.FqdnsUnicode
+	}
+	return nil
+}
+
+type ListFQDNsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFQDNsRequest) Reset() {
+	*x = ListFQDNsRequest{}
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFQDNsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFQDNsRequest) ProtoMessage() {}
+
+func (x *ListFQDNsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFQDNsRequest.ProtoReflect.Descriptor instead.
+func (*ListFQDNsRequest) Descriptor() ([]byte, []int) {
+	return file_dnsresolver_v1_resolver_proto_rawDescGZIP(), []int{6}
+}
+
+type ListFQDNsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fqdns         []string               `protobuf:"bytes,1,rep,name=fqdns,proto3" json:"fqdns,omitempty"`
+	FqdnsUnicode  []string               `protobuf:"bytes,2,rep,name=fqdns_unicode,json=fqdnsUnicode,proto3" json:"fqdns_unicode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFQDNsResponse) Reset() {
+	*x = ListFQDNsResponse{}
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFQDNsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFQDNsResponse) ProtoMessage() {}
+
+func (x *ListFQDNsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFQDNsResponse.ProtoReflect.Descriptor instead.
+func (*ListFQDNsResponse) Descriptor() ([]byte, []int) {
+	return file_dnsresolver_v1_resolver_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListFQDNsResponse) GetFqdns() []string {
+	if x != nil {
+		return x.Fqdns
+	}
+	return nil
+}
+
+func (x *ListFQDNsResponse) GetFqdnsUnicode() []string {
+	if x != nil {
+		return x.FqdnsUnicode
+	}
+	return nil
+}
+
+type DeleteFQDNRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fqdn          string                 `protobuf:"bytes,1,opt,name=fqdn,proto3" json:"fqdn,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFQDNRequest) Reset() {
+	*x = DeleteFQDNRequest{}
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFQDNRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFQDNRequest) ProtoMessage() {}
+
+func (x *DeleteFQDNRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFQDNRequest.ProtoReflect.Descriptor instead.
+func (*DeleteFQDNRequest) Descriptor() ([]byte, []int) {
+	return file_dnsresolver_v1_resolver_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteFQDNRequest) GetFqdn() string {
+	if x != nil {
+		return x.Fqdn
+	}
+	return ""
+}
+
+type DeleteFQDNResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFQDNResponse) Reset() {
+	*x = DeleteFQDNResponse{}
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFQDNResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFQDNResponse) ProtoMessage() {}
+
+func (x *DeleteFQDNResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFQDNResponse.ProtoReflect.Descriptor instead.
+func (*DeleteFQDNResponse) Descriptor() ([]byte, []int) {
+	return file_dnsresolver_v1_resolver_proto_rawDescGZIP(), []int{9}
+}
+
+type WatchEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Glob-шаблоны FQDN, как параметр fqdn у GET /api/events
+	FqdnPatterns []string `protobuf:"bytes,1,rep,name=fqdn_patterns,json=fqdnPatterns,proto3" json:"fqdn_patterns,omitempty"`
+	// fqdn_added, ip_added, ip_removed, nxdomain, resolve_failed
+	Types []string `protobuf:"bytes,2,rep,name=types,proto3" json:"types,omitempty"`
+	// Если задан, поток начинается с событий журнала после этого ID
+	AfterId       *uint64 `protobuf:"varint,3,opt,name=after_id,json=afterId,proto3,oneof" json:"after_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_dnsresolver_v1_resolver_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WatchEventsRequest) GetFqdnPatterns() []string {
+	if x != nil {
+		return x.FqdnPatterns
+	}
+	return nil
+}
+
+func (x *WatchEventsRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *WatchEventsRequest) GetAfterId() uint64 {
+	if x != nil && x.AfterId != nil {
+		return *x.AfterId
+	}
+	return 0
+}
+
+type Event struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Fqdn          string                 `protobuf:"bytes,3,opt,name=fqdn,proto3" json:"fqdn,omitempty"`
+	Ip            string                 `protobuf:"bytes,4,opt,name=ip,proto3" json:"ip,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_dnsresolver_v1_resolver_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_dnsresolver_v1_resolver_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Event) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetFqdn() string {
+	if x != nil {
+		return x.Fqdn
+	}
+	return ""
+}
+
+func (x *Event) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *Event) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Event) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+var File_dnsresolver_v1_resolver_proto protoreflect.FileDescriptor
+
+const file_dnsresolver_v1_resolver_proto_rawDesc = "" +
+	"\n" +
+	"\x1ddnsresolver/v1/resolver.proto\x12\x0ednsresolver.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"$\n" +
+	"\x0eAddFQDNRequest\x12\x12\n" +
+	"\x04fqdn\x18\x01 \x01(\tR\x04fqdn\"Z\n" +
+	"\x0fAddFQDNResponse\x12\x12\n" +
+	"\x04fqdn\x18\x01 \x01(\tR\x04fqdn\x12!\n" +
+	"\ffqdn_unicode\x18\x02 \x01(\tR\vfqdnUnicode\x12\x10\n" +
+	"\x03ips\x18\x03 \x03(\tR\x03ips\")\n" +
+	"\x13GetIPsByFQDNRequest\x12\x12\n" +
+	"\x04fqdn\x18\x01 \x01(\tR\x04fqdn\"_\n" +
+	"\x14GetIPsByFQDNResponse\x12\x12\n" +
+	"\x04fqdn\x18\x01 \x01(\tR\x04fqdn\x12!\n" +
+	"\ffqdn_unicode\x18\x02 \x01(\tR\vfqdnUnicode\x12\x10\n" +
+	"\x03ips\x18\x03 \x03(\tR\x03ips\"%\n" +
+	"\x13GetFQDNsByIPRequest\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\"a\n" +
+	"\x14GetFQDNsByIPResponse\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\x12\x14\n" +
+	"\x05fqdns\x18\x02 \x03(\tR\x05fqdns\x12#\n" +
+	"\rfqdns_unicode\x18\x03 \x03(\tR\ffqdnsUnicode\"\x12\n" +
+	"\x10ListFQDNsRequest\"N\n" +
+	"\x11ListFQDNsResponse\x12\x14\n" +
+	"\x05fqdns\x18\x01 \x03(\tR\x05fqdns\x12#\n" +
+	"\rfqdns_unicode\x18\x02 \x03(\tR\ffqdnsUnicode\"'\n" +
+	"\x11DeleteFQDNRequest\x12\x12\n" +
+	"\x04fqdn\x18\x01 \x01(\tR\x04fqdn\"\x14\n" +
+	"\x12DeleteFQDNResponse\"|\n" +
+	"\x12WatchEventsRequest\x12#\n" +
+	"\rfqdn_patterns\x18\x01 \x03(\tR\ffqdnPatterns\x12\x14\n" +
+	"\x05types\x18\x02 \x03(\tR\x05types\x12\x1e\n" +
+	"\bafter_id\x18\x03 \x01(\x04H\x00R\aafterId\x88\x01\x01B\v\n" +
+	"\t_after_id\"\xa2\x01\n" +
+	"\x05Event\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x12\n" +
+	"\x04fqdn\x18\x03 \x01(\tR\x04fqdn\x12\x0e\n" +
+	"\x02ip\x18\x04 \x01(\tR\x02ip\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12;\n" +
+	"\voccurred_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt2\x86\x04\n" +
+	"\x0fResolverService\x12J\n" +
+	"\aAddFQDN\x12\x1e.dnsresolver.v1.AddFQDNRequest\x1a\x1f.dnsresolver.v1.AddFQDNResponse\x12Y\n" +
+	"\fGetIPsByFQDN\x12#.dnsresolver.v1.GetIPsByFQDNRequest\x1a$.dnsresolver.v1.GetIPsByFQDNResponse\x12Y\n" +
+	"\fGetFQDNsByIP\x12#.dnsresolver.v1.GetFQDNsByIPRequest\x1a$.dnsresolver.v1.GetFQDNsByIPResponse\x12P\n" +
+	"\tListFQDNs\x12 .dnsresolver.v1.ListFQDNsRequest\x1a!.dnsresolver.v1.ListFQDNsResponse\x12S\n" +
+	"\n" +
+	"DeleteFQDN\x12!.dnsresolver.v1.DeleteFQDNRequest\x1a\".dnsresolver.v1.DeleteFQDNResponse\x12J\n" +
+	"\vWatchEvents\x12\".dnsresolver.v1.WatchEventsRequest\x1a\x15.dnsresolver.v1.Event0\x01B5Z3dns-resolver/internal/grpcapi/resolverv1;resolverv1b\x06proto3"
+
+var (
+	file_dnsresolver_v1_resolver_proto_rawDescOnce sync.Once
+	file_dnsresolver_v1_resolver_proto_rawDescData []byte
+)
+
+func file_dnsresolver_v1_resolver_proto_rawDescGZIP() []byte {
+	file_dnsresolver_v1_resolver_proto_rawDescOnce.Do(func() {
+		file_dnsresolver_v1_resolver_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_dnsresolver_v1_resolver_proto_rawDesc), len(file_dnsresolver_v1_resolver_proto_rawDesc)))
+	})
+	return file_dnsresolver_v1_resolver_proto_rawDescData
+}
+
+var file_dnsresolver_v1_resolver_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_dnsresolver_v1_resolver_proto_goTypes = []any{
+	(*AddFQDNRequest)(nil),        // 0: dnsresolver.v1.AddFQDNRequest
+	(*AddFQDNResponse)(nil),       // 1: dnsresolver.v1.AddFQDNResponse
+	(*GetIPsByFQDNRequest)(nil),   // 2: dnsresolver.v1.GetIPsByFQDNRequest
+	(*GetIPsByFQDNResponse)(nil),  // 3: dnsresolver.v1.GetIPsByFQDNResponse
+	(*GetFQDNsByIPRequest)(nil),   // 4: dnsresolver.v1.GetFQDNsByIPRequest
+	(*GetFQDNsByIPResponse)(nil),  // 5: dnsresolver.v1.GetFQDNsByIPResponse
+	(*ListFQDNsRequest)(nil),      // 6: dnsresolver.v1.ListFQDNsRequest
+	(*ListFQDNsResponse)(nil),     // 7: dnsresolver.v1.ListFQDNsResponse
+	(*DeleteFQDNRequest)(nil),     // 8: dnsresolver.v1.DeleteFQDNRequest
+	(*DeleteFQDNResponse)(nil),    // 9: dnsresolver.v1.DeleteFQDNResponse
+	(*WatchEventsRequest)(nil),    // 10: dnsresolver.v1.WatchEventsRequest
+	(*Event)(nil),                 // 11: dnsresolver.v1.Event
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+}
+var file_dnsresolver_v1_resolver_proto_depIdxs = []int32{
+	12, // 0: dnsresolver.v1.Event.occurred_at:type_name -> google.protobuf.Timestamp
+	0,  // 1: dnsresolver.v1.ResolverService.AddFQDN:input_type -> dnsresolver.v1.AddFQDNRequest
+	2,  // 2: dnsresolver.v1.ResolverService.GetIPsByFQDN:input_type -> dnsresolver.v1.GetIPsByFQDNRequest
+	4,  // 3: dnsresolver.v1.ResolverService.GetFQDNsByIP:input_type -> dnsresolver.v1.GetFQDNsByIPRequest
+	6,  // 4: dnsresolver.v1.ResolverService.ListFQDNs:input_type -> dnsresolver.v1.ListFQDNsRequest
+	8,  // 5: dnsresolver.v1.ResolverService.DeleteFQDN:input_type -> dnsresolver.v1.DeleteFQDNRequest
+	10, // 6: dnsresolver.v1.ResolverService.WatchEvents:input_type -> dnsresolver.v1.WatchEventsRequest
+	1,  // 7: dnsresolver.v1.ResolverService.AddFQDN:output_type -> dnsresolver.v1.AddFQDNResponse
+	3,  // 8: dnsresolver.v1.ResolverService.GetIPsByFQDN:output_type -> dnsresolver.v1.GetIPsByFQDNResponse
+	5,  // 9: dnsresolver.v1.ResolverService.GetFQDNsByIP:output_type -> dnsresolver.v1.GetFQDNsByIPResponse
+	7,  // 10: dnsresolver.v1.ResolverService.ListFQDNs:output_type -> dnsresolver.v1.ListFQDNsResponse
+	9,  // 11: dnsresolver.v1.ResolverService.DeleteFQDN:output_type -> dnsresolver.v1.DeleteFQDNResponse
+	11, // 12: dnsresolver.v1.ResolverService.WatchEvents:output_type -> dnsresolver.v1.Event
+	7,  // [7:13] is the sub-list for method output_type
+	1,  // [1:7] is the sub-list for method input_type
+	1,  // [1:1] is the sub-list for extension type_name
+	1,  // [1:1] is the sub-list for extension extendee
+	0,  // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_dnsresolver_v1_resolver_proto_init() }
+func file_dnsresolver_v1_resolver_proto_init() {
+	if File_dnsresolver_v1_resolver_proto != nil {
+		return
+	}
+	file_dnsresolver_v1_resolver_proto_msgTypes[10].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_dnsresolver_v1_resolver_proto_rawDesc), len(file_dnsresolver_v1_resolver_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_dnsresolver_v1_resolver_proto_goTypes,
+		DependencyIndexes: file_dnsresolver_v1_resolver_proto_depIdxs,
+		MessageInfos:      file_dnsresolver_v1_resolver_proto_msgTypes,
+	}.Build()
+	File_dnsresolver_v1_resolver_proto = out.File
+	file_dnsresolver_v1_resolver_proto_goTypes = nil
+	file_dnsresolver_v1_resolver_proto_depIdxs = nil
+}
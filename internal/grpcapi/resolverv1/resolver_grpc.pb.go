@@ -0,0 +1,327 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: dnsresolver/v1/resolver.proto
+
+package resolverv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ResolverService_AddFQDN_FullMethodName      = "/dnsresolver.v1.ResolverService/AddFQDN"
+	ResolverService_GetIPsByFQDN_FullMethodName = "/dnsresolver.v1.ResolverService/GetIPsByFQDN"
+	ResolverService_GetFQDNsByIP_FullMethodName = "/dnsresolver.v1.ResolverService/GetFQDNsByIP"
+	ResolverService_ListFQDNs_FullMethodName    = "/dnsresolver.v1.ResolverService/ListFQDNs"
+	ResolverService_DeleteFQDN_FullMethodName   = "/dnsresolver.v1.ResolverService/DeleteFQDN"
+	ResolverService_WatchEvents_FullMethodName  = "/dnsresolver.v1.ResolverService/WatchEvents"
+)
+
+// ResolverServiceClient is the client API for ResolverService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ResolverService повторяет операции REST API над FQDN.
+// Учетные данные передаются в метаданных x-api-key или authorization: Bearer
+type ResolverServiceClient interface {
+	// AddFQDN резолвит имя и начинает его отслеживать. Требует роль editor
+	AddFQDN(ctx context.Context, in *AddFQDNRequest, opts ...grpc.CallOption) (*AddFQDNResponse, error)
+	GetIPsByFQDN(ctx context.Context, in *GetIPsByFQDNRequest, opts ...grpc.CallOption) (*GetIPsByFQDNResponse, error)
+	GetFQDNsByIP(ctx context.Context, in *GetFQDNsByIPRequest, opts ...grpc.CallOption) (*GetFQDNsByIPResponse, error)
+	ListFQDNs(ctx context.Context, in *ListFQDNsRequest, opts ...grpc.CallOption) (*ListFQDNsResponse, error)
+	// DeleteFQDN прекращает отслеживание и удаляет записи. Требует роль editor
+	DeleteFQDN(ctx context.Context, in *DeleteFQDNRequest, opts ...grpc.CallOption) (*DeleteFQDNResponse, error)
+	// WatchEvents отдает изменения записей; с after_id сначала догоняет журнал
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type resolverServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewResolverServiceClient(cc grpc.ClientConnInterface) ResolverServiceClient {
+	return &resolverServiceClient{cc}
+}
+
+func (c *resolverServiceClient) AddFQDN(ctx context.Context, in *AddFQDNRequest, opts ...grpc.CallOption) (*AddFQDNResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddFQDNResponse)
+	err := c.cc.Invoke(ctx, ResolverService_AddFQDN_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resolverServiceClient) GetIPsByFQDN(ctx context.Context, in *GetIPsByFQDNRequest, opts ...grpc.CallOption) (*GetIPsByFQDNResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetIPsByFQDNResponse)
+	err := c.cc.Invoke(ctx, ResolverService_GetIPsByFQDN_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resolverServiceClient) GetFQDNsByIP(ctx context.Context, in *GetFQDNsByIPRequest, opts ...grpc.CallOption) (*GetFQDNsByIPResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetFQDNsByIPResponse)
+	err := c.cc.Invoke(ctx, ResolverService_GetFQDNsByIP_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resolverServiceClient) ListFQDNs(ctx context.Context, in *ListFQDNsRequest, opts ...grpc.CallOption) (*ListFQDNsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFQDNsResponse)
+	err := c.cc.Invoke(ctx, ResolverService_ListFQDNs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resolverServiceClient) DeleteFQDN(ctx context.Context, in *DeleteFQDNRequest, opts ...grpc.CallOption) (*DeleteFQDNResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteFQDNResponse)
+	err := c.cc.Invoke(ctx, ResolverService_DeleteFQDN_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resolverServiceClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ResolverService_ServiceDesc.Streams[0], ResolverService_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ResolverService_WatchEventsClient = grpc.ServerStreamingClient[Event]
+
+// ResolverServiceServer is the server API for ResolverService service.
+// All implementations must embed UnimplementedResolverServiceServer
+// for forward compatibility.
+//
+// ResolverService повторяет операции REST API над FQDN.
+// Учетные данные передаются в метаданных x-api-key или authorization: Bearer
+type ResolverServiceServer interface {
+	// AddFQDN резолвит имя и начинает его отслеживать. Требует роль editor
+	AddFQDN(context.Context, *AddFQDNRequest) (*AddFQDNResponse, error)
+	GetIPsByFQDN(context.Context, *GetIPsByFQDNRequest) (*GetIPsByFQDNResponse, error)
+	GetFQDNsByIP(context.Context, *GetFQDNsByIPRequest) (*GetFQDNsByIPResponse, error)
+	ListFQDNs(context.Context, *ListFQDNsRequest) (*ListFQDNsResponse, error)
+	// DeleteFQDN прекращает отслеживание и удаляет записи. Требует роль editor
+	DeleteFQDN(context.Context, *DeleteFQDNRequest) (*DeleteFQDNResponse, error)
+	// WatchEvents отдает изменения записей; с after_id сначала догоняет журнал
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedResolverServiceServer()
+}
+
+// UnimplementedResolverServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedResolverServiceServer struct{}
+
+func (UnimplementedResolverServiceServer) AddFQDN(context.Context, *AddFQDNRequest) (*AddFQDNResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddFQDN not implemented")
+}
+func (UnimplementedResolverServiceServer) GetIPsByFQDN(context.Context, *GetIPsByFQDNRequest) (*GetIPsByFQDNResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetIPsByFQDN not implemented")
+}
+func (UnimplementedResolverServiceServer) GetFQDNsByIP(context.Context, *GetFQDNsByIPRequest) (*GetFQDNsByIPResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFQDNsByIP not implemented")
+}
+func (UnimplementedResolverServiceServer) ListFQDNs(context.Context, *ListFQDNsRequest) (*ListFQDNsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFQDNs not implemented")
+}
+func (UnimplementedResolverServiceServer) DeleteFQDN(context.Context, *DeleteFQDNRequest) (*DeleteFQDNResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteFQDN not implemented")
+}
+func (UnimplementedResolverServiceServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedResolverServiceServer) mustEmbedUnimplementedResolverServiceServer() {}
+func (UnimplementedResolverServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeResolverServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ResolverServiceServer will
+// result in compilation errors.
+type UnsafeResolverServiceServer interface {
+	mustEmbedUnimplementedResolverServiceServer()
+}
+
+func RegisterResolverServiceServer(s grpc.ServiceRegistrar, srv ResolverServiceServer) {
+	// If the following call pancis, it indicates UnimplementedResolverServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ResolverService_ServiceDesc, srv)
+}
+
+func _ResolverService_AddFQDN_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddFQDNRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResolverServiceServer).AddFQDN(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResolverService_AddFQDN_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResolverServiceServer).AddFQDN(ctx, req.(*AddFQDNRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResolverService_GetIPsByFQDN_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetIPsByFQDNRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResolverServiceServer).GetIPsByFQDN(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResolverService_GetIPsByFQDN_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResolverServiceServer).GetIPsByFQDN(ctx, req.(*GetIPsByFQDNRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResolverService_GetFQDNsByIP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFQDNsByIPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResolverServiceServer).GetFQDNsByIP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResolverService_GetFQDNsByIP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResolverServiceServer).GetFQDNsByIP(ctx, req.(*GetFQDNsByIPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResolverService_ListFQDNs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFQDNsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResolverServiceServer).ListFQDNs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResolverService_ListFQDNs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResolverServiceServer).ListFQDNs(ctx, req.(*ListFQDNsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResolverService_DeleteFQDN_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFQDNRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResolverServiceServer).DeleteFQDN(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResolverService_DeleteFQDN_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResolverServiceServer).DeleteFQDN(ctx, req.(*DeleteFQDNRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResolverService_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ResolverServiceServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ResolverService_WatchEventsServer = grpc.ServerStreamingServer[Event]
+
+// ResolverService_ServiceDesc is the grpc.ServiceDesc for ResolverService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ResolverService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "dnsresolver.v1.ResolverService",
+	HandlerType: (*ResolverServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddFQDN",
+			Handler:    _ResolverService_AddFQDN_Handler,
+		},
+		{
+			MethodName: "GetIPsByFQDN",
+			Handler:    _ResolverService_GetIPsByFQDN_Handler,
+		},
+		{
+			MethodName: "GetFQDNsByIP",
+			Handler:    _ResolverService_GetFQDNsByIP_Handler,
+		},
+		{
+			MethodName: "ListFQDNs",
+			Handler:    _ResolverService_ListFQDNs_Handler,
+		},
+		{
+			MethodName: "DeleteFQDN",
+			Handler:    _ResolverService_DeleteFQDN_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _ResolverService_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "dnsresolver/v1/resolver.proto",
+}
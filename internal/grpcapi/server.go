@@ -0,0 +1,185 @@
+// Пакет grpcapi отдает операции резолвера по gRPC, параллельно с REST API.
+// Аутентификация, роли и аудит те же, что у REST
+package grpcapi
+
+import (
+	"context"
+	"dns-resolver/internal/auth"
+	dnsresolver "dns-resolver/internal/dns_resolver"
+	"dns-resolver/internal/grpcapi/resolverv1"
+	"dns-resolver/internal/logging"
+	"dns-resolver/internal/models"
+	"dns-resolver/internal/ratelimit"
+	"dns-resolver/internal/validator"
+	"errors"
+	"log/slog"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type Server struct {
+	resolverv1.UnimplementedResolverServiceServer
+
+	resolver *dnsresolver.Resolver
+	auth     *auth.Authenticator
+	limiter  *ratelimit.Limiter
+	logger   *slog.Logger
+}
+
+// NewServer создает gRPC-сервер с ResolverService и перехватчиками
+// аутентификации, проверки ролей, ограничения частоты и аудита.
+// limiter — общий с REST; nil отключает ограничение
+func NewServer(resolver *dnsresolver.Resolver, authenticator *auth.Authenticator, limiter *ratelimit.Limiter, opts ...grpc.ServerOption) *grpc.Server {
+	s := &Server{
+		resolver: resolver,
+		auth:     authenticator,
+		limiter:  limiter,
+		logger:   logging.Component("grpc"),
+	}
+
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(s.recoverUnary, s.unaryInterceptor),
+		grpc.ChainStreamInterceptor(s.recoverStream, s.streamInterceptor),
+	)
+	srv := grpc.NewServer(opts...)
+	resolverv1.RegisterResolverServiceServer(srv, s)
+	return srv
+}
+
+func (s *Server) AddFQDN(ctx context.Context, req *resolverv1.AddFQDNRequest) (*resolverv1.AddFQDNResponse, error) {
+	fqdn, err := validator.CanonicalFQDN(req.GetFqdn())
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+
+	ips, err := s.resolver.Resolve(ctx, fqdn)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+
+	return &resolverv1.AddFQDNResponse{
+		Fqdn:        fqdn,
+		FqdnUnicode: validator.UnicodeFQDN(fqdn),
+		Ips:         ips,
+	}, nil
+}
+
+func (s *Server) GetIPsByFQDN(ctx context.Context, req *resolverv1.GetIPsByFQDNRequest) (*resolverv1.GetIPsByFQDNResponse, error) {
+	fqdn, err := validator.CanonicalFQDN(req.GetFqdn())
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+
+	ips, err := s.resolver.GetIPsByFQDN(ctx, fqdn)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+
+	return &resolverv1.GetIPsByFQDNResponse{
+		Fqdn:        fqdn,
+		FqdnUnicode: validator.UnicodeFQDN(fqdn),
+		Ips:         ips,
+	}, nil
+}
+
+func (s *Server) GetFQDNsByIP(ctx context.Context, req *resolverv1.GetFQDNsByIPRequest) (*resolverv1.GetFQDNsByIPResponse, error) {
+	if req.GetIp() == "" {
+		return nil, status.Error(codes.InvalidArgument, "ip is required")
+	}
+
+	fqdns, err := s.resolver.GetFQDNsByIP(ctx, req.GetIp())
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+
+	return &resolverv1.GetFQDNsByIPResponse{
+		Ip:           req.GetIp(),
+		Fqdns:        fqdns,
+		FqdnsUnicode: unicodeFQDNs(fqdns),
+	}, nil
+}
+
+func (s *Server) ListFQDNs(ctx context.Context, _ *resolverv1.ListFQDNsRequest) (*resolverv1.ListFQDNsResponse, error) {
+	fqdns, err := s.resolver.GetAllFQDNs(ctx)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+
+	return &resolverv1.ListFQDNsResponse{
+		Fqdns:        fqdns,
+		FqdnsUnicode: unicodeFQDNs(fqdns),
+	}, nil
+}
+
+func (s *Server) DeleteFQDN(ctx context.Context, req *resolverv1.DeleteFQDNRequest) (*resolverv1.DeleteFQDNResponse, error) {
+	if err := s.resolver.Remove(ctx, req.GetFqdn()); err != nil {
+		return nil, statusFromError(err)
+	}
+	return &resolverv1.DeleteFQDNResponse{}, nil
+}
+
+// WatchEvents отдает события так же, как GET /api/events: after_id играет роль Last-Event-ID
+func (s *Server) WatchEvents(req *resolverv1.WatchEventsRequest, stream grpc.ServerStreamingServer[resolverv1.Event]) error {
+	ctx := stream.Context()
+
+	filter := models.EventFilter{
+		TenantID: models.TenantID(ctx),
+		Patterns: req.GetFqdnPatterns(),
+	}
+	for _, t := range req.GetTypes() {
+		filter.Types = append(filter.Types, models.EventType(t))
+	}
+	if err := filter.Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	send := func(ev models.Event) error {
+		return stream.Send(&resolverv1.Event{
+			Id:         uint64(ev.ID),
+			Type:       string(ev.Type),
+			Fqdn:       ev.FQDN,
+			Ip:         ev.IP,
+			Error:      ev.Error,
+			OccurredAt: timestamppb.New(ev.OccurredAt),
+		})
+	}
+
+	// Keepalive обеспечивает сам HTTP/2, отдельный heartbeat не нужен
+	err := s.resolver.Stream(ctx, filter, uint(req.GetAfterId()), req.AfterId != nil, send, nil)
+	if err != nil && ctx.Err() == nil {
+		return statusFromError(err)
+	}
+	return nil
+}
+
+func unicodeFQDNs(fqdns []string) []string {
+	res := make([]string, len(fqdns))
+	for i, fqdn := range fqdns {
+		res[i] = validator.UnicodeFQDN(fqdn)
+	}
+	return res
+}
+
+// statusFromError сопоставляет ошибки резолвера и хранилища кодам gRPC
+// так же, как problemFromError сопоставляет их HTTP-статусам
+func statusFromError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	code := codes.Internal
+	switch {
+	case errors.Is(err, validator.ErrInvalidFQDN):
+		code = codes.InvalidArgument
+	case errors.Is(err, models.ErrNotFound), errors.Is(err, dnsresolver.ErrNXDomain):
+		code = codes.NotFound
+	case errors.Is(err, dnsresolver.ErrTimeout):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, dnsresolver.ErrLookupFailed), errors.Is(err, models.ErrUnavailable):
+		code = codes.Unavailable
+	}
+	return status.Error(code, err.Error())
+}
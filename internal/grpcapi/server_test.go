@@ -0,0 +1,363 @@
+package grpcapi
+
+import (
+	"context"
+	"dns-resolver/internal/auth"
+	dnsresolver "dns-resolver/internal/dns_resolver"
+	"dns-resolver/internal/grpcapi/resolverv1"
+	"dns-resolver/internal/logging"
+	"dns-resolver/internal/models"
+	"dns-resolver/internal/ratelimit"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	editorKey = "dnsr_editor"
+	viewerKey = "dnsr_viewer"
+)
+
+// MockRepository хранит записи и журналы в памяти
+type MockRepository struct {
+	models.Repository
+
+	mu      sync.Mutex
+	records map[string][]string
+	events  []models.Event
+	audit   []models.AuditEntry
+}
+
+func newMockRepository() *MockRepository {
+	return &MockRepository{records: map[string][]string{
+		"example.com": {"1.1.1.1"},
+		"example.org": {"1.1.1.1", "2.2.2.2"},
+	}}
+}
+
+func (m *MockRepository) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	switch hash {
+	case models.HashAPIKey(editorKey):
+		return &models.APIKey{ID: 1, TenantID: models.DefaultTenantID, Role: models.RoleEditor}, nil
+	case models.HashAPIKey(viewerKey):
+		return &models.APIKey{ID: 2, TenantID: models.DefaultTenantID, Role: models.RoleViewer}, nil
+	}
+	return nil, models.ErrNotFound
+}
+
+func (m *MockRepository) GetAllFQDNs(ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var res []string
+	for fqdn := range m.records {
+		res = append(res, fqdn)
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+func (m *MockRepository) GetIPsByFQDN(ctx context.Context, fqdn string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.records[fqdn]...), nil
+}
+
+func (m *MockRepository) GetFQDNsByIP(ctx context.Context, ip string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var res []string
+	for fqdn, ips := range m.records {
+		for _, v := range ips {
+			if v == ip {
+				res = append(res, fqdn)
+			}
+		}
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+func (m *MockRepository) DeleteRecord(ctx context.Context, fqdn, ip string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var rest []string
+	for _, v := range m.records[fqdn] {
+		if v != ip {
+			rest = append(rest, v)
+		}
+	}
+	if len(rest) == 0 {
+		delete(m.records, fqdn)
+	} else {
+		m.records[fqdn] = rest
+	}
+	return nil
+}
+
+func (m *MockRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (m *MockRepository) AppendEvent(ctx context.Context, ev *models.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ev.ID = uint(len(m.events) + 1)
+	m.events = append(m.events, *ev)
+	return nil
+}
+
+func (m *MockRepository) ListEventsSince(ctx context.Context, afterID uint, limit int) ([]models.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var res []models.Event
+	for _, ev := range m.events {
+		if ev.ID > afterID && len(res) < limit {
+			res = append(res, ev)
+		}
+	}
+	return res, nil
+}
+
+func (m *MockRepository) AppendAudit(ctx context.Context, entry *models.AuditEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry.TenantID = models.TenantID(ctx)
+	m.audit = append(m.audit, *entry)
+	return nil
+}
+
+// startServer поднимает сервер на bufconn и возвращает клиента к нему
+func startServer(t *testing.T, repo *MockRepository) resolverv1.ResolverServiceClient {
+	return startLimitedServer(t, repo, nil)
+}
+
+func startLimitedServer(t *testing.T, repo *MockRepository, limiter *ratelimit.Limiter) resolverv1.ResolverServiceClient {
+	lis := bufconn.Listen(1 << 20)
+	resolver := dnsresolver.NewResolver(repo)
+	srv := NewServer(resolver, auth.NewAuthenticator(repo, "", nil), limiter)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return resolverv1.NewResolverServiceClient(conn)
+}
+
+func withKey(ctx context.Context, key string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, APIKeyMetadata, key)
+}
+
+func TestServer(t *testing.T) {
+	repo := newMockRepository()
+	client := startServer(t, repo)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("Missing or invalid API key", func(t *testing.T) {
+		_, err := client.ListFQDNs(ctx, &resolverv1.ListFQDNsRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = client.ListFQDNs(withKey(ctx, "dnsr_unknown"), &resolverv1.ListFQDNsRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Bearer token in authorization metadata", func(t *testing.T) {
+		md := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+viewerKey)
+		resp, err := client.ListFQDNs(md, &resolverv1.ListFQDNsRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"example.com", "example.org"}, resp.GetFqdns())
+	})
+
+	t.Run("GetIPsByFQDN canonicalizes the name", func(t *testing.T) {
+		resp, err := client.GetIPsByFQDN(withKey(ctx, viewerKey), &resolverv1.GetIPsByFQDNRequest{Fqdn: "Example.COM."})
+		require.NoError(t, err)
+		assert.Equal(t, "example.com", resp.GetFqdn())
+		assert.Equal(t, []string{"1.1.1.1"}, resp.GetIps())
+	})
+
+	t.Run("GetFQDNsByIP", func(t *testing.T) {
+		resp, err := client.GetFQDNsByIP(withKey(ctx, viewerKey), &resolverv1.GetFQDNsByIPRequest{Ip: "1.1.1.1"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"example.com", "example.org"}, resp.GetFqdns())
+		assert.Len(t, resp.GetFqdnsUnicode(), 2)
+
+		_, err = client.GetFQDNsByIP(withKey(ctx, viewerKey), &resolverv1.GetFQDNsByIPRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("AddFQDN rejects invalid names", func(t *testing.T) {
+		_, err := client.AddFQDN(withKey(ctx, editorKey), &resolverv1.AddFQDNRequest{Fqdn: "bad_name..com"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Viewer key is read-only", func(t *testing.T) {
+		_, err := client.AddFQDN(withKey(ctx, viewerKey), &resolverv1.AddFQDNRequest{Fqdn: "example.net"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		_, err = client.DeleteFQDN(withKey(ctx, viewerKey), &resolverv1.DeleteFQDNRequest{Fqdn: "example.com"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		entry := repo.audit[len(repo.audit)-1]
+		assert.Equal(t, "fqdn.delete", entry.Action)
+		assert.Equal(t, "key:2", entry.Actor)
+		assert.Equal(t, http.StatusForbidden, entry.Status)
+		assert.Equal(t, models.AuditFailure, entry.Result)
+	})
+
+	t.Run("Unauthenticated mutation is not audited", func(t *testing.T) {
+		repo.mu.Lock()
+		before := len(repo.audit)
+		repo.mu.Unlock()
+
+		_, err := client.AddFQDN(ctx, &resolverv1.AddFQDNRequest{Fqdn: "example.net"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		assert.Len(t, repo.audit, before)
+	})
+
+	t.Run("DeleteFQDN is streamed and audited", func(t *testing.T) {
+		// after_id = 0 догоняет журнал, поэтому событие не теряется,
+		// даже если удаление успеет раньше подписки
+		stream, err := client.WatchEvents(withKey(ctx, viewerKey), &resolverv1.WatchEventsRequest{
+			FqdnPatterns: []string{"*.org"},
+			Types:        []string{string(models.EventIPRemoved)},
+			AfterId:      proto.Uint64(0),
+		})
+		require.NoError(t, err)
+
+		_, err = client.DeleteFQDN(withKey(ctx, editorKey), &resolverv1.DeleteFQDNRequest{Fqdn: "Example.ORG"})
+		require.NoError(t, err)
+
+		var ips []string
+		for range 2 {
+			ev, err := stream.Recv()
+			require.NoError(t, err)
+			assert.Equal(t, "example.org", ev.GetFqdn())
+			assert.Equal(t, string(models.EventIPRemoved), ev.GetType())
+			assert.NotZero(t, ev.GetId())
+			ips = append(ips, ev.GetIp())
+		}
+		assert.ElementsMatch(t, []string{"1.1.1.1", "2.2.2.2"}, ips)
+
+		_, err = client.DeleteFQDN(withKey(ctx, editorKey), &resolverv1.DeleteFQDNRequest{Fqdn: "example.org"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		var entries []models.AuditEntry
+		for _, e := range repo.audit {
+			if e.Action == "fqdn.delete" && e.Actor == "key:1" {
+				entries = append(entries, e)
+			}
+		}
+		require.Len(t, entries, 2)
+		assert.Equal(t, "key:1", entries[0].Actor)
+		assert.Equal(t, "example.org", entries[0].Target)
+		assert.Equal(t, models.AuditSuccess, entries[0].Result)
+		assert.Equal(t, models.AuditFailure, entries[1].Result)
+		assert.Equal(t, http.StatusNotFound, entries[1].Status)
+	})
+
+	t.Run("Request ID is returned and audited", func(t *testing.T) {
+		var header metadata.MD
+		_, err := client.ListFQDNs(withKey(ctx, viewerKey), &resolverv1.ListFQDNsRequest{}, grpc.Header(&header))
+		require.NoError(t, err)
+		assert.Len(t, metadataValue(header, requestIDMetadata), 32)
+
+		md := metadata.AppendToOutgoingContext(withKey(ctx, editorKey), requestIDMetadata, "req-42")
+		_, err = client.DeleteFQDN(md, &resolverv1.DeleteFQDNRequest{Fqdn: "unknown.example"}, grpc.Header(&header))
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Equal(t, "req-42", metadataValue(header, requestIDMetadata))
+
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		assert.Equal(t, "req-42", repo.audit[len(repo.audit)-1].RequestID)
+	})
+
+	t.Run("WatchEvents invalid type", func(t *testing.T) {
+		stream, err := client.WatchEvents(withKey(ctx, viewerKey), &resolverv1.WatchEventsRequest{Types: []string{"ip_changed"}})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestRateLimiting(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Config{
+		Read:  ratelimit.Budget{Rate: 100, Burst: 100},
+		Write: ratelimit.Budget{Rate: 0.01, Burst: 1},
+	})
+	client := startLimitedServer(t, newMockRepository(), limiter)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Первый изменяющий вызов проходит до обработчика, второй упирается в бюджет записи
+	_, err := client.AddFQDN(withKey(ctx, editorKey), &resolverv1.AddFQDNRequest{Fqdn: "bad_name..com"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.AddFQDN(withKey(ctx, editorKey), &resolverv1.AddFQDNRequest{Fqdn: "example.net"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Лимит по адресу действует и без валидного ключа
+	_, err = client.DeleteFQDN(withKey(ctx, "dnsr_unknown"), &resolverv1.DeleteFQDNRequest{Fqdn: "example.com"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Чтение расходует отдельный бюджет
+	_, err = client.ListFQDNs(withKey(ctx, viewerKey), &resolverv1.ListFQDNsRequest{})
+	assert.NoError(t, err)
+
+	// Бюджет общий с REST: те же ключи в том же ограничителе
+	assert.False(t, limiter.Allow(fmt.Sprintf("tenant:%d:%s", models.DefaultTenantID, "key:1"), ratelimit.Write).Allowed)
+}
+
+func TestRecovery(t *testing.T) {
+	s := &Server{logger: logging.Component("grpc")}
+	info := &grpc.UnaryServerInfo{FullMethod: resolverv1.ResolverService_AddFQDN_FullMethodName}
+
+	// models.TenantID паникует без арендатора в контексте
+	_, err := s.recoverUnary(context.Background(), nil, info, func(ctx context.Context, _ any) (any, error) {
+		return models.TenantID(ctx), nil
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	err = s.recoverStream(nil, &serverStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: resolverv1.ResolverService_WatchEvents_FullMethodName},
+		func(any, grpc.ServerStream) error { panic("boom") })
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestPeerIP(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 50051}})
+	addr, ok := peerIP(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "2001:db8::1", addr)
+
+	_, ok = peerIP(context.Background())
+	assert.False(t, ok)
+}
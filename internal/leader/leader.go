@@ -0,0 +1,137 @@
+package leader
+
+import (
+	"context"
+	"dns-resolver/internal/logging"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultRetryInterval = 2 * time.Second
+	DefaultRenewInterval = 2 * time.Second
+
+	releaseTimeout = 5 * time.Second
+)
+
+// Lease — удерживаемая блокировка лидера
+type Lease interface {
+	// Renew подтверждает, что блокировка все еще удерживается
+	Renew(ctx context.Context) error
+	Release(ctx context.Context) error
+}
+
+// Locker берет блокировку лидера без ожидания; ok=false — она занята другим
+type Locker interface {
+	TryAcquire(ctx context.Context) (lease Lease, ok bool, err error)
+}
+
+// Elector выбирает одного лидера среди реплик. Реплики без блокировки
+// периодически пытаются ее взять, лидер — продлевает
+type Elector struct {
+	locker        Locker
+	retryInterval time.Duration
+	renewInterval time.Duration
+	logger        *slog.Logger
+
+	leader atomic.Bool
+}
+
+type Option func(*Elector)
+
+// WithRetryInterval задает, как часто реплика пытается стать лидером
+func WithRetryInterval(d time.Duration) Option {
+	return func(e *Elector) {
+		e.retryInterval = d
+	}
+}
+
+// WithRenewInterval задает, как часто лидер продлевает блокировку.
+// Он же служит таймаутом продления
+func WithRenewInterval(d time.Duration) Option {
+	return func(e *Elector) {
+		e.renewInterval = d
+	}
+}
+
+func NewElector(locker Locker, opts ...Option) *Elector {
+	e := &Elector{
+		locker:        locker,
+		retryInterval: DefaultRetryInterval,
+		renewInterval: DefaultRenewInterval,
+		logger:        logging.Component("leader"),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Run участвует в выборах до отмены ctx. Пока реплика лидер, выполняется lead;
+// его контекст отменяется, как только блокировка потеряна
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context)) {
+	for {
+		lease, ok, err := e.locker.TryAcquire(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			e.logger.ErrorContext(ctx, "Failed to acquire leadership", "error", err)
+		case ok:
+			e.hold(ctx, lease, lead)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.retryInterval):
+		}
+	}
+}
+
+// hold выполняет lead, пока продление блокировки успешно
+func (e *Elector) hold(ctx context.Context, lease Lease, lead func(ctx context.Context)) {
+	e.logger.InfoContext(ctx, "Acquired leadership")
+	e.leader.Store(true)
+
+	leadCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lead(leadCtx)
+	}()
+
+	ticker := time.NewTicker(e.renewInterval)
+	for renewed := true; renewed; {
+		select {
+		case <-ctx.Done():
+			renewed = false
+		case <-done:
+			renewed = false
+		case <-ticker.C:
+			renewCtx, cancelRenew := context.WithTimeout(ctx, e.renewInterval)
+			if err := lease.Renew(renewCtx); err != nil && ctx.Err() == nil {
+				e.logger.WarnContext(ctx, "Lost leadership", "error", err)
+				renewed = false
+			}
+			cancelRenew()
+		}
+	}
+	ticker.Stop()
+
+	// Сначала останавливаем работу лидера, затем отдаем блокировку,
+	// чтобы две реплики не работали одновременно
+	e.leader.Store(false)
+	cancel()
+	<-done
+
+	releaseCtx, cancelRelease := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancelRelease()
+	if err := lease.Release(releaseCtx); err != nil {
+		e.logger.ErrorContext(ctx, "Failed to release leadership", "error", err)
+	}
+	e.logger.InfoContext(ctx, "Released leadership")
+}
@@ -0,0 +1,183 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memLocker — блокировка в памяти, общая для нескольких Elector
+type memLocker struct {
+	mu     sync.Mutex
+	holder *memLease
+}
+
+type memLease struct {
+	locker *memLocker
+	lost   atomic.Bool
+}
+
+func (l *memLocker) TryAcquire(ctx context.Context) (Lease, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.holder != nil {
+		return nil, false, nil
+	}
+	l.holder = &memLease{locker: l}
+	return l.holder, true, nil
+}
+
+// drop имитирует обрыв сессии лидера: блокировка свободна, лидер узнает
+// об этом только при следующем продлении
+func (l *memLocker) drop() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.holder != nil {
+		l.holder.lost.Store(true)
+		l.holder = nil
+	}
+}
+
+func (l *memLease) Renew(ctx context.Context) error {
+	if l.lost.Load() {
+		return errors.New("session closed")
+	}
+	return nil
+}
+
+func (l *memLease) Release(ctx context.Context) error {
+	l.locker.mu.Lock()
+	defer l.locker.mu.Unlock()
+	if l.locker.holder == l {
+		l.locker.holder = nil
+	}
+	return nil
+}
+
+// replica запускает Elector и считает, сколько раз он становился лидером
+type replica struct {
+	elector *Elector
+	cancel  context.CancelFunc
+	done    chan struct{}
+	terms   atomic.Int32
+}
+
+func startReplica(locker Locker, leading *atomic.Int32, overlap *atomic.Bool) *replica {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &replica{
+		elector: NewElector(locker, WithRetryInterval(10*time.Millisecond), WithRenewInterval(10*time.Millisecond)),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	go func() {
+		defer close(r.done)
+		r.elector.Run(ctx, func(ctx context.Context) {
+			r.terms.Add(1)
+			if leading.Add(1) > 1 {
+				overlap.Store(true)
+			}
+			<-ctx.Done()
+			leading.Add(-1)
+		})
+	}()
+	return r
+}
+
+func (r *replica) stop() {
+	r.cancel()
+	<-r.done
+}
+
+func leaders(replicas []*replica) []*replica {
+	var res []*replica
+	for _, r := range replicas {
+		if r.elector.IsLeader() {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+func TestElector(t *testing.T) {
+	locker := &memLocker{}
+	var leading atomic.Int32
+	var overlap atomic.Bool
+
+	replicas := []*replica{
+		startReplica(locker, &leading, &overlap),
+		startReplica(locker, &leading, &overlap),
+		startReplica(locker, &leading, &overlap),
+	}
+	defer func() {
+		for _, r := range replicas {
+			r.stop()
+		}
+	}()
+
+	require.Eventually(t, func() bool { return len(leaders(replicas)) == 1 }, time.Second, time.Millisecond)
+	first := leaders(replicas)[0]
+
+	t.Run("Leader keeps the lock while renewals succeed", func(t *testing.T) {
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(t, []*replica{first}, leaders(replicas))
+		assert.Equal(t, int32(1), first.terms.Load())
+	})
+
+	t.Run("Lost session fails over", func(t *testing.T) {
+		locker.drop()
+		require.Eventually(t, func() bool {
+			l := leaders(replicas)
+			return len(l) == 1 && (l[0] != first || first.terms.Load() > 1)
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("Graceful shutdown hands over leadership", func(t *testing.T) {
+		current := leaders(replicas)[0]
+		current.stop()
+		require.Eventually(t, func() bool {
+			l := leaders(replicas)
+			return len(l) == 1 && l[0] != current
+		}, time.Second, time.Millisecond)
+	})
+
+	// Работа прежнего лидера останавливается до передачи блокировки
+	// (при drop — до следующей попытки продления)
+	assert.LessOrEqual(t, leading.Load(), int32(1))
+}
+
+func TestElector_NoOverlapOnRelease(t *testing.T) {
+	locker := &memLocker{}
+	var leading atomic.Int32
+	var overlap atomic.Bool
+
+	a := startReplica(locker, &leading, &overlap)
+	b := startReplica(locker, &leading, &overlap)
+	defer func() {
+		a.stop()
+		b.stop()
+	}()
+
+	require.Eventually(t, func() bool { return a.elector.IsLeader() || b.elector.IsLeader() }, time.Second, time.Millisecond)
+	for range 5 {
+		current, other := a, b
+		if b.elector.IsLeader() {
+			current, other = b, a
+		}
+		current.stop()
+		require.Eventually(t, other.elector.IsLeader, time.Second, time.Millisecond)
+
+		// Остановленная реплика возвращается в строй ведомой
+		if current == a {
+			a = startReplica(locker, &leading, &overlap)
+		} else {
+			b = startReplica(locker, &leading, &overlap)
+		}
+	}
+	assert.False(t, overlap.Load(), "two replicas led at the same time")
+}
@@ -0,0 +1,101 @@
+// Пакет logging настраивает структурированный журнал на log/slog и переносит
+// идентификаторы запроса и трассировки через контекст во все записи,
+// сделанные в его рамках
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+
+	// RequestIDKey — имя атрибута с идентификатором запроса
+	RequestIDKey = "request_id"
+	TraceIDKey   = "trace_id"
+	SpanIDKey    = "span_id"
+)
+
+// ParseLevel разбирает уровень debug, info, warn или error; пустая строка — info
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q", s)
+	}
+	return level, nil
+}
+
+// New создает журнал в формате json (по умолчанию) или text
+func New(w io.Writer, level slog.Leveler, format string) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level}
+
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case "", FormatJSON:
+		h = slog.NewJSONHandler(w, opts)
+	case FormatText:
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q: want %s or %s", format, FormatJSON, FormatText)
+	}
+	return slog.New(contextHandler{h}), nil
+}
+
+// Component возвращает журнал по умолчанию с атрибутом component
+func Component(name string) *slog.Logger {
+	return slog.Default().With("component", name)
+}
+
+type requestIDKey struct{}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID возвращает идентификатор запроса из контекста или пустую строку
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID выдает случайный идентификатор для запроса без своего
+func NewRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// contextHandler добавляет к записи идентификаторы запроса и спана из контекста
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String(RequestIDKey, id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String(TraceIDKey, sc.TraceID().String()), slog.String(SpanIDKey, sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
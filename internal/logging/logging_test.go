@@ -0,0 +1,63 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelInfo, level)
+
+	level, err = ParseLevel("DEBUG")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelDebug, level)
+
+	_, err = ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, slog.LevelInfo, "")
+	require.NoError(t, err)
+
+	ctx := WithRequestID(context.Background(), "req-42")
+	logger.With("component", "api").InfoContext(ctx, "resolved", "fqdn", "example.com")
+	logger.DebugContext(ctx, "filtered by level")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "resolved", entry["msg"])
+	assert.Equal(t, "api", entry["component"])
+	assert.Equal(t, "example.com", entry["fqdn"])
+	assert.Equal(t, "req-42", entry[RequestIDKey])
+	assert.NotContains(t, entry, TraceIDKey)
+
+	buf.Reset()
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	logger.InfoContext(ctx, "traced")
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, traceID.String(), entry[TraceIDKey])
+	assert.Equal(t, spanID.String(), entry[SpanIDKey])
+
+	buf.Reset()
+	logger, err = New(&buf, slog.LevelInfo, "text")
+	require.NoError(t, err)
+	logger.Info("no request")
+	assert.Contains(t, buf.String(), "msg=\"no request\"")
+	assert.NotContains(t, buf.String(), RequestIDKey)
+
+	_, err = New(&buf, slog.LevelInfo, "xml")
+	assert.Error(t, err)
+}
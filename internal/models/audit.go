@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// AuditEntry — запись журнала аудита об изменяющей операции API.
+// Журнал только пополняется: записи не изменяются и не удаляются
+type AuditEntry struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	TenantID  uint      `gorm:"not null;default:1;index" json:"-"`
+	Actor     string    `gorm:"not null;index" json:"actor"`
+	Action    string    `gorm:"not null;index" json:"action"`
+	Target    string    `gorm:"index" json:"target,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	SourceIP  string    `json:"source_ip"`
+	Result    string    `gorm:"not null" json:"result"`
+	Status    int       `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// AuditFilter отбирает записи аудита. Пустые поля не ограничивают выборку
+type AuditFilter struct {
+	Actor  string
+	Action string
+	Target string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+	Offset int
+}
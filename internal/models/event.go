@@ -0,0 +1,90 @@
+package models
+
+import (
+	"fmt"
+	"path"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventFQDNAdded     EventType = "fqdn_added"
+	EventIPAdded       EventType = "ip_added"
+	EventIPRemoved     EventType = "ip_removed"
+	EventNXDomain      EventType = "nxdomain"
+	EventResolveFailed EventType = "resolve_failed"
+)
+
+// Valid сообщает, известен ли тип события
+func (t EventType) Valid() bool {
+	switch t {
+	case EventFQDNAdded, EventIPAdded, EventIPRemoved, EventNXDomain, EventResolveFailed:
+		return true
+	}
+	return false
+}
+
+// Event описывает изменение DNS-записей, обнаруженное резолвером.
+// События хранятся в журнале, ID монотонно растет и служит курсором возобновления
+type Event struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	TenantID   uint      `gorm:"not null;default:1;index" json:"-"`
+	Type       EventType `gorm:"not null" json:"type"`
+	FQDN       string    `gorm:"not null;index" json:"fqdn"`
+	IP         string    `json:"ip,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	OccurredAt time.Time `gorm:"not null;index" json:"occurred_at"`
+}
+
+// EventFilter отбирает события по арендатору, glob-шаблонам FQDN и типам.
+// Нулевое значение поля означает отсутствие ограничения
+type EventFilter struct {
+	TenantID uint
+	Patterns []string
+	Types    []EventType
+}
+
+// Validate проверяет синтаксис шаблонов и типы событий из запроса клиента
+func (f EventFilter) Validate() error {
+	for _, p := range f.Patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid fqdn pattern %q", p)
+		}
+	}
+	for _, t := range f.Types {
+		if !t.Valid() {
+			return fmt.Errorf("unknown event type %q", t)
+		}
+	}
+	return nil
+}
+
+func (f EventFilter) Match(ev Event) bool {
+	if f.TenantID != 0 && f.TenantID != ev.TenantID {
+		return false
+	}
+
+	if len(f.Types) > 0 {
+		found := false
+		for _, t := range f.Types {
+			if t == ev.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(f.Patterns) == 0 {
+		return true
+	}
+	for _, p := range f.Patterns {
+		if ok, err := path.Match(p, ev.FQDN); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
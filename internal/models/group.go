@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Group объединяет отслеживаемые FQDN под общим именем (тегом),
+// например payments-egress. Один FQDN может входить в несколько групп
+type Group struct {
+	ID          uint      `gorm:"primarykey"`
+	TenantID    uint      `gorm:"not null;default:1;uniqueIndex:idx_groups_tenant_name"`
+	Name        string    `gorm:"not null;uniqueIndex:idx_groups_tenant_name"`
+	Description string    `gorm:"not null;default:''"`
+	CreatedAt   time.Time `gorm:"autoCreateTime;column:created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime;column:updated_at"`
+}
+
+type GroupMember struct {
+	GroupID   uint      `gorm:"primaryKey"`
+	FQDN      string    `gorm:"primaryKey;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at"`
+}
@@ -2,21 +2,81 @@ package models
 
 import (
 	"context"
+	"errors"
 	"time"
+)
 
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("already exists")
+	// ErrUnavailable — хранилище недоступно (нет соединения, таймаут)
+	ErrUnavailable = errors.New("storage unavailable")
 )
 
 type DNSRecord struct {
 	ID        uint      `gorm:"primarykey"`
+	TenantID  uint      `gorm:"not null;default:1;index"`
 	FQDN      string    `gorm:"not null;index"`
 	IP        string    `gorm:"not null;index"`
 	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at"`
 	UpdatedAt  time.Time `gorm:"autoUpdateTime;column:updated_at"`
 }
 
+// RecordVersion описывает состояние набора записей FQDN.
+// UpdatedAt пуст, если записей нет
+type RecordVersion struct {
+	Count     int64
+	UpdatedAt *time.Time
+}
+
 type Repository interface {
+	// Transaction выполняет fn в одной транзакции: вызовы репозитория
+	// с переданным в fn контекстом фиксируются или откатываются вместе
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+
 	AddOrUpdate(ctx context.Context, fqdn, ip string) error
 	GetIPsByFQDN(ctx context.Context, fqdn string) ([]string, error)
+	GetRecordVersion(ctx context.Context, fqdn string) (RecordVersion, error)
 	GetFQDNsByIP(ctx context.Context, ip string) ([]string, error)
 	GetAllFQDNs(ctx context.Context) ([]string, error)
+	DeleteRecord(ctx context.Context, fqdn, ip string) error
+	GetIPsByFQDNs(ctx context.Context, fqdns []string) (map[string][]string, error)
+	GetFQDNsByIPs(ctx context.Context, ips []string) (map[string][]string, error)
+	ListFQDNsByIP(ctx context.Context, ip string, q PageQuery) (Page, error)
+	ListIPsByFQDN(ctx context.Context, fqdn string, q PageQuery) (Page, error)
+	ListFQDNs(ctx context.Context, q PageQuery) (Page, error)
+	SearchFQDNs(ctx context.Context, search DomainQuery, q PageQuery) (Page, error)
+
+	CreateWebhook(ctx context.Context, hook *Webhook) error
+	ListWebhooks(ctx context.Context) ([]Webhook, error)
+	GetWebhook(ctx context.Context, id uint) (*Webhook, error)
+	DeleteWebhook(ctx context.Context, id uint) error
+	EnqueueDeliveries(ctx context.Context, deliveries []WebhookDelivery) error
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *WebhookDelivery) error
+	ListDeliveries(ctx context.Context, webhookID uint, limit int) ([]WebhookDelivery, error)
+
+	AppendEvent(ctx context.Context, ev *Event) error
+	ListEventsSince(ctx context.Context, afterID uint, limit int) ([]Event, error)
+
+	CreateGroup(ctx context.Context, group *Group) error
+	ListGroups(ctx context.Context, fqdn string) ([]Group, error)
+	GetGroup(ctx context.Context, name string) (*Group, error)
+	UpdateGroup(ctx context.Context, group *Group) error
+	DeleteGroup(ctx context.Context, name string) error
+	AddGroupMember(ctx context.Context, group, fqdn string) error
+	RemoveGroupMember(ctx context.Context, group, fqdn string) error
+	ListGroupMembers(ctx context.Context, group string) ([]string, error)
+	GetIPsByGroup(ctx context.Context, group string) ([]string, error)
+
+	CreateTenant(ctx context.Context, tenant *Tenant) error
+	ListTenants(ctx context.Context) ([]Tenant, error)
+	DeleteTenant(ctx context.Context, id uint) error
+	CreateAPIKey(ctx context.Context, key *APIKey) error
+	ListAPIKeys(ctx context.Context) ([]APIKey, error)
+	DeleteAPIKey(ctx context.Context, id uint) error
+	GetAPIKeyByHash(ctx context.Context, hash string) (*APIKey, error)
+
+	AppendAudit(ctx context.Context, entry *AuditEntry) error
+	ListAudit(ctx context.Context, filter AuditFilter) ([]AuditEntry, error)
 }
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type SortField string
+
+const (
+	SortByName      SortField = "name"
+	SortByUpdatedAt SortField = "updated_at"
+)
+
+// PageQuery задает страницу keyset-пагинации: страница начинается
+// сразу после элемента, на который указывает After. Limit 0 — все
+// элементы до конца выборки
+type PageQuery struct {
+	Limit int
+	Sort  SortField
+	Desc  bool
+	After *Cursor
+}
+
+// Cursor — позиция последнего элемента страницы. Хранит порядок сортировки,
+// чтобы курсор нельзя было применить к выборке с другим порядком
+type Cursor struct {
+	Sort      SortField  `json:"s"`
+	Desc      bool       `json:"d,omitempty"`
+	Name      string     `json:"n"`
+	UpdatedAt *time.Time `json:"u,omitempty"`
+}
+
+// Page — страница значений (FQDN или IP) и общее число совпадений
+type Page struct {
+	Items []string
+	Total int64
+	Next  *Cursor
+}
+
+// Encode возвращает курсор в непрозрачном для клиента виде
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(raw string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Name == "" {
+		return nil, ErrInvalidCursor
+	}
+	switch {
+	case c.Sort == SortByName && c.UpdatedAt == nil:
+	case c.Sort == SortByUpdatedAt && c.UpdatedAt != nil:
+	default:
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
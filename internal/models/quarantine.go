@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// QuarantinedRecord — строка с некорректным FQDN, убранная миграцией имен
+// к каноническому виду. Хранится для разбора вручную
+type QuarantinedRecord struct {
+	ID uint `gorm:"primarykey"`
+	// Source — таблица, из которой убрана строка: dns_records или group_members
+	Source    string    `gorm:"not null"`
+	TenantID  uint      `gorm:"not null;default:1"`
+	GroupID   *uint     `gorm:"column:group_id"`
+	FQDN      string    `gorm:"not null"`
+	IP        string    `gorm:"not null;default:''"`
+	Reason    string    `gorm:"not null;default:''"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// RateLimitSettingsID — ключ единственной строки бюджетов
+const RateLimitSettingsID = 1
+
+// RateLimitSettings — бюджеты ограничения частоты, заданные через API.
+// Хранятся в базе, чтобы их видели все реплики и после перезапуска
+type RateLimitSettings struct {
+	ID         uint      `gorm:"primarykey"`
+	ReadRate   float64   `gorm:"not null"`
+	ReadBurst  int       `gorm:"not null"`
+	WriteRate  float64   `gorm:"not null"`
+	WriteBurst int       `gorm:"not null"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime;column:updated_at"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrLeaseLost — аренда задания истекла и его забрал другой обработчик
+var ErrLeaseLost = errors.New("lease lost")
+
+// RefreshJob — строка очереди перепроверки FQDN. Задание доступно обработчикам,
+// когда подошел DueAt и у него нет действующей аренды
+type RefreshJob struct {
+	ID         uint      `gorm:"primarykey"`
+	TenantID   uint      `gorm:"not null;default:1;uniqueIndex:idx_refresh_jobs_tenant_fqdn"`
+	FQDN       string    `gorm:"not null;uniqueIndex:idx_refresh_jobs_tenant_fqdn"`
+	DueAt      time.Time `gorm:"not null;index"`
+	LeaseOwner string    `gorm:"not null;default:''"`
+	LeaseUntil *time.Time
+	// Claims растет при каждой выдаче и отличает текущую аренду от истекших
+	Claims int `gorm:"not null;default:0"`
+	// Attempts — число неудачных перепроверок подряд
+	Attempts  int       `gorm:"not null;default:0"`
+	LastError string    `gorm:"not null;default:''"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime;column:updated_at"`
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+type MatchMode string
+
+const (
+	MatchSubstring MatchMode = "substring"
+	MatchGlob      MatchMode = "glob"
+	MatchRegex     MatchMode = "regex"
+)
+
+// maxPatternLength ограничивает шаблон: регулярное выражение выполняется в Postgres
+const maxPatternLength = 256
+
+// ErrSearchTimeout — поиск по шаблону не уложился в отведенное время
+var ErrSearchTimeout = errors.New("search timed out")
+
+// DomainQuery отбирает отслеживаемые домены. Suffix — имя и все его
+// поддомены, Pattern сравнивается с FQDN целиком по правилам Match.
+// Пустые поля не ограничивают выборку
+type DomainQuery struct {
+	Suffix  string
+	Pattern string
+	Match   MatchMode
+}
+
+func (q DomainQuery) Validate() error {
+	if len(q.Pattern) > maxPatternLength {
+		return fmt.Errorf("pattern is longer than %d characters", maxPatternLength)
+	}
+
+	switch q.Match {
+	case "", MatchSubstring:
+		return nil
+	case MatchGlob:
+		if _, err := path.Match(q.Pattern, ""); err != nil {
+			return fmt.Errorf("invalid glob %q", q.Pattern)
+		}
+	case MatchRegex:
+	default:
+		return fmt.Errorf("unknown match mode %q", q.Match)
+	}
+
+	// Выражение выполняет Postgres, но принимаются только выражения синтаксиса
+	// RE2: без обратных ссылок и просмотра вперед
+	if _, err := regexp.Compile(q.Expr()); err != nil {
+		return fmt.Errorf("invalid regex %q", q.Pattern)
+	}
+	return nil
+}
+
+// Expr возвращает шаблон glob или regex в виде регулярного выражения.
+// Glob переводится в выражение, привязанное к началу и концу имени
+func (q DomainQuery) Expr() string {
+	if q.Match == MatchGlob {
+		return globExpr(q.Pattern)
+	}
+	return q.Pattern
+}
+
+// globExpr переводит шаблон path.Match в регулярное выражение:
+// "*" и "?" становятся ".*" и ".", классы "[...]" переносятся как есть.
+// Шаблон должен быть заранее проверен в Validate
+func globExpr(glob string) string {
+	var b strings.Builder
+	b.WriteByte('^')
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteByte('.')
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			}
+		case '[':
+			end := strings.IndexByte(glob[i:], ']')
+			b.WriteString(glob[i : i+end+1])
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteByte('$')
+	return b.String()
+}
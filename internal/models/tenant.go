@@ -0,0 +1,83 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// DefaultTenantID — арендатор, создаваемый миграцией. Ему принадлежат данные,
+// записанные до появления арендаторов. Арендатором по умолчанию он
+// не подставляется: вызывающий задает его явно через WithTenant
+const DefaultTenantID uint = 1
+
+// ErrNoTenant — запрос к данным арендатора пришел без арендатора в контексте
+var ErrNoTenant = errors.New("no tenant in context")
+
+type Tenant struct {
+	ID        uint      `gorm:"primarykey"`
+	Name      string    `gorm:"not null;uniqueIndex"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at"`
+}
+
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+var roleRank = map[Role]int{RoleViewer: 1, RoleEditor: 2, RoleAdmin: 3}
+
+// Valid сообщает, известна ли роль
+func (r Role) Valid() bool {
+	return roleRank[r] > 0
+}
+
+// Allows проверяет, что роль r не ниже required:
+// admin включает права editor, editor — права viewer
+func (r Role) Allows(required Role) bool {
+	return r.Valid() && roleRank[r] >= roleRank[required]
+}
+
+// APIKey хранит только хеш ключа; сам ключ показывается один раз при создании
+type APIKey struct {
+	ID        uint      `gorm:"primarykey"`
+	TenantID  uint      `gorm:"not null;index"`
+	Name      string    `gorm:"not null;default:''"`
+	Role      Role      `gorm:"not null;default:'editor'"`
+	Prefix    string    `gorm:"not null"`
+	KeyHash   string    `gorm:"not null;uniqueIndex"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at"`
+}
+
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+type tenantKey struct{}
+
+func WithTenant(ctx context.Context, tenantID uint) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFrom возвращает арендатора из контекста
+func TenantFrom(ctx context.Context) (uint, bool) {
+	id, ok := ctx.Value(tenantKey{}).(uint)
+	return id, ok && id != 0
+}
+
+// TenantID возвращает арендатора из контекста, который уже прошел
+// аутентификацию. Контекст без арендатора — ошибка в коде: подставить
+// арендатора молча значило бы отдать чужие данные
+func TenantID(ctx context.Context) uint {
+	id, ok := TenantFrom(ctx)
+	if !ok {
+		panic(ErrNoTenant)
+	}
+	return id
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// UpdaterSettingsID — ключ единственной строки настроек
+const UpdaterSettingsID = 1
+
+// UpdaterSettings — настройки фонового обновления, общие для реплик: их меняет
+// API любой реплики, а применяет лидер. IntervalSeconds 0 — интервал
+// по умолчанию из конфигурации процесса
+type UpdaterSettings struct {
+	ID              uint    `gorm:"primarykey"`
+	IntervalSeconds float64 `gorm:"not null;default:0"`
+	Paused          bool    `gorm:"not null;default:false"`
+	// RefreshRequested — запрошен внеочередной проход, лидер его еще не начал
+	RefreshRequested bool      `gorm:"not null;default:false"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime;column:updated_at"`
+}
+
+// UpdaterSettingsUpdate — изменение настроек; пустые поля не меняются
+type UpdaterSettingsUpdate struct {
+	Interval       *time.Duration
+	Paused         *bool
+	RequestRefresh bool
+}
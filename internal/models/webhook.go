@@ -0,0 +1,59 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type Webhook struct {
+	ID          uint      `gorm:"primarykey"`
+	TenantID    uint      `gorm:"not null;default:1;index"`
+	URL         string    `gorm:"not null"`
+	Secret      string    `gorm:"not null"`
+	FQDNPattern string    `gorm:"column:fqdn_pattern;not null;default:'*'"`
+	EventTypes  string    `gorm:"column:event_types;not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime;column:created_at"`
+}
+
+// Events возвращает список типов событий, на которые подписан вебхук
+func (w Webhook) Events() []EventType {
+	var events []EventType
+	for _, e := range strings.Split(w.EventTypes, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			events = append(events, EventType(e))
+		}
+	}
+	return events
+}
+
+// Matches проверяет, подходит ли событие под фильтры вебхука
+func (w Webhook) Matches(ev Event) bool {
+	filter := EventFilter{Types: w.Events()}
+	if w.FQDNPattern != "" {
+		filter.Patterns = []string{w.FQDNPattern}
+	}
+	return filter.Match(ev)
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery — запись outbox-таблицы и одновременно журнал доставки
+type WebhookDelivery struct {
+	ID            uint      `gorm:"primarykey"`
+	TenantID      uint      `gorm:"not null;default:1"`
+	WebhookID     uint      `gorm:"not null;index"`
+	EventType     string    `gorm:"not null"`
+	Payload       string    `gorm:"not null"`
+	Status        string    `gorm:"not null;default:'pending';index"`
+	Attempts      int       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"not null;index"`
+	ResponseCode  int
+	LastError     string
+	DeliveredAt   *time.Time
+	CreatedAt     time.Time `gorm:"autoCreateTime;column:created_at"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime;column:updated_at"`
+}
@@ -0,0 +1,246 @@
+package netpol
+
+import (
+	"bytes"
+	"dns-resolver/internal/firewall"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Flavor string
+
+const (
+	FlavorNetworkPolicy Flavor = "networkpolicy"
+	FlavorCilium        Flavor = "cilium"
+)
+
+const managedByLabel = "app.kubernetes.io/managed-by"
+
+var (
+	dns1123Re  = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	labelKeyRe = regexp.MustCompile(`^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/)?[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+	labelValRe = regexp.MustCompile(`^([A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?)?$`)
+)
+
+type Port struct {
+	Port     int
+	Protocol string
+}
+
+type Options struct {
+	Flavor    Flavor
+	Name      string
+	Namespace string
+	// Selector — метки подов, к которым применяется политика. Пустой — все поды
+	Selector map[string]string
+	Ports    []Port
+}
+
+// ParseSelector разбирает селектор вида "app=web,tier=frontend"
+func ParseSelector(raw string) (map[string]string, error) {
+	selector := map[string]string{}
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || !labelKeyRe.MatchString(key) || len(value) > 63 || !labelValRe.MatchString(value) {
+			return nil, fmt.Errorf("invalid selector %q", pair)
+		}
+		selector[key] = value
+	}
+	return selector, nil
+}
+
+// ParsePort разбирает порт вида "443" или "53/UDP"
+func ParsePort(raw string) (Port, error) {
+	num, proto, _ := strings.Cut(raw, "/")
+	if proto == "" {
+		proto = "TCP"
+	}
+	proto = strings.ToUpper(proto)
+	if proto != "TCP" && proto != "UDP" && proto != "SCTP" {
+		return Port{}, fmt.Errorf("invalid protocol in port %q", raw)
+	}
+
+	port, err := strconv.Atoi(num)
+	if err != nil || port < 1 || port > 65535 {
+		return Port{}, fmt.Errorf("invalid port %q", raw)
+	}
+	return Port{Port: port, Protocol: proto}, nil
+}
+
+type objectMeta struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels"`
+}
+
+type labelSelector struct {
+	MatchLabels map[string]string `yaml:"matchLabels,omitempty"`
+}
+
+type networkPolicy struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   objectMeta        `yaml:"metadata"`
+	Spec       networkPolicySpec `yaml:"spec"`
+}
+
+type networkPolicySpec struct {
+	PodSelector labelSelector `yaml:"podSelector"`
+	PolicyTypes []string      `yaml:"policyTypes"`
+	Egress      []egressRule  `yaml:"egress"`
+}
+
+type egressRule struct {
+	To    []peer       `yaml:"to"`
+	Ports []policyPort `yaml:"ports,omitempty"`
+}
+
+type peer struct {
+	IPBlock ipBlock `yaml:"ipBlock"`
+}
+
+type ipBlock struct {
+	CIDR string `yaml:"cidr"`
+}
+
+type policyPort struct {
+	Protocol string `yaml:"protocol"`
+	Port     int    `yaml:"port"`
+}
+
+type ciliumPolicy struct {
+	APIVersion string     `yaml:"apiVersion"`
+	Kind       string     `yaml:"kind"`
+	Metadata   objectMeta `yaml:"metadata"`
+	Spec       ciliumSpec `yaml:"spec"`
+}
+
+type ciliumSpec struct {
+	EndpointSelector labelSelector `yaml:"endpointSelector"`
+	Egress           []ciliumRule  `yaml:"egress"`
+}
+
+type ciliumRule struct {
+	ToCIDR  []string         `yaml:"toCIDR"`
+	ToPorts []ciliumPortRule `yaml:"toPorts,omitempty"`
+}
+
+type ciliumPortRule struct {
+	Ports []ciliumPort `yaml:"ports"`
+}
+
+type ciliumPort struct {
+	Port     string `yaml:"port"`
+	Protocol string `yaml:"protocol"`
+}
+
+// cidrs превращает адреса в /32 и /128 блоки в стабильном порядке
+func cidrs(ips []string) []string {
+	v4, v6 := firewall.Split(ips)
+	res := make([]string, 0, len(v4)+len(v6))
+	for _, a := range append(v4, v6...) {
+		res = append(res, netip.PrefixFrom(a, a.BitLen()).String())
+	}
+	return res
+}
+
+// Render формирует YAML-манифест политики исходящего трафика для адресов ips.
+// Если адресов нет, политика не разрешает egress вовсе
+func Render(opts Options, ips []string) ([]byte, error) {
+	if opts.Name == "" {
+		opts.Name = "dns-resolver-egress"
+	}
+	if len(opts.Name) > 253 || !dns1123Re.MatchString(opts.Name) {
+		return nil, fmt.Errorf("invalid name %q", opts.Name)
+	}
+	if opts.Namespace != "" && (len(opts.Namespace) > 63 || !dns1123Re.MatchString(opts.Namespace)) {
+		return nil, fmt.Errorf("invalid namespace %q", opts.Namespace)
+	}
+
+	meta := objectMeta{
+		Name:      opts.Name,
+		Namespace: opts.Namespace,
+		Labels:    map[string]string{managedByLabel: "dns-resolver"},
+	}
+	blocks := cidrs(ips)
+
+	ports := append([]Port(nil), opts.Ports...)
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Port != ports[j].Port {
+			return ports[i].Port < ports[j].Port
+		}
+		return ports[i].Protocol < ports[j].Protocol
+	})
+
+	var doc interface{}
+	switch opts.Flavor {
+	case FlavorNetworkPolicy:
+		rules := []egressRule{}
+		if len(blocks) > 0 {
+			rule := egressRule{}
+			for _, cidr := range blocks {
+				rule.To = append(rule.To, peer{IPBlock: ipBlock{CIDR: cidr}})
+			}
+			for _, p := range ports {
+				rule.Ports = append(rule.Ports, policyPort{Protocol: p.Protocol, Port: p.Port})
+			}
+			rules = append(rules, rule)
+		}
+		doc = networkPolicy{
+			APIVersion: "networking.k8s.io/v1",
+			Kind:       "NetworkPolicy",
+			Metadata:   meta,
+			Spec: networkPolicySpec{
+				PodSelector: labelSelector{MatchLabels: opts.Selector},
+				PolicyTypes: []string{"Egress"},
+				Egress:      rules,
+			},
+		}
+	case FlavorCilium:
+		rules := []ciliumRule{}
+		if len(blocks) > 0 {
+			rule := ciliumRule{ToCIDR: blocks}
+			if len(ports) > 0 {
+				portRule := ciliumPortRule{}
+				for _, p := range ports {
+					portRule.Ports = append(portRule.Ports, ciliumPort{Port: strconv.Itoa(p.Port), Protocol: p.Protocol})
+				}
+				rule.ToPorts = []ciliumPortRule{portRule}
+			}
+			rules = append(rules, rule)
+		}
+		doc = ciliumPolicy{
+			APIVersion: "cilium.io/v2",
+			Kind:       "CiliumNetworkPolicy",
+			Metadata:   meta,
+			Spec: ciliumSpec{
+				EndpointSelector: labelSelector{MatchLabels: opts.Selector},
+				Egress:           rules,
+			},
+		}
+	default:
+		return nil, fmt.Errorf("unknown flavor %q", opts.Flavor)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
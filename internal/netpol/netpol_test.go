@@ -0,0 +1,97 @@
+package netpol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderNetworkPolicy(t *testing.T) {
+	out, err := Render(Options{
+		Flavor:    FlavorNetworkPolicy,
+		Name:      "payments-egress",
+		Namespace: "payments",
+		Selector:  map[string]string{"app": "payments"},
+		Ports:     []Port{{Port: 443, Protocol: "TCP"}},
+	}, []string{"2001:db8::1", "10.0.0.2", "10.0.0.1"})
+	require.NoError(t, err)
+
+	expected := `apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: payments-egress
+  namespace: payments
+  labels:
+    app.kubernetes.io/managed-by: dns-resolver
+spec:
+  podSelector:
+    matchLabels:
+      app: payments
+  policyTypes:
+    - Egress
+  egress:
+    - to:
+        - ipBlock:
+            cidr: 10.0.0.1/32
+        - ipBlock:
+            cidr: 10.0.0.2/32
+        - ipBlock:
+            cidr: 2001:db8::1/128
+      ports:
+        - protocol: TCP
+          port: 443
+`
+	assert.Equal(t, expected, string(out))
+}
+
+func TestRenderCilium(t *testing.T) {
+	out, err := Render(Options{
+		Flavor: FlavorCilium,
+		Ports:  []Port{{Port: 53, Protocol: "UDP"}},
+	}, []string{"10.0.0.1"})
+	require.NoError(t, err)
+
+	expected := `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: dns-resolver-egress
+  labels:
+    app.kubernetes.io/managed-by: dns-resolver
+spec:
+  endpointSelector: {}
+  egress:
+    - toCIDR:
+        - 10.0.0.1/32
+      toPorts:
+        - ports:
+            - port: "53"
+              protocol: UDP
+`
+	assert.Equal(t, expected, string(out))
+}
+
+func TestRenderNoAddresses(t *testing.T) {
+	out, err := Render(Options{Flavor: FlavorNetworkPolicy}, nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "egress: []")
+}
+
+func TestParse(t *testing.T) {
+	selector, err := ParseSelector("app=web, tier=frontend")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "web", "tier": "frontend"}, selector)
+
+	_, err = ParseSelector("app")
+	assert.Error(t, err)
+
+	port, err := ParsePort("53/udp")
+	require.NoError(t, err)
+	assert.Equal(t, Port{Port: 53, Protocol: "UDP"}, port)
+
+	_, err = ParsePort("70000")
+	assert.Error(t, err)
+
+	_, err = Render(Options{Flavor: FlavorNetworkPolicy, Name: "Bad_Name"}, nil)
+	assert.Error(t, err)
+}
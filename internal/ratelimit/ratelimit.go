@@ -0,0 +1,181 @@
+package ratelimit
+
+import (
+	"context"
+	"dns-resolver/internal/logging"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Class разделяет бюджеты запросов: запись дороже чтения,
+// так как добавление FQDN вызывает внешний DNS-запрос
+type Class string
+
+const (
+	Read  Class = "read"
+	Write Class = "write"
+)
+
+// Budget — параметры token bucket: Rate запросов в секунду и запас Burst.
+// Rate <= 0 отключает ограничение
+type Budget struct {
+	Rate  float64 `json:"rate" validate:"gte=0"`
+	Burst int     `json:"burst" validate:"gte=0"`
+}
+
+type Config struct {
+	Read  Budget `json:"read"`
+	Write Budget `json:"write"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Read:  Budget{Rate: 20, Burst: 40},
+		Write: Budget{Rate: 1, Burst: 5},
+	}
+}
+
+func (c Config) budget(class Class) Budget {
+	if class == Write {
+		return c.Write
+	}
+	return c.Read
+}
+
+// Decision — результат проверки запроса, из него строятся заголовки ответа
+type Decision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// Limiter хранит token bucket на каждую пару (ключ, класс).
+// Ключом служит адрес клиента или идентификатор учетных данных
+type Limiter struct {
+	mu        sync.Mutex
+	config    Config
+	buckets   map[string]*bucket
+	idleTTL   time.Duration
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func New(config Config) *Limiter {
+	return &Limiter{
+		config:  config,
+		buckets: make(map[string]*bucket),
+		idleTTL: 10 * time.Minute,
+		now:     time.Now,
+	}
+}
+
+func (l *Limiter) Config() Config {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.config
+}
+
+// SetConfig меняет бюджеты на лету. Накопленные бакеты сбрасываются,
+// чтобы новые значения применялись сразу ко всем клиентам
+func (l *Limiter) SetConfig(config Config) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.config = config
+	l.buckets = make(map[string]*bucket)
+}
+
+// Store хранит бюджеты, общие для реплик
+type Store interface {
+	// GetRateLimits возвращает сохраненные бюджеты; false — их не сохраняли
+	GetRateLimits(ctx context.Context) (Config, bool, error)
+	SaveRateLimits(ctx context.Context, config Config) error
+}
+
+// DefaultWatchInterval — как часто реплики перечитывают общие бюджеты
+const DefaultWatchInterval = 2 * time.Second
+
+// Watch применяет бюджеты из store до отмены ctx, чтобы изменение через
+// любую реплику действовало на всех. Бакеты сбрасываются только при изменении
+func (l *Limiter) Watch(ctx context.Context, store Store, interval time.Duration) {
+	logger := logging.Component("ratelimit")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		config, ok, err := store.GetRateLimits(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			logger.ErrorContext(ctx, "Failed to load rate limits", "error", err)
+		case ok && config != l.Config():
+			l.SetConfig(config)
+			logger.InfoContext(ctx, "Rate limits updated", "read_rate", config.Read.Rate, "write_rate", config.Write.Rate)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Allow списывает токен из бакета key для класса class
+func (l *Limiter) Allow(key string, class Class) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	budget := l.config.budget(class)
+	if budget.Rate <= 0 {
+		return Decision{Allowed: true}
+	}
+
+	now := l.now()
+	l.sweep(now)
+
+	name := fmt.Sprintf("%s|%s", class, key)
+	b, ok := l.buckets[name]
+	if !ok {
+		burst := budget.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(budget.Rate), burst)}
+		l.buckets[name] = b
+	}
+	b.lastSeen = now
+
+	d := Decision{Limit: b.limiter.Burst()}
+	r := b.limiter.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		d.RetryAfter = delay
+		return d
+	}
+
+	d.Allowed = true
+	d.Remaining = int(math.Max(0, math.Floor(b.limiter.TokensAt(now))))
+	return d
+}
+
+// sweep удаляет бакеты, которыми давно не пользовались
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.idleTTL {
+		return
+	}
+	l.lastSweep = now
+	for name, b := range l.buckets {
+		if now.Sub(b.lastSeen) >= l.idleTTL {
+			delete(l.buckets, name)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memStore — общие бюджеты в памяти
+type memStore struct {
+	mu     sync.Mutex
+	config *Config
+}
+
+func (s *memStore) GetRateLimits(ctx context.Context) (Config, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.config == nil {
+		return Config{}, false, nil
+	}
+	return *s.config, true, nil
+}
+
+func (s *memStore) SaveRateLimits(ctx context.Context, config Config) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = &config
+	return nil
+}
+
+func TestLimiter_Watch(t *testing.T) {
+	store := &memStore{}
+	l := New(DefaultConfig())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Watch(ctx, store, 5*time.Millisecond)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Пока бюджеты не сохраняли, действуют заданные при запуске
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, DefaultConfig(), l.Config())
+
+	// Изменение, сохраненное другой репликой, применяется здесь
+	updated := Config{Read: Budget{Rate: 50, Burst: 100}, Write: Budget{Rate: 2, Burst: 10}}
+	require.NoError(t, store.SaveRateLimits(ctx, updated))
+	require.Eventually(t, func() bool { return l.Config() == updated }, time.Second, 5*time.Millisecond)
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l := New(Config{Read: Budget{Rate: 10, Burst: 2}, Write: Budget{Rate: 1, Burst: 1}})
+	l.now = func() time.Time { return now }
+
+	t.Run("burst then reject", func(t *testing.T) {
+		d := l.Allow("ip:1.1.1.1", Write)
+		require.True(t, d.Allowed)
+		assert.Equal(t, 1, d.Limit)
+		assert.Equal(t, 0, d.Remaining)
+
+		d = l.Allow("ip:1.1.1.1", Write)
+		assert.False(t, d.Allowed)
+		assert.Equal(t, time.Second, d.RetryAfter)
+
+		// Отказ не расходует токены: через секунду запрос проходит
+		now = now.Add(time.Second)
+		assert.True(t, l.Allow("ip:1.1.1.1", Write).Allowed)
+	})
+
+	t.Run("classes and keys are independent", func(t *testing.T) {
+		assert.True(t, l.Allow("ip:1.1.1.1", Read).Allowed)
+		assert.True(t, l.Allow("ip:2.2.2.2", Write).Allowed)
+	})
+
+	t.Run("SetConfig applies immediately", func(t *testing.T) {
+		assert.False(t, l.Allow("ip:1.1.1.1", Write).Allowed)
+
+		l.SetConfig(Config{Read: Budget{Rate: 10, Burst: 2}})
+		for i := 0; i < 10; i++ {
+			assert.True(t, l.Allow("ip:1.1.1.1", Write).Allowed)
+		}
+	})
+
+	t.Run("idle buckets are evicted", func(t *testing.T) {
+		l.Allow("ip:3.3.3.3", Read)
+		now = now.Add(time.Hour)
+		l.Allow("ip:4.4.4.4", Read)
+		assert.Len(t, l.buckets, 1)
+	})
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"dns-resolver/internal/models"
+)
+
+func (d *DB) AppendAudit(ctx context.Context, entry *models.AuditEntry) error {
+	tenant, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+	entry.TenantID = tenant
+	return d.conn(ctx).Create(entry).Error
+}
+
+// ListAudit возвращает записи аудита арендатора, новые первыми
+func (d *DB) ListAudit(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	query := d.scoped(ctx)
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Target != "" {
+		query = query.Where("target = ?", filter.Target)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+
+	var entries []models.AuditEntry
+	err := query.Order("id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// CanonicalizeFQDNs приводит уже сохраненные имена в dns_records и group_members
+// к виду, который возвращает canonical. Записи Unicode-имени и его вариантов
+// сливаются с записями A-label, а строки с некорректными именами переносятся
+// в quarantined_records. Задания перепроверки старых имен удаляются:
+// SyncRefreshJobs заведет задания для новых. Запрос общий для всех арендаторов
+func (d *DB) CanonicalizeFQDNs(ctx context.Context, canonical func(string) (string, error)) (renamed, quarantined int64, err error) {
+	err = d.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		var names []string
+		err := tx.Raw(`SELECT fqdn FROM dns_records UNION SELECT fqdn FROM group_members
+			UNION SELECT fqdn FROM refresh_jobs`).Scan(&names).Error
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			fqdn, err := canonical(name)
+			switch {
+			case err != nil:
+				n, qErr := quarantineFQDN(tx, name, err.Error())
+				if qErr != nil {
+					return qErr
+				}
+				quarantined += n
+			case fqdn != name:
+				if err := renameFQDN(tx, name, fqdn); err != nil {
+					return err
+				}
+				renamed++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return renamed, quarantined, nil
+}
+
+// renameFQDN переносит строки на имя fqdn. Строки, которые у fqdn уже есть,
+// не дублируются, а удаляются вместе со старым именем
+func renameFQDN(tx *gorm.DB, name, fqdn string) error {
+	for _, q := range []string{
+		`UPDATE dns_records r SET fqdn = @fqdn WHERE fqdn = @name AND NOT EXISTS (
+			SELECT 1 FROM dns_records d WHERE d.tenant_id = r.tenant_id AND d.ip = r.ip AND d.fqdn = @fqdn)`,
+		`DELETE FROM dns_records WHERE fqdn = @name`,
+		`UPDATE group_members m SET fqdn = @fqdn WHERE fqdn = @name AND NOT EXISTS (
+			SELECT 1 FROM group_members o WHERE o.group_id = m.group_id AND o.fqdn = @fqdn)`,
+		`DELETE FROM group_members WHERE fqdn = @name`,
+		`DELETE FROM refresh_jobs WHERE fqdn = @name`,
+	} {
+		if err := tx.Exec(q, map[string]any{"name": name, "fqdn": fqdn}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quarantineFQDN переносит строки имени в quarantined_records и возвращает их число
+func quarantineFQDN(tx *gorm.DB, name, reason string) (int64, error) {
+	var moved int64
+	for _, q := range []string{
+		`INSERT INTO quarantined_records (source, tenant_id, fqdn, ip, reason, created_at)
+			SELECT 'dns_records', tenant_id, fqdn, ip, @reason, NOW() FROM dns_records WHERE fqdn = @name`,
+		`INSERT INTO quarantined_records (source, tenant_id, group_id, fqdn, reason, created_at)
+			SELECT 'group_members', g.tenant_id, m.group_id, m.fqdn, @reason, NOW()
+			FROM group_members m JOIN groups g ON g.id = m.group_id WHERE m.fqdn = @name`,
+	} {
+		res := tx.Exec(q, map[string]any{"name": name, "reason": reason})
+		if res.Error != nil {
+			return 0, res.Error
+		}
+		moved += res.RowsAffected
+	}
+
+	for _, table := range []string{"dns_records", "group_members", "refresh_jobs"} {
+		if err := tx.Exec("DELETE FROM "+table+" WHERE fqdn = ?", name).Error; err != nil {
+			return 0, err
+		}
+	}
+	return moved, nil
+}
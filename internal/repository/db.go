@@ -4,6 +4,7 @@ import (
 	"context"
 	"dns-resolver/internal/models"
 	"fmt"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -11,25 +12,80 @@ import (
 
 type DB struct {
 	db *gorm.DB
+	// searchTimeout ограничивает поиск по шаблону, см. SearchFQDNs
+	searchTimeout time.Duration
 }
 
 func NewDB(db *gorm.DB) *DB{
-	return &DB{db: db}
+	return &DB{db: db, searchTimeout: regexSearchTimeout}
+}
+
+const ProdDSN = "host=postgres user=postgres password=dbdns dbname=DNS_DB port=5432 sslmode=require sslmode=disable"
+
+func Open(dsn string) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true, Logger: newQueryLogger()})
+	if err != nil {
+		return nil, err
+	}
+	if err := registerErrorTranslation(db); err != nil {
+		return nil, err
+	}
+	if err := registerTracing(db); err != nil {
+		return nil, err
+	}
+	return db, nil
 }
 
 func ProdDB() (*gorm.DB, error) {
-	dsn := "host=postgres user=postgres password=dbdns dbname=DNS_DB port=5432 sslmode=require sslmode=disable"
-    return gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	return Open(ProdDSN)
 }
 
 func DBForTest() (*gorm.DB, error) {
-	dsn := "host=localhost user=postgres password=dbdns dbname=DNS_DB port=5432 sslmode=require sslmode=disable"
-    return gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	return Open("host=localhost user=postgres password=dbdns dbname=DNS_DB port=5432 sslmode=require sslmode=disable")
+}
+
+type txKey struct{}
+
+// Transaction выполняет fn в одной транзакции: методы DB, вызванные
+// с переданным в fn контекстом, работают в ней же
+func (d *DB) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return d.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn возвращает открытую в контексте транзакцию или общее соединение
+func (d *DB) conn(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return d.db.WithContext(ctx)
+}
+
+// tenantOf возвращает арендатора из контекста. Арендатор по умолчанию
+// не подставляется: запрос без арендатора — ошибка вызывающего
+func tenantOf(ctx context.Context) (uint, error) {
+	if id, ok := models.TenantFrom(ctx); ok {
+		return id, nil
+	}
+	return 0, models.ErrNoTenant
+}
+
+// scoped ограничивает запрос арендатором из контекста. Без арендатора
+// запрос не выполняется и возвращает models.ErrNoTenant
+func (d *DB) scoped(ctx context.Context) *gorm.DB {
+	db := d.conn(ctx)
+	tenant, err := tenantOf(ctx)
+	if err != nil {
+		db.AddError(err)
+		return db
+	}
+	return db.Where("tenant_id = ?", tenant)
 }
 
 func (d *DB) GetFQDNsByIP(ctx context.Context, ip string) ([]string, error) {
 	var records []models.DNSRecord
-	err := d.db.WithContext(ctx).Where("ip = ?", ip).Find(&records).Error
+	err := d.scoped(ctx).Where("ip = ?", ip).Find(&records).Error
 	if err != nil {
 		return nil, err
 	}
@@ -44,7 +100,7 @@ func (d *DB) GetFQDNsByIP(ctx context.Context, ip string) ([]string, error) {
 
 func (d *DB) GetIPsByFQDN(ctx context.Context, fqdn string) ([]string, error) {
 	var records []models.DNSRecord
-	err := d.db.WithContext(ctx).Where("fqdn = ?", fqdn).Find(&records).Error
+	err := d.scoped(ctx).Where("fqdn = ?", fqdn).Find(&records).Error
 	if err != nil{
 		return nil, err
 	}
@@ -59,15 +115,78 @@ func (d *DB) GetIPsByFQDN(ctx context.Context, fqdn string) ([]string, error) {
 
 // AddOrUpdateRecord добавляет или обновляет запись
 func (d *DB) AddOrUpdate(ctx context.Context, fqdn, ip string) error {
-	return d.db.WithContext(ctx).Where(models.DNSRecord{FQDN: fqdn, IP: ip}).FirstOrCreate(&models.DNSRecord{FQDN: fqdn, IP: ip}).Error
+	tenant, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+	record := models.DNSRecord{TenantID: tenant, FQDN: fqdn, IP: ip}
+	return d.conn(ctx).Where(record).FirstOrCreate(&record).Error
 }
 
 func (d *DB) GetAllFQDNs(ctx context.Context) ([]string, error) {
 	var fqdns []string
-	err := d.db.WithContext(ctx).Model(&models.DNSRecord{}).Distinct("fqdn").Pluck("fqdn", &fqdns).Error
+	err := d.scoped(ctx).Model(&models.DNSRecord{}).Distinct("fqdn").Pluck("fqdn", &fqdns).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to get FQDNs: %w", err)
 	}
 
 	return fqdns, nil
-}
\ No newline at end of file
+}
+
+// DeleteRecord удаляет запись и сдвигает updated_at оставшихся записей FQDN,
+// чтобы время изменения набора (Last-Modified) учитывало удаление
+func (d *DB) DeleteRecord(ctx context.Context, fqdn, ip string) error {
+	tenant, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+	return d.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("tenant_id = ? AND fqdn = ? AND ip = ?", tenant, fqdn, ip).Delete(&models.DNSRecord{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.DNSRecord{}).Where("tenant_id = ? AND fqdn = ?", tenant, fqdn).
+			Update("updated_at", time.Now()).Error
+	})
+}
+
+// GetRecordVersion возвращает число записей FQDN и время последнего изменения
+// набора — дешевый валидатор для условных запросов
+func (d *DB) GetRecordVersion(ctx context.Context, fqdn string) (models.RecordVersion, error) {
+	var version models.RecordVersion
+	err := d.scoped(ctx).Model(&models.DNSRecord{}).Where("fqdn = ?", fqdn).
+		Select("COUNT(*) AS count, MAX(updated_at) AS updated_at").Scan(&version).Error
+	return version, err
+}
+
+// GetFQDNsByIPs ищет FQDN сразу для нескольких IP одним запросом
+func (d *DB) GetFQDNsByIPs(ctx context.Context, ips []string) (map[string][]string, error) {
+	var records []models.DNSRecord
+	err := d.scoped(ctx).Where("ip IN ?", ips).Order("fqdn").Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string][]string, len(ips))
+	for _, record := range records {
+		res[record.IP] = append(res[record.IP], record.FQDN)
+	}
+
+	return res, nil
+}
+
+// GetIPsByFQDNs ищет IP сразу для нескольких FQDN одним запросом
+func (d *DB) GetIPsByFQDNs(ctx context.Context, fqdns []string) (map[string][]string, error) {
+	var records []models.DNSRecord
+	err := d.scoped(ctx).Where("fqdn IN ?", fqdns).Order("ip").Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string][]string, len(fqdns))
+	for _, record := range records {
+		res[record.FQDN] = append(res[record.FQDN], record.IP)
+	}
+
+	return res, nil
+}
@@ -3,7 +3,13 @@ package repository
 import (
 	"context"
 	"dns-resolver/internal/models"
+	"dns-resolver/internal/ratelimit"
+	"dns-resolver/internal/validator"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -13,14 +19,19 @@ func TestDB(t *testing.T) {
 	db, err := DBForTest()
 	require.NoError(t, err)
 
-	err = db.Exec("DROP TABLE IF EXISTS dns_records").Error 
+	err = db.Exec("DROP TABLE IF EXISTS dns_records, webhooks, webhook_deliveries, events, groups, group_members, tenants, api_keys, audit_entries, refresh_jobs, updater_settings, quarantined_records, rate_limit_settings").Error
 	require.NoError(t, err)
 
-	err = db.AutoMigrate(&models.DNSRecord{})
+	err = db.AutoMigrate(&models.DNSRecord{}, &models.Webhook{}, &models.WebhookDelivery{}, &models.Event{},
+		&models.Group{}, &models.GroupMember{}, &models.Tenant{}, &models.APIKey{}, &models.AuditEntry{}, &models.RefreshJob{},
+		&models.UpdaterSettings{}, &models.QuarantinedRecord{}, &models.RateLimitSettings{})
 	require.NoError(t, err, "Failed to migrate test database")
+	// AutoMigrate не создает внешний ключ членства, он задан в миграции 004
+	err = db.Exec("ALTER TABLE group_members ADD FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE").Error
+	require.NoError(t, err)
 
 	repo := NewDB(db) //
-	ctx := context.Background() 
+	ctx := models.WithTenant(context.Background(), models.DefaultTenantID)
 
 	t.Run("GetIPsByFQDN", func(t *testing.T) {
 		err := db.Exec("DELETE FROM dns_records").Error
@@ -107,4 +118,539 @@ func TestDB(t *testing.T) {
 		require.NoError(t, err)
 		assert.ElementsMatch(t, []string{"site1.com", "site2.com"}, fqdns)
 	})
+
+	t.Run("Batch lookups", func(t *testing.T) {
+		require.NoError(t, repo.AddOrUpdate(ctx, "batch-a.com", "10.0.0.1"))
+		require.NoError(t, repo.AddOrUpdate(ctx, "batch-b.com", "10.0.0.1"))
+		require.NoError(t, repo.AddOrUpdate(ctx, "batch-b.com", "10.0.0.2"))
+
+		fqdns, err := repo.GetFQDNsByIPs(ctx, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{
+			"10.0.0.1": {"batch-a.com", "batch-b.com"},
+			"10.0.0.2": {"batch-b.com"},
+		}, fqdns)
+
+		ips, err := repo.GetIPsByFQDNs(ctx, []string{"batch-b.com", "missing.com"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{"batch-b.com": {"10.0.0.1", "10.0.0.2"}}, ips)
+	})
+
+	t.Run("Keyset pagination", func(t *testing.T) {
+		require.NoError(t, db.Exec("DELETE FROM dns_records").Error)
+		for _, fqdn := range []string{"c.cdn.com", "a.cdn.com", "d.cdn.com", "b.cdn.com"} {
+			require.NoError(t, repo.AddOrUpdate(ctx, fqdn, "5.5.5.5"))
+		}
+		// updated_at задает порядок, отличный от имени
+		base := time.Now().Add(-time.Hour)
+		for i, fqdn := range []string{"d.cdn.com", "b.cdn.com", "c.cdn.com", "a.cdn.com"} {
+			err := db.Exec("UPDATE dns_records SET updated_at = ? WHERE fqdn = ?", base.Add(time.Duration(i)*time.Minute), fqdn).Error
+			require.NoError(t, err)
+		}
+
+		collect := func(q models.PageQuery) []string {
+			var all []string
+			for {
+				page, err := repo.ListFQDNsByIP(ctx, "5.5.5.5", q)
+				require.NoError(t, err)
+				assert.Equal(t, int64(4), page.Total)
+				assert.LessOrEqual(t, len(page.Items), q.Limit)
+				all = append(all, page.Items...)
+				if page.Next == nil {
+					return all
+				}
+				q.After = page.Next
+			}
+		}
+
+		assert.Equal(t, []string{"a.cdn.com", "b.cdn.com", "c.cdn.com", "d.cdn.com"},
+			collect(models.PageQuery{Limit: 3, Sort: models.SortByName}))
+		assert.Equal(t, []string{"d.cdn.com", "c.cdn.com", "b.cdn.com", "a.cdn.com"},
+			collect(models.PageQuery{Limit: 1, Sort: models.SortByName, Desc: true}))
+		assert.Equal(t, []string{"d.cdn.com", "b.cdn.com", "c.cdn.com", "a.cdn.com"},
+			collect(models.PageQuery{Limit: 2, Sort: models.SortByUpdatedAt}))
+		assert.Equal(t, []string{"a.cdn.com", "c.cdn.com", "b.cdn.com", "d.cdn.com"},
+			collect(models.PageQuery{Limit: 3, Sort: models.SortByUpdatedAt, Desc: true}))
+
+		page, err := repo.ListIPsByFQDN(ctx, "a.cdn.com", models.PageQuery{Limit: 10, Sort: models.SortByName})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"5.5.5.5"}, page.Items)
+		assert.Nil(t, page.Next)
+
+		page, err = repo.ListFQDNs(ctx, models.PageQuery{Limit: 2, Sort: models.SortByName})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a.cdn.com", "b.cdn.com"}, page.Items)
+		assert.Equal(t, int64(4), page.Total)
+		require.NotNil(t, page.Next)
+	})
+
+	t.Run("SearchFQDNs", func(t *testing.T) {
+		require.NoError(t, db.Exec("DELETE FROM dns_records").Error)
+		for _, fqdn := range []string{"corp.example.com", "git.corp.example.com", "notcorp.example.com", "100%_off.example.net"} {
+			require.NoError(t, repo.AddOrUpdate(ctx, fqdn, "6.6.6.6"))
+		}
+
+		search := func(q models.DomainQuery) []string {
+			page, err := repo.SearchFQDNs(ctx, q, models.PageQuery{Limit: 10, Sort: models.SortByName})
+			require.NoError(t, err)
+			return page.Items
+		}
+
+		assert.Equal(t, []string{"corp.example.com", "git.corp.example.com"}, search(models.DomainQuery{Suffix: "corp.example.com"}))
+		assert.Equal(t, []string{"100%_off.example.net"}, search(models.DomainQuery{Pattern: "%_"}))
+		assert.Equal(t, []string{"git.corp.example.com"}, search(models.DomainQuery{Pattern: "*.corp.*", Match: models.MatchGlob}))
+		assert.Equal(t, []string{"corp.example.com", "notcorp.example.com"},
+			search(models.DomainQuery{Pattern: "^[a-z]*corp\\.", Match: models.MatchRegex}))
+		assert.Equal(t, []string{"git.corp.example.com"}, search(models.DomainQuery{Pattern: "^GIT\\.", Match: models.MatchRegex}))
+
+		// Limit 0 — без ограничения и без курсора
+		page, err := repo.SearchFQDNs(ctx, models.DomainQuery{}, models.PageQuery{Sort: models.SortByName})
+		require.NoError(t, err)
+		assert.Len(t, page.Items, 4)
+		assert.Empty(t, page.Next)
+	})
+
+	t.Run("SearchFQDNs timeout", func(t *testing.T) {
+		require.NoError(t, db.Exec(`INSERT INTO dns_records (tenant_id, fqdn, ip, created_at, updated_at)
+			SELECT ?, 'host' || n || '.slow.example.com', '6.6.6.6', NOW(), NOW() FROM generate_series(1, 200000) AS n`,
+			models.DefaultTenantID).Error)
+		defer db.Exec("DELETE FROM dns_records WHERE fqdn LIKE '%.slow.example.com'")
+		defer func(timeout time.Duration) { repo.searchTimeout = timeout }(repo.searchTimeout)
+		repo.searchTimeout = time.Millisecond
+
+		_, err := repo.SearchFQDNs(ctx, models.DomainQuery{Pattern: "(a|aa)+b", Match: models.MatchRegex},
+			models.PageQuery{Limit: 10, Sort: models.SortByName})
+		assert.ErrorIs(t, err, models.ErrSearchTimeout)
+	})
+
+	t.Run("DeleteRecord", func(t *testing.T) {
+		err := db.Exec("DELETE FROM dns_records").Error
+		require.NoError(t, err)
+		require.NoError(t, repo.AddOrUpdate(ctx, "site1.com", "3.3.3.3"))
+		require.NoError(t, repo.AddOrUpdate(ctx, "site1.com", "4.4.4.4"))
+
+		require.NoError(t, repo.DeleteRecord(ctx, "site1.com", "3.3.3.3"))
+
+		ips, err := repo.GetIPsByFQDN(ctx, "site1.com")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"4.4.4.4"}, ips)
+	})
+
+	t.Run("Record version", func(t *testing.T) {
+		require.NoError(t, db.Exec("DELETE FROM dns_records").Error)
+
+		version, err := repo.GetRecordVersion(ctx, "site1.com")
+		require.NoError(t, err)
+		assert.Zero(t, version.Count)
+		assert.Nil(t, version.UpdatedAt)
+
+		require.NoError(t, repo.AddOrUpdate(ctx, "site1.com", "3.3.3.3"))
+		require.NoError(t, repo.AddOrUpdate(ctx, "site1.com", "4.4.4.4"))
+		old := time.Now().Add(-time.Hour)
+		require.NoError(t, db.Exec("UPDATE dns_records SET updated_at = ?", old).Error)
+
+		// Удаление адреса меняет время изменения оставшегося набора
+		require.NoError(t, repo.DeleteRecord(ctx, "site1.com", "3.3.3.3"))
+		version, err = repo.GetRecordVersion(ctx, "site1.com")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), version.Count)
+		require.NotNil(t, version.UpdatedAt)
+		assert.True(t, version.UpdatedAt.After(old.Add(time.Minute)))
+	})
+
+	t.Run("Advisory lock", func(t *testing.T) {
+		first, second := repo.AdvisoryLocker(42), repo.AdvisoryLocker(42)
+
+		lease, ok, err := first.TryAcquire(ctx)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.NoError(t, lease.Renew(ctx))
+
+		_, ok, err = second.TryAcquire(ctx)
+		require.NoError(t, err)
+		assert.False(t, ok, "lock is held by another session")
+
+		require.NoError(t, lease.Release(ctx))
+		lease, ok, err = second.TryAcquire(ctx)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.NoError(t, lease.Release(ctx))
+	})
+
+	t.Run("Refresh queue", func(t *testing.T) {
+		err := db.Exec("DELETE FROM dns_records").Error
+		require.NoError(t, err)
+		for i := range 40 {
+			require.NoError(t, repo.AddOrUpdate(ctx, fmt.Sprintf("host%d.example.com", i), "1.1.1.1"))
+		}
+
+		added, removed, err := repo.SyncRefreshJobs(ctx, -time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, int64(40), added)
+		assert.Zero(t, removed)
+
+		// Конкурирующие обработчики не получают одно задание дважды
+		var mu sync.Mutex
+		claimed := map[uint]string{}
+		var wg sync.WaitGroup
+		for i := range 4 {
+			owner := fmt.Sprintf("replica-%d", i)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					jobs, err := repo.ClaimRefreshJobs(ctx, owner, 3, time.Minute)
+					if !assert.NoError(t, err) || len(jobs) == 0 {
+						return
+					}
+					mu.Lock()
+					for _, job := range jobs {
+						assert.NotContains(t, claimed, job.ID)
+						claimed[job.ID] = owner
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Len(t, claimed, 40)
+
+		// Истекшая аренда выдается повторно, а результат прежнего владельца отбрасывается
+		require.NoError(t, db.Exec("UPDATE refresh_jobs SET lease_until = NOW() - INTERVAL '1 second' WHERE fqdn = 'host0.example.com'").Error)
+		jobs, err := repo.ClaimRefreshJobs(ctx, "survivor", 10, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		stale := jobs[0]
+		stale.Claims--
+		assert.ErrorIs(t, repo.CompleteRefreshJob(ctx, &stale, time.Hour, nil), models.ErrLeaseLost)
+		require.NoError(t, repo.CompleteRefreshJob(ctx, &jobs[0], time.Hour, nil))
+
+		jobs, err = repo.ClaimRefreshJobs(ctx, "survivor", 10, time.Minute)
+		require.NoError(t, err)
+		assert.Empty(t, jobs, "completed job is due in an hour")
+
+		// Внеочередная перепроверка делает задание подошедшим
+		requeued, err := repo.RequeueRefreshJobs(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), requeued)
+		jobs, err = repo.ClaimRefreshJobs(ctx, "survivor", 10, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		require.NoError(t, repo.CompleteRefreshJob(ctx, &jobs[0], time.Hour, nil))
+
+		require.NoError(t, repo.DeleteRecord(ctx, "host0.example.com", "1.1.1.1"))
+		_, removed, err = repo.SyncRefreshJobs(ctx, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), removed)
+	})
+
+	t.Run("Webhook deliveries", func(t *testing.T) {
+		hook := &models.Webhook{URL: "https://hooks.example.com", Secret: "s", FQDNPattern: "*"}
+		require.NoError(t, repo.CreateWebhook(ctx, hook))
+
+		err := repo.EnqueueDeliveries(ctx, []models.WebhookDelivery{
+			{WebhookID: hook.ID, EventType: "ip_added", Payload: "{}", Status: models.DeliveryPending, NextAttemptAt: time.Now().Add(-time.Second)},
+			{WebhookID: hook.ID, EventType: "ip_removed", Payload: "{}", Status: models.DeliveryPending, NextAttemptAt: time.Now().Add(time.Hour)},
+		})
+		require.NoError(t, err)
+
+		due, err := repo.ClaimDueDeliveries(ctx, 10, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		assert.Equal(t, "ip_added", due[0].EventType)
+
+		// Повторно та же доставка не выдается, пока не истек lease
+		due, err = repo.ClaimDueDeliveries(ctx, 10, time.Minute)
+		require.NoError(t, err)
+		assert.Empty(t, due)
+
+		log, err := repo.ListDeliveries(ctx, hook.ID, 10)
+		require.NoError(t, err)
+		assert.Len(t, log, 2)
+
+		require.NoError(t, repo.DeleteWebhook(ctx, hook.ID))
+		_, err = repo.GetWebhook(ctx, hook.ID)
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+
+	t.Run("Event log", func(t *testing.T) {
+		first := &models.Event{Type: models.EventIPAdded, FQDN: "site1.com", IP: "3.3.3.3", OccurredAt: time.Now()}
+		second := &models.Event{Type: models.EventIPRemoved, FQDN: "site1.com", IP: "3.3.3.3", OccurredAt: time.Now()}
+		require.NoError(t, repo.AppendEvent(ctx, first))
+		require.NoError(t, repo.AppendEvent(ctx, second))
+		assert.Greater(t, second.ID, first.ID)
+
+		events, err := repo.ListEventsSince(ctx, first.ID, 10)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, second.ID, events[0].ID)
+
+		old := &models.Event{Type: models.EventIPAdded, FQDN: "site1.com", IP: "4.4.4.4", OccurredAt: time.Now().Add(-48 * time.Hour)}
+		require.NoError(t, repo.AppendEvent(ctx, old))
+		deleted, err := repo.DeleteEventsBefore(ctx, time.Now().Add(-24*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+	})
+
+	t.Run("Event feed", func(t *testing.T) {
+		listenCtx, cancel := context.WithCancel(ctx)
+		received := make(chan models.Event, 100)
+		done := make(chan error, 1)
+		go func() {
+			done <- repo.ListenEvents(listenCtx, func(ev models.Event) { received <- ev })
+		}()
+		// LISTEN выполняется асинхронно: публикуем, пока слушатель не получит событие
+		ev := models.Event{ID: 42, TenantID: 2, Type: models.EventIPAdded, FQDN: "site1.com", IP: "3.3.3.3", OccurredAt: time.Now()}
+		var got models.Event
+		require.Eventually(t, func() bool {
+			require.NoError(t, repo.Transaction(ctx, func(ctx context.Context) error {
+				return repo.PublishEvents(ctx, []models.Event{ev})
+			}))
+			select {
+			case got = <-received:
+				return true
+			case <-time.After(50 * time.Millisecond):
+				return false
+			}
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, uint(42), got.ID)
+		assert.Equal(t, uint(2), got.TenantID)
+
+		// Отбрасываем повторы, отправленные до того, как LISTEN заработал
+		time.Sleep(100 * time.Millisecond)
+		for len(received) > 0 {
+			<-received
+		}
+
+		// Откаченная транзакция ничего не отправляет
+		err := repo.Transaction(ctx, func(ctx context.Context) error {
+			require.NoError(t, repo.PublishEvents(ctx, []models.Event{ev}))
+			return errors.New("rollback")
+		})
+		require.Error(t, err)
+		select {
+		case <-received:
+			t.Fatal("event from rolled back transaction delivered")
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+	})
+
+	t.Run("Groups", func(t *testing.T) {
+		err := db.Exec("DELETE FROM dns_records").Error
+		require.NoError(t, err)
+		require.NoError(t, repo.AddOrUpdate(ctx, "site1.com", "3.3.3.3"))
+		require.NoError(t, repo.AddOrUpdate(ctx, "site2.com", "3.3.3.3"))
+		require.NoError(t, repo.AddOrUpdate(ctx, "site2.com", "4.4.4.4"))
+		require.NoError(t, repo.AddOrUpdate(ctx, "site3.com", "5.5.5.5"))
+
+		require.NoError(t, repo.CreateGroup(ctx, &models.Group{Name: "payments-egress"}))
+		assert.ErrorIs(t, repo.CreateGroup(ctx, &models.Group{Name: "payments-egress"}), models.ErrConflict)
+
+		require.NoError(t, repo.AddGroupMember(ctx, "payments-egress", "site1.com"))
+		require.NoError(t, repo.AddGroupMember(ctx, "payments-egress", "site2.com"))
+		// Повторное добавление не ошибка
+		require.NoError(t, repo.AddGroupMember(ctx, "payments-egress", "site2.com"))
+
+		ips, err := repo.GetIPsByGroup(ctx, "payments-egress")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"3.3.3.3", "4.4.4.4"}, ips)
+
+		groups, err := repo.ListGroups(ctx, "site1.com")
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.Equal(t, "payments-egress", groups[0].Name)
+
+		require.NoError(t, repo.RemoveGroupMember(ctx, "payments-egress", "site1.com"))
+		fqdns, err := repo.ListGroupMembers(ctx, "payments-egress")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"site2.com"}, fqdns)
+
+		require.NoError(t, repo.DeleteGroup(ctx, "payments-egress"))
+		_, err = repo.GetIPsByGroup(ctx, "payments-egress")
+		assert.ErrorIs(t, err, models.ErrNotFound)
+		assert.ErrorIs(t, repo.AddGroupMember(ctx, "payments-egress", "site1.com"), models.ErrNotFound)
+	})
+
+	t.Run("Group deleted while adding a member", func(t *testing.T) {
+		require.NoError(t, repo.CreateGroup(ctx, &models.Group{Name: "racing"}))
+
+		// Удаление не зафиксировано: AddGroupMember еще видит группу, а проверка
+		// внешнего ключа ждет блокировку строки и после фиксации не проходит
+		tx := db.Begin()
+		require.NoError(t, tx.Exec("DELETE FROM groups WHERE name = 'racing'").Error)
+		done := make(chan error, 1)
+		go func() {
+			done <- repo.AddGroupMember(ctx, "racing", "site1.com")
+		}()
+		require.Eventually(t, func() bool {
+			var waiting int64
+			db.Raw(`SELECT COUNT(*) FROM pg_stat_activity
+				WHERE wait_event_type = 'Lock' AND query LIKE 'INSERT INTO "group_members"%'`).Scan(&waiting)
+			return waiting > 0
+		}, 5*time.Second, 10*time.Millisecond)
+		require.NoError(t, tx.Commit().Error)
+
+		assert.ErrorIs(t, <-done, models.ErrNotFound)
+	})
+
+	t.Run("Tenant isolation", func(t *testing.T) {
+		err := db.Exec("DELETE FROM dns_records").Error
+		require.NoError(t, err)
+
+		require.NoError(t, repo.CreateTenant(ctx, &models.Tenant{ID: models.DefaultTenantID, Name: "default"}))
+		other := &models.Tenant{Name: "team-search"}
+		require.NoError(t, repo.CreateTenant(ctx, other))
+		otherCtx := models.WithTenant(ctx, other.ID)
+
+		// Один и тот же FQDN отслеживается арендаторами независимо
+		require.NoError(t, repo.AddOrUpdate(ctx, "shared.com", "1.1.1.1"))
+		require.NoError(t, repo.AddOrUpdate(otherCtx, "shared.com", "2.2.2.2"))
+
+		ips, err := repo.GetIPsByFQDN(ctx, "shared.com")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1.1.1.1"}, ips)
+
+		ips, err = repo.GetIPsByFQDN(otherCtx, "shared.com")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2.2.2.2"}, ips)
+
+		require.NoError(t, repo.DeleteRecord(otherCtx, "shared.com", "1.1.1.1"))
+		ips, err = repo.GetIPsByFQDN(ctx, "shared.com")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1.1.1.1"}, ips)
+
+		key := &models.APIKey{TenantID: other.ID, Prefix: "dnsr_x", KeyHash: models.HashAPIKey("dnsr_x")}
+		require.NoError(t, repo.CreateAPIKey(ctx, key))
+		found, err := repo.GetAPIKeyByHash(ctx, models.HashAPIKey("dnsr_x"))
+		require.NoError(t, err)
+		assert.Equal(t, other.ID, found.TenantID)
+
+		// Чужой ключ удалить нельзя
+		assert.ErrorIs(t, repo.DeleteAPIKey(ctx, key.ID), models.ErrNotFound)
+		require.NoError(t, repo.DeleteAPIKey(otherCtx, key.ID))
+	})
+
+	t.Run("Queries without tenant fail", func(t *testing.T) {
+		noTenant := context.Background()
+		_, err := repo.GetIPsByFQDN(noTenant, "example.com")
+		assert.ErrorIs(t, err, models.ErrNoTenant)
+		assert.ErrorIs(t, repo.AddOrUpdate(noTenant, "example.com", "1.1.1.1"), models.ErrNoTenant)
+		assert.ErrorIs(t, repo.DeleteRecord(noTenant, "example.com", "1.1.1.1"), models.ErrNoTenant)
+	})
+
+	t.Run("Audit log", func(t *testing.T) {
+		otherCtx := models.WithTenant(ctx, 2)
+		require.NoError(t, repo.AppendAudit(ctx, &models.AuditEntry{Actor: "key:1", Action: "fqdn.add", Target: "a.com", Result: models.AuditSuccess}))
+		require.NoError(t, repo.AppendAudit(ctx, &models.AuditEntry{Actor: "key:1", Action: "group.create", Target: "web", Result: models.AuditSuccess}))
+		require.NoError(t, repo.AppendAudit(ctx, &models.AuditEntry{Actor: "alice", Action: "fqdn.add", Target: "b.com", Result: models.AuditFailure}))
+		require.NoError(t, repo.AppendAudit(otherCtx, &models.AuditEntry{Actor: "bob", Action: "fqdn.add", Target: "c.com", Result: models.AuditSuccess}))
+
+		entries, err := repo.ListAudit(ctx, models.AuditFilter{Action: "fqdn.add", Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "b.com", entries[0].Target)
+		assert.Equal(t, "a.com", entries[1].Target)
+
+		entries, err = repo.ListAudit(ctx, models.AuditFilter{Limit: 1, Offset: 1})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "group.create", entries[0].Action)
+	})
+	t.Run("Delete tenant", func(t *testing.T) {
+		// Внешний ключ журнала задан в миграции 017
+		err := db.Exec("ALTER TABLE audit_entries ADD FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE RESTRICT").Error
+		require.NoError(t, err)
+
+		// У team-search есть записи аудита
+		assert.ErrorIs(t, repo.DeleteTenant(ctx, 2), models.ErrConflict)
+
+		empty := &models.Tenant{Name: "team-empty"}
+		require.NoError(t, repo.CreateTenant(ctx, empty))
+		require.NoError(t, repo.DeleteTenant(ctx, empty.ID))
+		assert.ErrorIs(t, repo.DeleteTenant(ctx, empty.ID), models.ErrNotFound)
+	})
+
+	t.Run("Updater settings", func(t *testing.T) {
+		settings, err := repo.GetUpdaterSettings(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, settings.IntervalSeconds)
+
+		paused, interval := true, time.Minute
+		require.NoError(t, repo.UpdateUpdaterSettings(ctx, models.UpdaterSettingsUpdate{Paused: &paused}))
+		require.NoError(t, repo.UpdateUpdaterSettings(ctx, models.UpdaterSettingsUpdate{Interval: &interval, RequestRefresh: true}))
+		settings, err = repo.GetUpdaterSettings(ctx)
+		require.NoError(t, err)
+		assert.True(t, settings.Paused)
+		assert.Equal(t, float64(60), settings.IntervalSeconds)
+
+		// Запрос прохода достается одному вызвавшему
+		taken, err := repo.TakeUpdaterRefresh(ctx)
+		require.NoError(t, err)
+		assert.True(t, taken)
+		taken, err = repo.TakeUpdaterRefresh(ctx)
+		require.NoError(t, err)
+		assert.False(t, taken)
+	})
+
+	t.Run("Rate limits", func(t *testing.T) {
+		_, ok, err := repo.GetRateLimits(ctx)
+		require.NoError(t, err)
+		assert.False(t, ok, "nothing saved yet")
+
+		config := ratelimit.Config{Read: ratelimit.Budget{Rate: 50, Burst: 100}, Write: ratelimit.Budget{Rate: 0, Burst: 0}}
+		require.NoError(t, repo.SaveRateLimits(ctx, config))
+		config.Write = ratelimit.Budget{Rate: 2, Burst: 10}
+		require.NoError(t, repo.SaveRateLimits(ctx, config))
+
+		saved, ok, err := repo.GetRateLimits(ctx)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, config, saved)
+	})
+
+	t.Run("Canonicalize stored FQDNs", func(t *testing.T) {
+		require.NoError(t, db.Exec("DELETE FROM dns_records").Error)
+		for _, r := range [][2]string{
+			{"xn--e1afmkfd.xn--p1ai", "1.1.1.1"},
+			{"пример.рф", "1.1.1.1"},
+			{"ПРИМЕР.рф.", "2.2.2.2"},
+			{"GitHub.COM", "3.3.3.3"},
+			{"bad_name..com", "4.4.4.4"},
+		} {
+			require.NoError(t, db.Exec("INSERT INTO dns_records (tenant_id, fqdn, ip) VALUES (1, ?, ?)", r[0], r[1]).Error)
+		}
+		require.NoError(t, repo.CreateGroup(ctx, &models.Group{Name: "legacy"}))
+		require.NoError(t, repo.AddGroupMember(ctx, "legacy", "GitHub.COM"))
+		require.NoError(t, repo.AddGroupMember(ctx, "legacy", "github.com"))
+
+		renamed, quarantined, err := repo.CanonicalizeFQDNs(ctx, validator.CanonicalFQDN)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), renamed)
+		assert.Equal(t, int64(1), quarantined)
+
+		ips, err := repo.GetIPsByFQDN(ctx, "xn--e1afmkfd.xn--p1ai")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"1.1.1.1", "2.2.2.2"}, ips)
+		fqdns, err := repo.GetAllFQDNs(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"xn--e1afmkfd.xn--p1ai", "github.com"}, fqdns)
+		members, err := repo.ListGroupMembers(ctx, "legacy")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"github.com"}, members)
+
+		var held []models.QuarantinedRecord
+		require.NoError(t, db.Find(&held).Error)
+		require.Len(t, held, 1)
+		assert.Equal(t, "bad_name..com", held[0].FQDN)
+		assert.Equal(t, "dns_records", held[0].Source)
+
+		// Повторный запуск ничего не меняет
+		renamed, quarantined, err = repo.CanonicalizeFQDNs(ctx, validator.CanonicalFQDN)
+		require.NoError(t, err)
+		assert.Zero(t, renamed)
+		assert.Zero(t, quarantined)
+	})
 }
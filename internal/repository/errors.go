@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"dns-resolver/internal/models"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// isUnavailable отличает недоступность базы от ошибок самого запроса
+func isUnavailable(err error) bool {
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, driver.ErrBadConn),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &connectErr),
+		errors.As(err, &netErr),
+		pgconn.Timeout(err):
+		return true
+	case errors.As(err, &pgErr):
+		// 08 — ошибки соединения, 57P0x — остановка сервера, 53300 — нет свободных соединений
+		return strings.HasPrefix(pgErr.Code, "08") || strings.HasPrefix(pgErr.Code, "57P0") || pgErr.Code == "53300"
+	}
+	return false
+}
+
+func translateUnavailable(tx *gorm.DB) {
+	if tx.Error != nil && !errors.Is(tx.Error, models.ErrUnavailable) && isUnavailable(tx.Error) {
+		tx.Error = fmt.Errorf("%w: %w", models.ErrUnavailable, tx.Error)
+	}
+}
+
+// registerErrorTranslation оборачивает ошибки недоступности базы в models.ErrUnavailable
+// после каждой операции, чтобы API отвечал 503, а не общей ошибкой
+func registerErrorTranslation(db *gorm.DB) error {
+	const name = "dns:translate_unavailable"
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().After("*").Register(name, translateUnavailable),
+		cb.Query().After("*").Register(name, translateUnavailable),
+		cb.Update().After("*").Register(name, translateUnavailable),
+		cb.Delete().After("*").Register(name, translateUnavailable),
+		cb.Row().After("*").Register(name, translateUnavailable),
+		cb.Raw().After("*").Register(name, translateUnavailable),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
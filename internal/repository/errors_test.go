@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"dns-resolver/internal/models"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestTranslateUnavailable(t *testing.T) {
+	for _, tc := range []struct {
+		err         error
+		unavailable bool
+	}{
+		{context.DeadlineExceeded, true},
+		{&pgconn.PgError{Code: "08006"}, true},
+		{&pgconn.PgError{Code: "57P01"}, true},
+		{&pgconn.PgError{Code: "23505"}, false},
+		{gorm.ErrRecordNotFound, false},
+		{errors.New("syntax error"), false},
+	} {
+		tx := &gorm.DB{Statement: &gorm.Statement{}}
+		tx.Error = tc.err
+		translateUnavailable(tx)
+		assert.Equal(t, tc.unavailable, errors.Is(tx.Error, models.ErrUnavailable), "%v", tc.err)
+		assert.ErrorIs(t, tx.Error, tc.err)
+	}
+}
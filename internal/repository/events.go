@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"dns-resolver/internal/models"
+	"time"
+)
+
+func (d *DB) AppendEvent(ctx context.Context, ev *models.Event) error {
+	tenant, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+	ev.TenantID = tenant
+	return d.conn(ctx).Create(ev).Error
+}
+
+// ListEventsSince возвращает события журнала с ID больше afterID в порядке возрастания
+func (d *DB) ListEventsSince(ctx context.Context, afterID uint, limit int) ([]models.Event, error) {
+	var events []models.Event
+	err := d.scoped(ctx).
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// DeleteEventsBefore удаляет события всех арендаторов, случившиеся раньше before
+func (d *DB) DeleteEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	res := d.conn(ctx).Where("occurred_at < ?", before).Delete(&models.Event{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"dns-resolver/internal/models"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// eventsChannel — канал LISTEN/NOTIFY, по которому реплики получают события друг друга
+const eventsChannel = "dns_events"
+
+// eventPayload — событие в уведомлении. В JSON события API арендатор скрыт,
+// а подписчикам он нужен для фильтра
+type eventPayload struct {
+	models.Event
+	TenantID uint `json:"tenant_id"`
+}
+
+// PublishEvents отправляет события всем репликам через pg_notify. В транзакции
+// Postgres доставит уведомления только после фиксации и в порядке фиксаций
+func (d *DB) PublishEvents(ctx context.Context, events []models.Event) error {
+	for _, ev := range events {
+		payload, err := json.Marshal(eventPayload{Event: ev, TenantID: ev.TenantID})
+		if err != nil {
+			return fmt.Errorf("encode event: %w", err)
+		}
+		if err := d.conn(ctx).Exec("SELECT pg_notify(?, ?)", eventsChannel, string(payload)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListenEvents передает fn события, зафиксированные любой репликой, до отмены
+// ctx или обрыва соединения. Слушатель занимает отдельное соединение пула,
+// которое затем закрывается, чтобы LISTEN не достался другим запросам
+func (d *DB) ListenEvents(ctx context.Context, fn func(models.Event)) error {
+	sqlDB, err := d.db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		conn.Raw(func(any) error { return driver.ErrBadConn })
+		conn.Close()
+	}()
+
+	return conn.Raw(func(driverConn any) error {
+		pgConn := driverConn.(*stdlib.Conn).Conn()
+		if _, err := pgConn.Exec(ctx, "LISTEN "+eventsChannel); err != nil {
+			return err
+		}
+
+		for {
+			n, err := pgConn.WaitForNotification(ctx)
+			if err != nil {
+				return err
+			}
+			var p eventPayload
+			if err := json.Unmarshal([]byte(n.Payload), &p); err != nil {
+				return fmt.Errorf("decode event: %w", err)
+			}
+			p.Event.TenantID = p.TenantID
+			fn(p.Event)
+		}
+	})
+}
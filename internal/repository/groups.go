@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"dns-resolver/internal/models"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (d *DB) CreateGroup(ctx context.Context, group *models.Group) error {
+	tenant, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+	group.TenantID = tenant
+	err = d.conn(ctx).Create(group).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.ErrConflict
+	}
+	return err
+}
+
+// ListGroups возвращает все группы или, если задан fqdn, только группы с этим FQDN
+func (d *DB) ListGroups(ctx context.Context, fqdn string) ([]models.Group, error) {
+	var groups []models.Group
+	query := d.scoped(ctx).Order("name")
+	if fqdn != "" {
+		query = query.Where("id IN (?)", d.db.Model(&models.GroupMember{}).Select("group_id").Where("fqdn = ?", fqdn))
+	}
+	if err := query.Find(&groups).Error; err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+func (d *DB) GetGroup(ctx context.Context, name string) (*models.Group, error) {
+	var group models.Group
+	err := d.scoped(ctx).Where("name = ?", name).First(&group).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+func (d *DB) UpdateGroup(ctx context.Context, group *models.Group) error {
+	return d.conn(ctx).Model(group).Update("description", group.Description).Error
+}
+
+// DeleteGroup удаляет группу вместе с членством, сами записи DNS не затрагиваются
+func (d *DB) DeleteGroup(ctx context.Context, name string) error {
+	tenant, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+	return d.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		var group models.Group
+		err := tx.Where("tenant_id = ? AND name = ?", tenant, name).First(&group).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("group_id = ?", group.ID).Delete(&models.GroupMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&group).Error
+	})
+}
+
+func (d *DB) AddGroupMember(ctx context.Context, group, fqdn string) error {
+	g, err := d.GetGroup(ctx, group)
+	if err != nil {
+		return err
+	}
+
+	err = d.conn(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.GroupMember{GroupID: g.ID, FQDN: fqdn}).Error
+	// Группу удалили между чтением и вставкой; с TranslateError драйвер
+	// возвращает gorm.ErrForeignKeyViolated вместо *pgconn.PgError
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		return models.ErrNotFound
+	}
+	return err
+}
+
+func (d *DB) RemoveGroupMember(ctx context.Context, group, fqdn string) error {
+	g, err := d.GetGroup(ctx, group)
+	if err != nil {
+		return err
+	}
+
+	res := d.conn(ctx).Where("group_id = ? AND fqdn = ?", g.ID, fqdn).Delete(&models.GroupMember{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (d *DB) ListGroupMembers(ctx context.Context, group string) ([]string, error) {
+	g, err := d.GetGroup(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+
+	var fqdns []string
+	err = d.conn(ctx).Model(&models.GroupMember{}).
+		Where("group_id = ?", g.ID).
+		Order("fqdn").
+		Pluck("fqdn", &fqdns).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return fqdns, nil
+}
+
+// GetIPsByGroup возвращает объединение IP-адресов всех FQDN группы
+func (d *DB) GetIPsByGroup(ctx context.Context, group string) ([]string, error) {
+	g, err := d.GetGroup(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+
+	var ips []string
+	err = d.conn(ctx).Model(&models.DNSRecord{}).
+		Distinct("dns_records.ip").
+		Joins("JOIN group_members ON group_members.fqdn = dns_records.fqdn").
+		Where("group_members.group_id = ? AND dns_records.tenant_id = ?", g.ID, g.TenantID).
+		Order("dns_records.ip").
+		Pluck("dns_records.ip", &ips).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ips, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"dns-resolver/internal/leader"
+	"errors"
+)
+
+// AdvisoryLocker реализует leader.Locker на сессионной advisory-блокировке.
+// Блокировка принадлежит отдельному соединению: при падении реплики Postgres
+// закрывает сессию и снимает блокировку сам, без ожидания истечения аренды
+type AdvisoryLocker struct {
+	db  *DB
+	key int64
+}
+
+func (d *DB) AdvisoryLocker(key int64) *AdvisoryLocker {
+	return &AdvisoryLocker{db: d, key: key}
+}
+
+// Серверные keepalive ограничивают время, за которое Postgres заметит
+// пропавшего лидера (около 10 с) и отдаст блокировку другой реплике
+var sessionKeepalives = []string{
+	"SET tcp_keepalives_idle = 4",
+	"SET tcp_keepalives_interval = 2",
+	"SET tcp_keepalives_count = 3",
+}
+
+func (l *AdvisoryLocker) TryAcquire(ctx context.Context) (leader.Lease, bool, error) {
+	sqlDB, err := l.db.db.DB()
+	if err != nil {
+		return nil, false, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var ok bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&ok); err != nil || !ok {
+		conn.Close()
+		return nil, false, err
+	}
+
+	for _, stmt := range sessionKeepalives {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			lease := &advisoryLease{conn: conn, key: l.key}
+			return nil, false, errors.Join(err, lease.Release(context.Background()))
+		}
+	}
+	return &advisoryLease{conn: conn, key: l.key}, true, nil
+}
+
+type advisoryLease struct {
+	conn *sql.Conn
+	key  int64
+}
+
+// Renew проверяет, что сессия с блокировкой жива: блокировку держит сессия,
+// поэтому пока запрос на том же соединении проходит, она не потеряна
+func (l *advisoryLease) Renew(ctx context.Context) error {
+	return l.conn.PingContext(ctx)
+}
+
+// Release снимает блокировку и закрывает сессию. Соединение не возвращается
+// в пул: если unlock не прошел, блокировку снимет закрытие сессии
+func (l *advisoryLease) Release(ctx context.Context) error {
+	_, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key)
+	// ErrBadConn из Raw закрывает соединение вместо возврата в пул
+	l.conn.Raw(func(any) error { return driver.ErrBadConn })
+	return err
+}
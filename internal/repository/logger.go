@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"dns-resolver/internal/logging"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// slowQuery — порог, после которого запрос пишется в журнал как медленный
+const slowQuery = 200 * time.Millisecond
+
+// queryLogger пишет запросы GORM в slog: ошибки — error, медленные — warn,
+// остальные — debug. Идентификатор запроса приходит из контекста
+type queryLogger struct {
+	logger *slog.Logger
+}
+
+func newQueryLogger() gormlogger.Interface {
+	return queryLogger{logger: logging.Component("db")}
+}
+
+func (l queryLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return l
+}
+
+func (l queryLogger) Info(ctx context.Context, msg string, args ...any) {
+	l.logger.InfoContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (l queryLogger) Warn(ctx context.Context, msg string, args ...any) {
+	l.logger.WarnContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (l queryLogger) Error(ctx context.Context, msg string, args ...any) {
+	l.logger.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (l queryLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	elapsed := time.Since(begin)
+	var level slog.Level
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		level = slog.LevelError
+	case elapsed > slowQuery:
+		level = slog.LevelWarn
+	default:
+		level = slog.LevelDebug
+	}
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []slog.Attr{slog.String("sql", sql), slog.Int64("rows", rows), slog.Duration("duration", elapsed)}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	l.logger.LogAttrs(ctx, level, "Query", attrs...)
+}
+
+// ParamsFilter убирает значения параметров из SQL в журнале: среди них
+// бывают хэши ключей и другие данные арендаторов
+func (l queryLogger) ParamsFilter(ctx context.Context, sql string, params ...any) (string, []any) {
+	return sql, nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"dns-resolver/internal/logging"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestQueryLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, slog.LevelInfo, logging.FormatJSON)
+	require.NoError(t, err)
+	l := queryLogger{logger: logger}
+
+	ctx := logging.WithRequestID(context.Background(), "req-42")
+	query := func() (string, int64) { return "SELECT 1", 1 }
+
+	// Обычные запросы и отсутствие записи — только на уровне debug
+	l.Trace(ctx, time.Now(), query, nil)
+	l.Trace(ctx, time.Now(), query, gorm.ErrRecordNotFound)
+	assert.Empty(t, buf.String())
+
+	l.Trace(ctx, time.Now(), query, errors.New("connection reset"))
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "ERROR", entry["level"])
+	assert.Equal(t, "SELECT 1", entry["sql"])
+	assert.Equal(t, "connection reset", entry["error"])
+	assert.Equal(t, "req-42", entry[logging.RequestIDKey])
+
+	buf.Reset()
+	l.Trace(ctx, time.Now().Add(-time.Second), query, nil)
+	assert.Contains(t, buf.String(), `"level":"WARN"`)
+
+	sql, params := l.ParamsFilter(ctx, "SELECT * FROM api_keys WHERE hash = $1", "secret")
+	assert.Equal(t, "SELECT * FROM api_keys WHERE hash = $1", sql)
+	assert.Empty(t, params)
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"dns-resolver/internal/models"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+const (
+	// regexSearchTimeout — предел statement_timeout для поиска по шаблону
+	regexSearchTimeout = 5 * time.Second
+
+	// pgQueryCanceled — код отмены запроса, в том числе по statement_timeout
+	pgQueryCanceled = "57014"
+)
+
+// ListFQDNsByIP возвращает страницу FQDN, которые резолвятся в ip
+func (d *DB) ListFQDNsByIP(ctx context.Context, ip string, q models.PageQuery) (models.Page, error) {
+	return d.pageRecords(ctx, "fqdn", "ip = ?", ip, q)
+}
+
+// ListIPsByFQDN возвращает страницу IP домена
+func (d *DB) ListIPsByFQDN(ctx context.Context, fqdn string, q models.PageQuery) (models.Page, error) {
+	return d.pageRecords(ctx, "ip", "fqdn = ?", fqdn, q)
+}
+
+// ListFQDNs возвращает страницу отслеживаемых FQDN. Поддерживается только
+// сортировка по имени: у домена нет одного updated_at
+func (d *DB) ListFQDNs(ctx context.Context, q models.PageQuery) (models.Page, error) {
+	return d.SearchFQDNs(ctx, models.DomainQuery{}, q)
+}
+
+// SearchFQDNs возвращает страницу FQDN, подходящих под запрос. Суффикс ищется
+// по индексу reverse(fqdn), подстрока и регулярные выражения — по триграммам
+func (d *DB) SearchFQDNs(ctx context.Context, search models.DomainQuery, q models.PageQuery) (models.Page, error) {
+	if search.Pattern == "" || search.Match != models.MatchGlob && search.Match != models.MatchRegex {
+		return d.searchFQDNs(ctx, search, q)
+	}
+
+	// Регулярное выражение от клиента может перебирать долго и в синтаксисе RE2:
+	// движок Postgres допускает возвраты. Запрос ограничен по времени
+	var page models.Page
+	err := d.Transaction(ctx, func(ctx context.Context) error {
+		err := d.conn(ctx).Exec(fmt.Sprintf("SET LOCAL statement_timeout = %d", d.searchTimeout.Milliseconds())).Error
+		if err != nil {
+			return err
+		}
+		page, err = d.searchFQDNs(ctx, search, q)
+		return err
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgQueryCanceled {
+		return page, fmt.Errorf("%w: %w", models.ErrSearchTimeout, err)
+	}
+	return page, err
+}
+
+func (d *DB) searchFQDNs(ctx context.Context, search models.DomainQuery, q models.PageQuery) (models.Page, error) {
+	filtered := func() *gorm.DB {
+		query := d.scoped(ctx).Model(&models.DNSRecord{})
+		if search.Suffix != "" {
+			query = query.Where("(fqdn = ? OR reverse(fqdn) LIKE ?)", search.Suffix, likeEscape(reverse("."+search.Suffix))+"%")
+		}
+		switch {
+		case search.Pattern == "":
+		case search.Match == models.MatchGlob || search.Match == models.MatchRegex:
+			// Имена хранятся в нижнем регистре: "API-.*" должно найти api-v2
+			query = query.Where("fqdn ~* ?", search.Expr())
+		default:
+			query = query.Where("fqdn LIKE ?", "%"+likeEscape(strings.ToLower(search.Pattern))+"%")
+		}
+		return query
+	}
+
+	page := models.Page{Items: []string{}}
+	if err := filtered().Distinct("fqdn").Count(&page.Total).Error; err != nil {
+		return page, err
+	}
+
+	query := filtered().Distinct("fqdn")
+	op, order := ">", "fqdn"
+	if q.Desc {
+		op, order = "<", "fqdn DESC"
+	}
+	if q.After != nil {
+		query = query.Where("fqdn "+op+" ?", q.After.Name)
+	}
+
+	query = query.Order(order)
+	if q.Limit > 0 {
+		query = query.Limit(q.Limit + 1)
+	}
+	var fqdns []string
+	if err := query.Pluck("fqdn", &fqdns).Error; err != nil {
+		return page, err
+	}
+
+	if q.Limit > 0 && len(fqdns) > q.Limit {
+		fqdns = fqdns[:q.Limit]
+		page.Next = &models.Cursor{Sort: models.SortByName, Desc: q.Desc, Name: fqdns[len(fqdns)-1]}
+	}
+	page.Items = append(page.Items, fqdns...)
+	return page, nil
+}
+
+// pageRecords выбирает страницу значений column среди записей, подходящих под
+// cond. Пара (tenant_id, fqdn, ip) уникальна, поэтому column однозначно
+// задает позицию при сортировке по имени и разрешает равенство updated_at
+func (d *DB) pageRecords(ctx context.Context, column, cond string, arg any, q models.PageQuery) (models.Page, error) {
+	page := models.Page{Items: []string{}}
+	filtered := func() *gorm.DB {
+		return d.scoped(ctx).Model(&models.DNSRecord{}).Where(cond, arg)
+	}
+
+	if err := filtered().Count(&page.Total).Error; err != nil {
+		return page, err
+	}
+
+	op, dir := ">", ""
+	if q.Desc {
+		op, dir = "<", " DESC"
+	}
+
+	query := filtered().Select(column, "updated_at")
+	if q.Sort == models.SortByUpdatedAt {
+		if q.After != nil {
+			query = query.Where("(updated_at, "+column+") "+op+" (?, ?)", *q.After.UpdatedAt, q.After.Name)
+		}
+		query = query.Order("updated_at" + dir).Order(column + dir)
+	} else {
+		if q.After != nil {
+			query = query.Where(column+" "+op+" ?", q.After.Name)
+		}
+		query = query.Order(column + dir)
+	}
+
+	if q.Limit > 0 {
+		query = query.Limit(q.Limit + 1)
+	}
+	var records []models.DNSRecord
+	if err := query.Find(&records).Error; err != nil {
+		return page, err
+	}
+
+	if q.Limit > 0 && len(records) > q.Limit {
+		records = records[:q.Limit]
+		last := records[len(records)-1]
+		page.Next = &models.Cursor{Sort: models.SortByName, Desc: q.Desc, Name: recordValue(last, column)}
+		if q.Sort == models.SortByUpdatedAt {
+			page.Next.Sort = models.SortByUpdatedAt
+			page.Next.UpdatedAt = &last.UpdatedAt
+		}
+	}
+
+	for _, record := range records {
+		page.Items = append(page.Items, recordValue(record, column))
+	}
+	return page, nil
+}
+
+func recordValue(record models.DNSRecord, column string) string {
+	if column == "ip" {
+		return record.IP
+	}
+	return record.FQDN
+}
+
+// likeEscape экранирует спецсимволы LIKE (экранирующий символ по умолчанию — \)
+func likeEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// reverse разворачивает строку по байтам, что совпадает с reverse() в Postgres:
+// имена хранятся в ASCII (IDN — в punycode)
+func reverse(s string) string {
+	b := []byte(s)
+	slices.Reverse(b)
+	return string(b)
+}
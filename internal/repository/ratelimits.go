@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"dns-resolver/internal/models"
+	"dns-resolver/internal/ratelimit"
+
+	"gorm.io/gorm/clause"
+)
+
+// GetRateLimits возвращает бюджеты, сохраненные через API. false — их
+// не меняли, и действуют значения из конфигурации процесса
+func (d *DB) GetRateLimits(ctx context.Context) (ratelimit.Config, bool, error) {
+	var settings models.RateLimitSettings
+	res := d.conn(ctx).Where("id = ?", models.RateLimitSettingsID).Limit(1).Find(&settings)
+	if res.Error != nil || res.RowsAffected == 0 {
+		return ratelimit.Config{}, false, res.Error
+	}
+
+	return ratelimit.Config{
+		Read:  ratelimit.Budget{Rate: settings.ReadRate, Burst: settings.ReadBurst},
+		Write: ratelimit.Budget{Rate: settings.WriteRate, Burst: settings.WriteBurst},
+	}, true, nil
+}
+
+func (d *DB) SaveRateLimits(ctx context.Context, config ratelimit.Config) error {
+	settings := models.RateLimitSettings{
+		ID:         models.RateLimitSettingsID,
+		ReadRate:   config.Read.Rate,
+		ReadBurst:  config.Read.Burst,
+		WriteRate:  config.Write.Rate,
+		WriteBurst: config.Write.Burst,
+	}
+	return d.conn(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"read_rate", "read_burst", "write_rate", "write_burst", "updated_at"}),
+	}).Create(&settings).Error
+}
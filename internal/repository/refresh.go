@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"dns-resolver/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Очередь перепроверки общая для всех арендаторов и реплик. Сроки считаются
+// по часам Postgres, чтобы расхождение часов реплик не влияло на аренды
+
+// SyncRefreshJobs заводит задания для FQDN без задания и удаляет задания FQDN,
+// у которых не осталось записей. Первая перепроверка нового задания —
+// через interval после последнего обновления его записей
+func (d *DB) SyncRefreshJobs(ctx context.Context, interval time.Duration) (added, removed int64, err error) {
+	err = d.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Exec(`INSERT INTO refresh_jobs (tenant_id, fqdn, due_at, updated_at)
+			SELECT tenant_id, fqdn, MAX(updated_at) + ? * INTERVAL '1 second', NOW()
+			FROM dns_records GROUP BY tenant_id, fqdn
+			ON CONFLICT (tenant_id, fqdn) DO NOTHING`, interval.Seconds())
+		if res.Error != nil {
+			return res.Error
+		}
+		added = res.RowsAffected
+
+		res = tx.Exec(`DELETE FROM refresh_jobs j WHERE NOT EXISTS (
+			SELECT 1 FROM dns_records r WHERE r.tenant_id = j.tenant_id AND r.fqdn = j.fqdn)`)
+		removed = res.RowsAffected
+		return res.Error
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return added, removed, nil
+}
+
+// ClaimRefreshJobs выдает owner до limit подошедших заданий на время visibility.
+// Занятые другой транзакцией строки пропускаются, а задания с истекшей арендой
+// выдаются повторно: так задания упавшей реплики забирают остальные
+func (d *DB) ClaimRefreshJobs(ctx context.Context, owner string, limit int, visibility time.Duration) ([]models.RefreshJob, error) {
+	var jobs []models.RefreshJob
+	err := d.conn(ctx).Raw(`UPDATE refresh_jobs
+		SET lease_owner = ?, lease_until = NOW() + ? * INTERVAL '1 second', claims = claims + 1, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM refresh_jobs
+			WHERE due_at <= NOW() AND (lease_until IS NULL OR lease_until <= NOW())
+			ORDER BY due_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED)
+		RETURNING *`, owner, visibility.Seconds(), limit).Scan(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// CompleteRefreshJob снимает аренду и назначает следующую перепроверку через after.
+// Если задание с тех пор выдано другому обработчику, возвращает ErrLeaseLost
+func (d *DB) CompleteRefreshJob(ctx context.Context, job *models.RefreshJob, after time.Duration, jobErr error) error {
+	updates := map[string]any{
+		"due_at":      gorm.Expr("NOW() + ? * INTERVAL '1 second'", after.Seconds()),
+		"lease_owner": "",
+		"lease_until": nil,
+		"attempts":    0,
+		"last_error":  "",
+	}
+	if jobErr != nil {
+		updates["attempts"] = gorm.Expr("attempts + 1")
+		updates["last_error"] = jobErr.Error()
+	}
+
+	res := d.conn(ctx).Model(&models.RefreshJob{}).
+		Where("id = ? AND claims = ?", job.ID, job.Claims).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return models.ErrLeaseLost
+	}
+
+	return nil
+}
+
+// RequeueRefreshJobs назначает перепроверку всех заданий на сейчас: их разберут
+// обработчики при следующем опросе. Аренды не снимаются — выданные задания
+// завершатся как обычно
+func (d *DB) RequeueRefreshJobs(ctx context.Context) (int64, error) {
+	res := d.conn(ctx).Model(&models.RefreshJob{}).
+		Where("due_at > NOW()").
+		Update("due_at", gorm.Expr("NOW()"))
+	return res.RowsAffected, res.Error
+}
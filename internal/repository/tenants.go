@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"dns-resolver/internal/models"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func (d *DB) CreateTenant(ctx context.Context, tenant *models.Tenant) error {
+	err := d.conn(ctx).Create(tenant).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.ErrConflict
+	}
+	return err
+}
+
+func (d *DB) ListTenants(ctx context.Context) ([]models.Tenant, error) {
+	var tenants []models.Tenant
+	if err := d.conn(ctx).Order("id").Find(&tenants).Error; err != nil {
+		return nil, err
+	}
+
+	return tenants, nil
+}
+
+// DeleteTenant удаляет арендатора вместе с его данными. Журнал аудита
+// не удаляется, поэтому арендатор с записями аудита дает ErrConflict
+func (d *DB) DeleteTenant(ctx context.Context, id uint) error {
+	res := d.conn(ctx).Delete(&models.Tenant{}, id)
+	if errors.Is(res.Error, gorm.ErrForeignKeyViolated) {
+		return fmt.Errorf("%w: tenant has audit entries", models.ErrConflict)
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// CreateAPIKey сохраняет ключ для key.TenantID, который задает вызывающий
+func (d *DB) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	return d.conn(ctx).Create(key).Error
+}
+
+func (d *DB) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	if err := d.scoped(ctx).Order("id").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (d *DB) DeleteAPIKey(ctx context.Context, id uint) error {
+	res := d.scoped(ctx).Delete(&models.APIKey{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// GetAPIKeyByHash ищет ключ среди всех арендаторов: по нему арендатор и определяется
+func (d *DB) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := d.conn(ctx).Where("key_hash = ?", hash).First(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
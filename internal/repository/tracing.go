@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+var tracer = otel.Tracer("dns-resolver/internal/repository")
+
+const spanInstanceKey = "dns:span"
+
+func startSpan(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		ctx, span := tracer.Start(tx.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemNamePostgreSQL, semconv.DBOperationName(operation)),
+		)
+		// Контекст спана уходит в драйвер и журнал запросов
+		tx.Statement.Context = ctx
+		tx.InstanceSet(spanInstanceKey, span)
+	}
+}
+
+func endSpan(tx *gorm.DB) {
+	v, _ := tx.InstanceGet(spanInstanceKey)
+	span, ok := v.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	// В SQL остаются плейсхолдеры, значения параметров в спан не попадают
+	span.SetAttributes(semconv.DBQueryText(tx.Statement.SQL.String()), attribute.Int64("db.rows_affected", tx.Statement.RowsAffected))
+	if tx.Statement.Table != "" {
+		span.SetAttributes(semconv.DBCollectionName(tx.Statement.Table))
+	}
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(tx.Error)
+		span.SetStatus(codes.Error, tx.Error.Error())
+	}
+}
+
+// registerTracing оборачивает каждую операцию GORM спаном OpenTelemetry
+func registerTracing(db *gorm.DB) error {
+	const start, end = "dns:trace_start", "dns:trace_end"
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().Before("*").Register(start, startSpan("create")),
+		cb.Create().After("*").Register(end, endSpan),
+		cb.Query().Before("*").Register(start, startSpan("query")),
+		cb.Query().After("*").Register(end, endSpan),
+		cb.Update().Before("*").Register(start, startSpan("update")),
+		cb.Update().After("*").Register(end, endSpan),
+		cb.Delete().Before("*").Register(start, startSpan("delete")),
+		cb.Delete().After("*").Register(end, endSpan),
+		cb.Row().Before("*").Register(start, startSpan("row")),
+		cb.Row().After("*").Register(end, endSpan),
+		cb.Raw().Before("*").Register(start, startSpan("raw")),
+		cb.Raw().After("*").Register(end, endSpan),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
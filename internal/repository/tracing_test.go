@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"dns-resolver/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+
+	// DryRun строит SQL и проходит все колбэки, не обращаясь к базе
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	require.NoError(t, registerTracing(db))
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	var records []models.DNSRecord
+	db.WithContext(ctx).Where("fqdn = ?", "example.com").Find(&records)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	query := spans[0]
+	assert.Equal(t, "gorm.query", query.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), query.Parent().SpanID())
+
+	attrs := map[string]string{}
+	for _, kv := range query.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	assert.Equal(t, "postgresql", attrs["db.system.name"])
+	assert.Equal(t, "dns_records", attrs["db.collection.name"])
+	assert.Equal(t, `SELECT * FROM "dns_records" WHERE fqdn = $1`, attrs["db.query.text"])
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"dns-resolver/internal/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// GetUpdaterSettings возвращает общие настройки обновления. Пока их не меняли,
+// строки нет и возвращается нулевое значение
+func (d *DB) GetUpdaterSettings(ctx context.Context) (models.UpdaterSettings, error) {
+	var settings models.UpdaterSettings
+	err := d.conn(ctx).Where("id = ?", models.UpdaterSettingsID).Limit(1).Find(&settings).Error
+	return settings, err
+}
+
+// UpdateUpdaterSettings сохраняет заданные в update поля, остальные не трогает
+func (d *DB) UpdateUpdaterSettings(ctx context.Context, update models.UpdaterSettingsUpdate) error {
+	settings := models.UpdaterSettings{ID: models.UpdaterSettingsID}
+	columns := []string{"updated_at"}
+	if update.Interval != nil {
+		settings.IntervalSeconds = update.Interval.Seconds()
+		columns = append(columns, "interval_seconds")
+	}
+	if update.Paused != nil {
+		settings.Paused = *update.Paused
+		columns = append(columns, "paused")
+	}
+	if update.RequestRefresh {
+		settings.RefreshRequested = true
+		columns = append(columns, "refresh_requested")
+	}
+
+	return d.conn(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns(columns),
+	}).Create(&settings).Error
+}
+
+// TakeUpdaterRefresh снимает запрос внеочередного прохода. true — запрос был,
+// и проход должен выполнить вызвавший
+func (d *DB) TakeUpdaterRefresh(ctx context.Context) (bool, error) {
+	res := d.conn(ctx).Model(&models.UpdaterSettings{}).
+		Where("id = ? AND refresh_requested", models.UpdaterSettingsID).
+		Update("refresh_requested", false)
+	return res.RowsAffected > 0, res.Error
+}
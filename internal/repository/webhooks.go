@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"dns-resolver/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (d *DB) CreateWebhook(ctx context.Context, hook *models.Webhook) error {
+	tenant, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+	hook.TenantID = tenant
+	return d.conn(ctx).Create(hook).Error
+}
+
+func (d *DB) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	var hooks []models.Webhook
+	err := d.scoped(ctx).Order("id").Find(&hooks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return hooks, nil
+}
+
+func (d *DB) GetWebhook(ctx context.Context, id uint) (*models.Webhook, error) {
+	var hook models.Webhook
+	err := d.scoped(ctx).First(&hook, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &hook, nil
+}
+
+// DeleteWebhook удаляет вебхук вместе с его очередью доставки
+func (d *DB) DeleteWebhook(ctx context.Context, id uint) error {
+	tenant, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+	return d.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("tenant_id = ?", tenant).Delete(&models.Webhook{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return models.ErrNotFound
+		}
+
+		return tx.Where("webhook_id = ?", id).Delete(&models.WebhookDelivery{}).Error
+	})
+}
+
+func (d *DB) EnqueueDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return d.conn(ctx).Create(&deliveries).Error
+}
+
+// ClaimDueDeliveries забирает из outbox доставки всех арендаторов, время которых
+// подошло, и сдвигает их next_attempt_at на lease, чтобы их не забрал другой обработчик
+func (d *DB) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := d.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i] = delivery.ID
+		}
+
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (d *DB) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return d.conn(ctx).Save(delivery).Error
+}
+
+func (d *DB) ListDeliveries(ctx context.Context, webhookID uint, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := d.scoped(ctx).
+		Where("webhook_id = ?", webhookID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
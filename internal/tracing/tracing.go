@@ -0,0 +1,66 @@
+// Пакет tracing настраивает OpenTelemetry: экспорт спанов и извлечение
+// контекста трассировки из входящих заголовков
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+
+	ServiceName = "dns-resolver"
+)
+
+// Setup включает трассировку с экспортером none (по умолчанию), otlp или stdout.
+// Экспортер stdout пишет спаны в out — имя взято из OpenTelemetry, а поток
+// выбирает вызывающий. Адрес коллектора и семплирование задаются стандартными
+// переменными OTEL_EXPORTER_OTLP_* и OTEL_TRACES_SAMPLER. Возвращает функцию,
+// которая отправляет накопленные спаны и останавливает экспорт
+func Setup(ctx context.Context, exporter string, out io.Writer) (func(context.Context) error, error) {
+	// Контекст из traceparent/baggage принимается и без экспорта: его
+	// идентификатор трассировки попадает в журнал
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exp sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exp, err = otlptracegrpc.New(ctx)
+	case ExporterStdout:
+		exp, err = stdouttrace.New(stdouttrace.WithWriter(out))
+	default:
+		return nil, fmt.Errorf("invalid traces exporter %q: want %s, %s or %s", exporter, ExporterNone, ExporterOTLP, ExporterStdout)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(ServiceName)),
+		// OTEL_SERVICE_NAME и OTEL_RESOURCE_ATTRIBUTES важнее значений по умолчанию
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exp), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,33 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := Setup(ctx, "jaeger", nil)
+	assert.Error(t, err)
+
+	shutdown, err := Setup(ctx, ExporterNone, nil)
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(ctx))
+
+	var out bytes.Buffer
+	shutdown, err = Setup(ctx, ExporterStdout, &out)
+	require.NoError(t, err)
+	_, span := otel.Tracer("test").Start(ctx, "AddFQDN")
+	span.End()
+
+	// Спаны уходят пачками, shutdown отправляет остаток
+	require.NoError(t, shutdown(ctx))
+	assert.Contains(t, out.String(), `"Name":"AddFQDN"`)
+	assert.Contains(t, out.String(), `"Value":"dns-resolver"`)
+}
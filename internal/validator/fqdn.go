@@ -0,0 +1,83 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+const (
+	maxFQDNLength  = 253
+	maxLabelLength = 63
+)
+
+// ErrInvalidFQDN оборачивает все ошибки проверки имени
+var ErrInvalidFQDN = errors.New("invalid fqdn")
+
+var labelRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// CanonicalFQDN приводит имя к виду, в котором оно хранится: нижний регистр,
+// без завершающей точки, IDN в punycode (A-label). Имя должно быть
+// корректным именем хоста по RFC 1123
+func CanonicalFQDN(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	name = strings.TrimSuffix(name, ".")
+	if name == "" {
+		return "", fmt.Errorf("%w: name is empty", ErrInvalidFQDN)
+	}
+	if net.ParseIP(name) != nil {
+		return "", fmt.Errorf("%w: %q is an IP address, not a host name", ErrInvalidFQDN, name)
+	}
+
+	ascii, err := idna.Lookup.ToASCII(name)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q: %v", ErrInvalidFQDN, name, err)
+	}
+	ascii = strings.ToLower(ascii)
+
+	if len(ascii) > maxFQDNLength {
+		return "", fmt.Errorf("%w: name is longer than %d characters", ErrInvalidFQDN, maxFQDNLength)
+	}
+	for _, label := range strings.Split(ascii, ".") {
+		if len(label) > maxLabelLength {
+			return "", fmt.Errorf("%w: label %q is longer than %d characters", ErrInvalidFQDN, label, maxLabelLength)
+		}
+		if !labelRe.MatchString(label) {
+			return "", fmt.Errorf("%w: invalid label %q", ErrInvalidFQDN, label)
+		}
+	}
+
+	return ascii, nil
+}
+
+// CanonicalGlob приводит glob-шаблон имени к виду хранимых имен: нижний
+// регистр и A-label для меток без спецсимволов, например "*.Пример.рф" —
+// "*.xn--e1afmkfd.xn--p1ai". Метки со спецсимволами переводятся только
+// в нижний регистр
+func CanonicalGlob(pattern string) string {
+	labels := strings.Split(strings.TrimSuffix(strings.TrimSpace(pattern), "."), ".")
+	for i, label := range labels {
+		label = strings.ToLower(label)
+		if !strings.ContainsAny(label, `*?[]\`) {
+			if ascii, err := idna.Lookup.ToASCII(label); err == nil {
+				label = ascii
+			}
+		}
+		labels[i] = label
+	}
+	return strings.Join(labels, ".")
+}
+
+// UnicodeFQDN возвращает U-label форму канонического имени для отображения.
+// Имена без IDN-меток и некорректный punycode возвращаются без изменений
+func UnicodeFQDN(fqdn string) string {
+	unicode, err := idna.Display.ToUnicode(fqdn)
+	if err != nil {
+		return fqdn
+	}
+	return unicode
+}
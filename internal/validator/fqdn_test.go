@@ -0,0 +1,48 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalFQDN(t *testing.T) {
+	for in, want := range map[string]string{
+		"github.com":      "github.com",
+		"github.com.":     "github.com",
+		"GitHub.COM":      "github.com",
+		" api.github.com": "api.github.com",
+		"пример.рф":       "xn--e1afmkfd.xn--p1ai",
+		"xn--p1ai":        "xn--p1ai",
+	} {
+		got, err := CanonicalFQDN(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	assert.Equal(t, "*.xn--e1afmkfd.xn--p1ai", CanonicalGlob("*.Пример.РФ."))
+	assert.Equal(t, "api-*.example.com", CanonicalGlob("API-*.Example.com"))
+	assert.Equal(t, "[a-z]?.corp.*", CanonicalGlob("[A-Z]?.Corp.*"))
+
+	assert.Equal(t, "пример.рф", UnicodeFQDN("xn--e1afmkfd.xn--p1ai"))
+	assert.Equal(t, "github.com", UnicodeFQDN("github.com"))
+
+	for _, in := range []string{
+		"",
+		".",
+		"github..com",
+		"-github.com",
+		"github-.com",
+		"git hub.com",
+		"git_hub.com",
+		"1.2.3.4",
+		"example.com/path",
+		strings.Repeat("a", 64) + ".com",
+		strings.Repeat("a.", 127) + "com",
+	} {
+		_, err := CanonicalFQDN(in)
+		assert.ErrorIs(t, err, ErrInvalidFQDN, in)
+	}
+}
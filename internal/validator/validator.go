@@ -1,15 +1,35 @@
 package validator
 
-import "github.com/go-playground/validator/v10"
+import (
+	"regexp"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var slugRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
 
 type CustomValidator struct {
 	validator *validator.Validate
 }
 
 func New() *CustomValidator {
-	return &CustomValidator{validator: validator.New()}
+	v := validator.New()
+	v.RegisterValidation("slug", func(fl validator.FieldLevel) bool {
+		return IsSlug(fl.Field().String())
+	})
+	v.RegisterValidation("dnsname", func(fl validator.FieldLevel) bool {
+		_, err := CanonicalFQDN(fl.Field().String())
+		return err == nil
+	})
+
+	return &CustomValidator{validator: v}
 }
 
 func (cv *CustomValidator) Validate(i interface{}) error {
 	return cv.validator.Struct(i)
-}
\ No newline at end of file
+}
+
+// IsSlug проверяет имя группы или арендатора: строчные буквы, цифры, '-' и '_'
+func IsSlug(name string) bool {
+	return slugRe.MatchString(name)
+}
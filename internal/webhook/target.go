@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// ErrForbiddenTarget — адрес вебхука ведет во внутреннюю сеть
+var ErrForbiddenTarget = errors.New("webhook target address is not allowed")
+
+// sharedAddressSpace — CGNAT (RFC 6598), снаружи недоступен, как и частные сети
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// publicAddr пропускает только глобальные unicast-адреса: вебхук не должен
+// достучаться до loopback, частных и link-local сетей, в том числе
+// до метаданных облака на 169.254.169.254
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// CheckURL проверяет адрес вебхука при регистрации: схема http или https,
+// а IP-литерал и localhost не ведут во внутреннюю сеть. Имена хостов
+// проверяются при каждом соединении, уже после резолвинга
+func CheckURL(raw string) error {
+	u, err := parseURL(raw)
+	if err != nil {
+		return err
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" {
+		return errors.New("url has no host")
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrForbiddenTarget
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !publicAddr(addr) {
+		return ErrForbiddenTarget
+	}
+	return nil
+}
+
+func parseURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.New("url scheme must be http or https")
+	}
+	return u, nil
+}
+
+// control отказывает в соединении с непубличным адресом. Проверяется адрес
+// после резолвинга, так что ни DNS-имя, ни редирект запрет не обходят
+func (s *Service) control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !s.allowAddr(addr) {
+		return fmt.Errorf("%w: %s", ErrForbiddenTarget, host)
+	}
+	return nil
+}
+
+func (s *Service) newClient() *http.Client {
+	dialer := &net.Dialer{Timeout: deliveryTimeout, Control: s.control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Через прокси соединение шло бы к нему, и проверка адреса не сработала бы
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: deliveryTimeout, Transport: transport}
+}
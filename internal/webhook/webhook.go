@@ -0,0 +1,228 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"dns-resolver/internal/logging"
+	"dns-resolver/internal/models"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+
+	maxAttempts     = 8
+	baseBackoff     = 30 * time.Second
+	maxBackoff      = time.Hour
+	batchSize       = 50
+	deliveryTimeout = 10 * time.Second
+	// claimLease с запасом перекрывает одну доставку: доставки забираются
+	// по одной, поэтому аренда не истекает, пока очередь до них не дошла
+	claimLease = 6 * deliveryTimeout
+)
+
+// Service складывает события в outbox и доставляет их подписчикам
+type Service struct {
+	repo   models.Repository
+	client *http.Client
+	logger *slog.Logger
+	// allowAddr решает, можно ли соединяться с адресом получателя
+	allowAddr func(addr netip.Addr) bool
+}
+
+func NewService(repo models.Repository) *Service {
+	s := &Service{
+		repo:      repo,
+		logger:    logging.Component("webhooks"),
+		allowAddr: publicAddr,
+	}
+	s.client = s.newClient()
+	return s
+}
+
+// Sign считает HMAC-SHA256 подпись тела запроса вместе с меткой времени
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Notify ставит события в очередь доставки для всех подходящих вебхуков.
+// Резолвер вызывает его в транзакции изменения записей, так что доставки
+// сохраняются вместе с изменением или не сохраняются вовсе. Вебхуки
+// читаются один раз на все события, чтобы не держать транзакцию дольше нужного
+func (s *Service) Notify(ctx context.Context, events []models.Event) error {
+	hooks, err := s.repo.ListWebhooks(ctx)
+	if err != nil {
+		return fmt.Errorf("list webhooks: %w", err)
+	}
+
+	var deliveries []models.WebhookDelivery
+	for _, ev := range events {
+		payload, err := json.Marshal(ev)
+		if err != nil {
+			return fmt.Errorf("encode event: %w", err)
+		}
+		for _, hook := range hooks {
+			if !hook.Matches(ev) {
+				continue
+			}
+			deliveries = append(deliveries, models.WebhookDelivery{
+				TenantID:      hook.TenantID,
+				WebhookID:     hook.ID,
+				EventType:     string(ev.Type),
+				Payload:       string(payload),
+				Status:        models.DeliveryPending,
+				NextAttemptAt: ev.OccurredAt,
+			})
+		}
+	}
+
+	if err := s.repo.EnqueueDeliveries(ctx, deliveries); err != nil {
+		return fmt.Errorf("enqueue deliveries: %w", err)
+	}
+	return nil
+}
+
+// Run периодически разбирает outbox до отмены контекста
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.DeliverDue(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// DeliverDue отправляет до batchSize доставок, время которых подошло.
+// Каждая забирается непосредственно перед отправкой, чтобы ее аренда
+// не истекла, пока отправляются предыдущие
+func (s *Service) DeliverDue(ctx context.Context) {
+	for i := 0; i < batchSize && ctx.Err() == nil; i++ {
+		deliveries, err := s.repo.ClaimDueDeliveries(ctx, 1, claimLease)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to claim deliveries", "error", err)
+			return
+		}
+		if len(deliveries) == 0 {
+			return
+		}
+		s.deliver(ctx, &deliveries[0])
+	}
+}
+
+func (s *Service) deliver(ctx context.Context, delivery *models.WebhookDelivery) {
+	ctx = models.WithTenant(ctx, delivery.TenantID)
+	hook, err := s.repo.GetWebhook(ctx, delivery.WebhookID)
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		// Вебхук удален: доставлять больше некому
+		delivery.Status = models.DeliveryFailed
+		delivery.LastError = fmt.Sprintf("webhook unavailable: %v", err)
+		s.save(ctx, delivery)
+		return
+	case err != nil:
+		// Сбой базы или остановка сервиса — не вина получателя, попытка не считается
+		if ctx.Err() != nil {
+			return
+		}
+		delivery.NextAttemptAt = time.Now().Add(Backoff(delivery.Attempts))
+		delivery.LastError = fmt.Sprintf("load webhook: %v", err)
+		s.save(ctx, delivery)
+		return
+	}
+
+	delivery.Attempts++
+	code, err := s.send(ctx, hook, delivery)
+	delivery.ResponseCode = code
+
+	switch {
+	case err == nil:
+		now := time.Now()
+		delivery.Status = models.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	case delivery.Attempts >= maxAttempts:
+		delivery.Status = models.DeliveryFailed
+		delivery.LastError = err.Error()
+	default:
+		delivery.NextAttemptAt = time.Now().Add(Backoff(delivery.Attempts))
+		delivery.LastError = err.Error()
+	}
+
+	s.save(ctx, delivery)
+}
+
+func (s *Service) send(ctx context.Context, hook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	// Адреса проверяет control при соединении, здесь остается схема:
+	// вебхуки могли быть созданы до проверки при регистрации
+	if _, err := parseURL(hook.URL); err != nil {
+		return 0, err
+	}
+
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func (s *Service) save(ctx context.Context, delivery *models.WebhookDelivery) {
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to update delivery", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+// Backoff возвращает задержку перед следующей попыткой: 30s, 1m, 2m, ... до 1h
+func Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := baseBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
@@ -0,0 +1,244 @@
+package webhook
+
+import (
+	"context"
+	"dns-resolver/internal/models"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRepository хранит вебхуки и доставки в памяти
+type fakeRepository struct {
+	models.Repository
+	hooks      []models.Webhook
+	deliveries []models.WebhookDelivery
+	listCalls  int
+	// getErr — сбой чтения вебхука, например потеря соединения с базой
+	getErr error
+}
+
+func (f *fakeRepository) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	f.listCalls++
+	return f.hooks, nil
+}
+
+func (f *fakeRepository) GetWebhook(ctx context.Context, id uint) (*models.Webhook, error) {
+	if f.getErr != nil {
+		return nil, f.getErr
+	}
+	for _, hook := range f.hooks {
+		if hook.ID == id {
+			return &hook, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (f *fakeRepository) EnqueueDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	for _, d := range deliveries {
+		d.ID = uint(len(f.deliveries) + 1)
+		f.deliveries = append(f.deliveries, d)
+	}
+	return nil
+}
+
+func (f *fakeRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	var due []models.WebhookDelivery
+	for i, d := range f.deliveries {
+		if len(due) < limit && d.Status == models.DeliveryPending && !d.NextAttemptAt.After(time.Now()) {
+			f.deliveries[i].NextAttemptAt = time.Now().Add(lease)
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	f.deliveries[delivery.ID-1] = *delivery
+	return nil
+}
+
+func TestWebhookMatches(t *testing.T) {
+	hook := models.Webhook{FQDNPattern: "*.example.com", EventTypes: "ip_added,nxdomain"}
+
+	assert.True(t, hook.Matches(models.Event{Type: models.EventIPAdded, FQDN: "api.example.com"}))
+	assert.True(t, hook.Matches(models.Event{Type: models.EventNXDomain, FQDN: "old.example.com"}))
+	assert.False(t, hook.Matches(models.Event{Type: models.EventIPRemoved, FQDN: "api.example.com"}))
+	assert.False(t, hook.Matches(models.Event{Type: models.EventIPAdded, FQDN: "example.org"}))
+
+	all := models.Webhook{FQDNPattern: "*"}
+	assert.True(t, all.Matches(models.Event{Type: models.EventIPRemoved, FQDN: "github.com"}))
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, Backoff(1))
+	assert.Equal(t, time.Minute, Backoff(2))
+	assert.Equal(t, 4*time.Minute, Backoff(4))
+	assert.Equal(t, time.Hour, Backoff(20))
+}
+
+func TestDeliverDue(t *testing.T) {
+	var (
+		calls     int
+		gotBody   string
+		gotHeader http.Header
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		gotHeader = r.Header.Clone()
+		// Первая попытка неудачная, чтобы проверить повтор
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	repo := &fakeRepository{hooks: []models.Webhook{
+		{ID: 1, URL: server.URL, Secret: "s3cret", FQDNPattern: "*"},
+		{ID: 2, URL: server.URL, Secret: "other", FQDNPattern: "*.org"},
+	}}
+	svc := NewService(repo)
+	// httptest слушает loopback, который в проде запрещен
+	svc.allowAddr = func(addr netip.Addr) bool { return addr.IsLoopback() }
+	ctx := context.Background()
+
+	err := svc.Notify(ctx, []models.Event{{Type: models.EventIPAdded, FQDN: "example.com", IP: "1.1.1.1", OccurredAt: time.Now()}})
+	require.NoError(t, err)
+	require.Len(t, repo.deliveries, 1)
+
+	svc.DeliverDue(ctx)
+	delivery := repo.deliveries[0]
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, delivery.ResponseCode)
+	assert.True(t, delivery.NextAttemptAt.After(time.Now()))
+
+	// Подводим время следующей попытки
+	repo.deliveries[0].NextAttemptAt = time.Now()
+	svc.DeliverDue(ctx)
+	delivery = repo.deliveries[0]
+	assert.Equal(t, models.DeliveryDelivered, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.NotNil(t, delivery.DeliveredAt)
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, "ip_added", gotHeader.Get(EventHeader))
+	expected := Sign("s3cret", gotHeader.Get(TimestampHeader), []byte(gotBody))
+	assert.Equal(t, expected, gotHeader.Get(SignatureHeader))
+	assert.JSONEq(t, delivery.Payload, gotBody)
+}
+
+func TestNotify_ListsWebhooksOnce(t *testing.T) {
+	repo := &fakeRepository{hooks: []models.Webhook{
+		{ID: 1, FQDNPattern: "*", EventTypes: "ip_added"},
+		{ID: 2, FQDNPattern: "*.example.com"},
+	}}
+	svc := NewService(repo)
+
+	now := time.Now()
+	err := svc.Notify(context.Background(), []models.Event{
+		{Type: models.EventIPAdded, FQDN: "api.example.com", IP: "1.1.1.1", OccurredAt: now},
+		{Type: models.EventIPRemoved, FQDN: "api.example.com", IP: "2.2.2.2", OccurredAt: now},
+		{Type: models.EventIPAdded, FQDN: "example.org", IP: "3.3.3.3", OccurredAt: now},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.listCalls)
+	assert.Len(t, repo.deliveries, 4)
+}
+
+func TestDeliverDue_WebhookLookupFailure(t *testing.T) {
+	repo := &fakeRepository{hooks: []models.Webhook{{ID: 1, URL: "https://hooks.example.com", FQDNPattern: "*"}}}
+	svc := NewService(repo)
+	ctx := context.Background()
+	require.NoError(t, svc.Notify(ctx, []models.Event{{Type: models.EventIPAdded, FQDN: "example.com", OccurredAt: time.Now()}}))
+
+	// Сбой базы откладывает доставку, не считая попытку
+	repo.getErr = errors.New("connection reset by peer")
+	svc.DeliverDue(ctx)
+	delivery := repo.deliveries[0]
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	assert.Zero(t, delivery.Attempts)
+	assert.True(t, delivery.NextAttemptAt.After(time.Now()))
+	assert.Contains(t, delivery.LastError, "connection reset")
+
+	// Удаленный вебхук — окончательный отказ
+	repo.getErr = nil
+	repo.hooks = nil
+	repo.deliveries[0].NextAttemptAt = time.Now()
+	svc.DeliverDue(ctx)
+	assert.Equal(t, models.DeliveryFailed, repo.deliveries[0].Status)
+}
+
+func TestCheckURL(t *testing.T) {
+	for _, raw := range []string{
+		"https://hooks.example.com/dns",
+		"http://93.184.216.34:8080/hook",
+		"https://[2606:2800:220:1:248:1893:25c8:1946]/hook",
+	} {
+		assert.NoError(t, CheckURL(raw), raw)
+	}
+
+	for _, raw := range []string{
+		"ftp://hooks.example.com",
+		"file:///etc/passwd",
+		"https://",
+	} {
+		assert.Error(t, CheckURL(raw), raw)
+	}
+
+	for _, raw := range []string{
+		"http://localhost:8080",
+		"http://api.localhost",
+		"http://127.0.0.1",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.1",
+		"http://169.254.169.254/latest/meta-data",
+		"http://100.64.0.1",
+		"http://[::1]:8080",
+		"http://[fe80::1]",
+		"http://[fd00::1]",
+		"http://[::ffff:127.0.0.1]",
+		"http://0.0.0.0",
+	} {
+		assert.ErrorIs(t, CheckURL(raw), ErrForbiddenTarget, raw)
+	}
+}
+
+func TestDeliverDue_ForbiddenTarget(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer server.Close()
+
+	// Имя резолвится в loopback: запрет срабатывает при соединении
+	hookURL := strings.Replace(server.URL, "127.0.0.1", "loopback.example", 1)
+	repo := &fakeRepository{hooks: []models.Webhook{{ID: 1, URL: hookURL, FQDNPattern: "*"}}}
+	svc := NewService(repo)
+	svc.client.Transport.(*http.Transport).DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		_, port, _ := net.SplitHostPort(addr)
+		dialer := &net.Dialer{Control: svc.control}
+		return dialer.DialContext(ctx, network, net.JoinHostPort("127.0.0.1", port))
+	}
+	ctx := context.Background()
+
+	require.NoError(t, svc.Notify(ctx, []models.Event{{Type: models.EventIPAdded, FQDN: "example.com", OccurredAt: time.Now()}}))
+	svc.DeliverDue(ctx)
+
+	assert.Equal(t, 0, calls)
+	assert.Contains(t, repo.deliveries[0].LastError, ErrForbiddenTarget.Error())
+}
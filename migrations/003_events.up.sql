@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS events (
+    id BIGSERIAL PRIMARY KEY,
+    type TEXT NOT NULL,
+    fqdn TEXT NOT NULL,
+    ip TEXT,
+    error TEXT,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_fqdn ON events(fqdn);
@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS tenants (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Арендатор по умолчанию получает все существующие данные
+INSERT INTO tenants (id, name) VALUES (1, 'default') ON CONFLICT DO NOTHING;
+SELECT setval(pg_get_serial_sequence('tenants', 'id'), GREATEST((SELECT MAX(id) FROM tenants), 1));
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name TEXT NOT NULL DEFAULT '',
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant_id ON api_keys(tenant_id);
+
+ALTER TABLE dns_records ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id);
+ALTER TABLE dns_records DROP CONSTRAINT IF EXISTS dns_records_fqdn_ip_key;
+ALTER TABLE dns_records ADD CONSTRAINT dns_records_tenant_fqdn_ip_key UNIQUE (tenant_id, fqdn, ip);
+CREATE INDEX IF NOT EXISTS idx_dns_records_tenant_id ON dns_records(tenant_id);
+
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id);
+CREATE INDEX IF NOT EXISTS idx_webhooks_tenant_id ON webhooks(tenant_id);
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id);
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id);
+CREATE INDEX IF NOT EXISTS idx_events_tenant_id ON events(tenant_id);
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id);
+ALTER TABLE groups DROP CONSTRAINT IF EXISTS groups_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_groups_tenant_name ON groups(tenant_id, name);
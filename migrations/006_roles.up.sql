@@ -0,0 +1,2 @@
+-- Существующие ключи сохраняют прежние права на чтение и запись
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'editor';
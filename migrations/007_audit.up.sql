@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE CASCADE,
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    target TEXT,
+    request_id TEXT,
+    source_ip TEXT,
+    result TEXT NOT NULL,
+    status INTEGER,
+    error TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_entries_tenant_id ON audit_entries(tenant_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_actor ON audit_entries(actor);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_action ON audit_entries(action);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_target ON audit_entries(target);
@@ -0,0 +1,23 @@
+-- Приводим FQDN к каноническому виду: нижний регистр, без завершающей точки.
+-- Записи, которые после этого совпадают, сливаются в одну (остается самая ранняя).
+-- Имена в Unicode переводит в punycode команда fqdnmigrate
+DELETE FROM dns_records r
+USING dns_records d
+WHERE r.tenant_id = d.tenant_id
+  AND r.ip = d.ip
+  AND lower(rtrim(btrim(r.fqdn), '.')) = lower(rtrim(btrim(d.fqdn), '.'))
+  AND r.id > d.id;
+
+UPDATE dns_records
+SET fqdn = lower(rtrim(btrim(fqdn), '.'))
+WHERE fqdn <> lower(rtrim(btrim(fqdn), '.'));
+
+DELETE FROM group_members m
+USING group_members o
+WHERE m.group_id = o.group_id
+  AND lower(rtrim(btrim(m.fqdn), '.')) = lower(rtrim(btrim(o.fqdn), '.'))
+  AND m.ctid > o.ctid;
+
+UPDATE group_members
+SET fqdn = lower(rtrim(btrim(fqdn), '.'))
+WHERE fqdn <> lower(rtrim(btrim(fqdn), '.'));
@@ -0,0 +1,4 @@
+-- Индексы под keyset-пагинацию поиска по IP и по FQDN
+CREATE INDEX IF NOT EXISTS idx_dns_records_tenant_ip_fqdn ON dns_records(tenant_id, ip, fqdn);
+CREATE INDEX IF NOT EXISTS idx_dns_records_tenant_ip_updated ON dns_records(tenant_id, ip, updated_at, fqdn);
+CREATE INDEX IF NOT EXISTS idx_dns_records_tenant_fqdn_updated ON dns_records(tenant_id, fqdn, updated_at, ip);
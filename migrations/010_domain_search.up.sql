@@ -0,0 +1,6 @@
+-- Поиск по доменам: суффикс через индекс по развернутому имени,
+-- подстроки и регулярные выражения — через триграммы
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_dns_records_tenant_fqdn_reversed ON dns_records(tenant_id, reverse(fqdn) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_dns_records_fqdn_trgm ON dns_records USING gin (fqdn gin_trgm_ops);
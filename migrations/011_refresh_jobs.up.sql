@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS refresh_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id) ON DELETE CASCADE,
+    fqdn TEXT NOT NULL,
+    due_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    lease_owner TEXT NOT NULL DEFAULT '',
+    lease_until TIMESTAMPTZ,
+    claims INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_jobs_tenant_fqdn ON refresh_jobs(tenant_id, fqdn);
+CREATE INDEX IF NOT EXISTS idx_refresh_jobs_due_at ON refresh_jobs(due_at);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS updater_settings (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    interval_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+    paused BOOLEAN NOT NULL DEFAULT FALSE,
+    refresh_requested BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
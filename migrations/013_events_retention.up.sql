@@ -0,0 +1,2 @@
+-- Журнал событий чистится по occurred_at, см. EVENT_RETENTION
+CREATE INDEX IF NOT EXISTS idx_events_occurred_at ON events(occurred_at);
@@ -0,0 +1,18 @@
+-- Журнал аудита только пополняется: записи нельзя изменить или удалить,
+-- в том числе с правами приложения. Поэтому и арендатор с записями
+-- аудита не удаляется (внешний ключ RESTRICT, миграция 017)
+CREATE OR REPLACE FUNCTION audit_entries_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_entries is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_entries_no_modify ON audit_entries;
+CREATE TRIGGER audit_entries_no_modify
+    BEFORE UPDATE OR DELETE ON audit_entries
+    FOR EACH ROW EXECUTE FUNCTION audit_entries_append_only();
+
+DROP TRIGGER IF EXISTS audit_entries_no_truncate ON audit_entries;
+CREATE TRIGGER audit_entries_no_truncate
+    BEFORE TRUNCATE ON audit_entries
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_entries_append_only();
@@ -0,0 +1,11 @@
+-- Строки с некорректными FQDN, которые убрала команда fqdnmigrate
+CREATE TABLE IF NOT EXISTS quarantined_records (
+    id SERIAL PRIMARY KEY,
+    source TEXT NOT NULL,
+    tenant_id INTEGER NOT NULL DEFAULT 1,
+    group_id INTEGER,
+    fqdn TEXT NOT NULL,
+    ip TEXT NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
@@ -0,0 +1,10 @@
+-- Бюджеты ограничения частоты, измененные через API: общие для реплик
+-- и переживают перезапуск. Пока строки нет, действуют переменные окружения
+CREATE TABLE IF NOT EXISTS rate_limit_settings (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    read_rate DOUBLE PRECISION NOT NULL,
+    read_burst INTEGER NOT NULL,
+    write_rate DOUBLE PRECISION NOT NULL,
+    write_burst INTEGER NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+-- Каскадное удаление арендатора упиралось в триггер append-only журнала
+-- аудита. Теперь арендатор с записями аудита не удаляется по внешнему ключу
+ALTER TABLE audit_entries DROP CONSTRAINT IF EXISTS audit_entries_tenant_id_fkey;
+ALTER TABLE audit_entries ADD CONSTRAINT audit_entries_tenant_id_fkey
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE RESTRICT;